@@ -0,0 +1,302 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/draw"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	tagMake               = 0x010f
+	tagModel              = 0x0110
+	tagOrientation        = 0x0112
+	tagDateTime           = 0x0132
+	tagExifIFD            = 0x8769
+	tagGPSIFD             = 0x8825
+	tagDateTimeOriginal   = 0x9003
+	tagOffsetTimeOriginal = 0x9011
+	tagLensMake           = 0xa433
+	tagLensModel          = 0xa434
+	tagGPSLatitudeRef     = 0x0001
+	tagGPSLatitude        = 0x0002
+	tagGPSLongitudeRef    = 0x0003
+	tagGPSLongitude       = 0x0004
+
+	exifDateFormat = "2006:01:02 15:04:05"
+)
+
+// ErrBadEXIF is returned when the EXIF segment of a JPEG cannot be read
+var ErrBadEXIF = errors.New("imaging: malformed exif")
+
+// EXIF is the metadata a camera records in a JPEG that is worth keeping, the serial numbers and the rest are left out on purpose
+type EXIF struct {
+	Make  string
+	Model string
+	Lens  string
+	// TakenAt is the time the picture was taken, in UTC unless the camera recorded its offset
+	TakenAt *time.Time
+	// Orientation is how the image must be turned to be upright, from 1 for upright to 8
+	Orientation int
+	Latitude    *float64
+	Longitude   *float64
+}
+
+// ReadEXIF reads the EXIF segment of a JPEG, a JPEG without one has an empty upright EXIF
+func ReadEXIF(data []byte) (*EXIF, error) {
+	exif := &EXIF{Orientation: 1}
+
+	segments, err := readSegments(data, true)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range segments {
+		if s.marker == markerAPP1 && bytes.HasPrefix(s.payload, []byte("Exif\x00\x00")) {
+			return exif, exif.read(s.payload[6:])
+		}
+	}
+
+	return exif, nil
+}
+
+func (exif *EXIF) read(data []byte) error {
+	if len(data) < 8 {
+		return ErrBadEXIF
+	}
+
+	var order binary.ByteOrder
+	switch string(data[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return ErrBadEXIF
+	}
+
+	t := tiff{data: data, order: order}
+	if order.Uint16(data[2:]) != 42 {
+		return ErrBadEXIF
+	}
+
+	main, err := t.ifd(order.Uint32(data[4:]))
+	if err != nil {
+		return err
+	}
+
+	exif.Make = main.text(tagMake)
+	exif.Model = main.text(tagModel)
+	if orientation := main.short(tagOrientation); orientation >= 1 && orientation <= 8 {
+		exif.Orientation = orientation
+	}
+	takenAt := main.text(tagDateTime)
+
+	if offset, ok := main.long(tagExifIFD); ok {
+		sub, err := t.ifd(offset)
+		if err != nil {
+			return err
+		}
+
+		if original := sub.text(tagDateTimeOriginal); original != "" {
+			takenAt = original
+		}
+		exif.TakenAt = parseEXIFTime(takenAt, sub.text(tagOffsetTimeOriginal))
+		exif.Lens = sub.text(tagLensModel)
+		if lensMake := sub.text(tagLensMake); !strings.HasPrefix(exif.Lens, lensMake) {
+			exif.Lens = strings.TrimSpace(lensMake + " " + exif.Lens)
+		}
+	} else {
+		exif.TakenAt = parseEXIFTime(takenAt, "")
+	}
+
+	if offset, ok := main.long(tagGPSIFD); ok {
+		gps, err := t.ifd(offset)
+		if err != nil {
+			return err
+		}
+
+		latitude, latOK := gps.degrees(tagGPSLatitude, gps.text(tagGPSLatitudeRef) == "S", 90)
+		longitude, lonOK := gps.degrees(tagGPSLongitude, gps.text(tagGPSLongitudeRef) == "W", 180)
+		if latOK && lonOK {
+			exif.Latitude, exif.Longitude = &latitude, &longitude
+		}
+	}
+
+	return nil
+}
+
+func parseEXIFTime(value string, offset string) *time.Time {
+	location := time.UTC
+	if zone, err := time.Parse("-07:00", offset); err == nil {
+		location = zone.Location()
+	}
+
+	taken, err := time.ParseInLocation(exifDateFormat, value, location)
+	if err != nil {
+		return nil
+	}
+
+	return &taken
+}
+
+// tiff is the TIFF structure the EXIF is stored as, its offsets start at the byte order mark
+type tiff struct {
+	data  []byte
+	order binary.ByteOrder
+}
+
+type ifd struct {
+	tiff    tiff
+	entries map[uint16]entry
+}
+
+type entry struct {
+	kind  uint16
+	count uint32
+	value []byte
+}
+
+var typeSizes = map[uint16]uint64{1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 7: 1, 9: 4, 10: 8}
+
+func (t tiff) ifd(offset uint32) (*ifd, error) {
+	start := uint64(offset)
+	if start+2 > uint64(len(t.data)) {
+		return nil, ErrBadEXIF
+	}
+
+	count := uint64(t.order.Uint16(t.data[start:]))
+	if start+2+count*12 > uint64(len(t.data)) {
+		return nil, ErrBadEXIF
+	}
+
+	entries := map[uint16]entry{}
+	for i := uint64(0); i < count; i++ {
+		raw := t.data[start+2+i*12:]
+		e := entry{kind: t.order.Uint16(raw[2:]), count: t.order.Uint32(raw[4:])}
+
+		size, known := typeSizes[e.kind]
+		if !known {
+			continue
+		}
+
+		// a value of four bytes or less is stored in place of its offset
+		length := size * uint64(e.count)
+		if length <= 4 {
+			e.value = raw[8 : 8+length]
+		} else {
+			valueOffset := uint64(t.order.Uint32(raw[8:]))
+			if valueOffset+length > uint64(len(t.data)) {
+				continue
+			}
+			e.value = t.data[valueOffset : valueOffset+length]
+		}
+
+		entries[t.order.Uint16(raw)] = e
+	}
+
+	return &ifd{tiff: t, entries: entries}, nil
+}
+
+func (d *ifd) text(tag uint16) string {
+	e, ok := d.entries[tag]
+	if !ok || e.kind != 2 {
+		return ""
+	}
+
+	return strings.TrimSpace(strings.TrimRight(string(e.value), "\x00"))
+}
+
+func (d *ifd) short(tag uint16) int {
+	e, ok := d.entries[tag]
+	if !ok || e.kind != 3 || len(e.value) < 2 {
+		return 0
+	}
+
+	return int(d.tiff.order.Uint16(e.value))
+}
+
+func (d *ifd) long(tag uint16) (uint32, bool) {
+	e, ok := d.entries[tag]
+	if !ok || e.kind != 4 || len(e.value) < 4 {
+		return 0, false
+	}
+
+	return d.tiff.order.Uint32(e.value), true
+}
+
+// degrees reads the degrees, minutes and seconds rationals of a coordinate
+func (d *ifd) degrees(tag uint16, negative bool, limit float64) (float64, bool) {
+	e, ok := d.entries[tag]
+	if !ok || e.kind != 5 || e.count != 3 {
+		return 0, false
+	}
+
+	var value float64
+	for i, unit := range []float64{1, 60, 3600} {
+		numerator := d.tiff.order.Uint32(e.value[i*8:])
+		denominator := d.tiff.order.Uint32(e.value[i*8+4:])
+		if denominator == 0 {
+			return 0, false
+		}
+		value += float64(numerator) / float64(denominator) / unit
+	}
+
+	if value > limit {
+		return 0, false
+	}
+	if negative {
+		value = -value
+	}
+
+	return math.Round(value*1e6) / 1e6, true
+}
+
+// Orient turns the image upright following its EXIF orientation, mirrored orientations included
+func Orient(src image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	in := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(in, in.Bounds(), src, bounds.Min, draw.Src)
+
+	dstWidth, dstHeight := width, height
+	if orientation >= 5 {
+		dstWidth, dstHeight = height, width
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for y := 0; y < dstHeight; y++ {
+		for x := 0; x < dstWidth; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2:
+				sx, sy = width-1-x, y
+			case 3:
+				sx, sy = width-1-x, height-1-y
+			case 4:
+				sx, sy = x, height-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, height-1-x
+			case 7:
+				sx, sy = width-1-y, height-1-x
+			case 8:
+				sx, sy = width-1-y, x
+			}
+
+			copy(dst.Pix[dst.PixOffset(x, y):dst.PixOffset(x, y)+4], in.Pix[in.PixOffset(sx, sy):in.PixOffset(sx, sy)+4])
+		}
+	}
+
+	return dst
+}
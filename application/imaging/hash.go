@@ -0,0 +1,95 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"math/bits"
+	"sort"
+)
+
+// DHash computes the difference hash of an image, each bit tells whether a pixel of a 9 by 8 grayscale copy is brighter than
+// its right neighbour so the hash survives resizing, recompression and small edits, near identical images differ by a few bits
+func DHash(src image.Image) uint64 {
+	small := Flatten(Resize(src, 9, 8), color.White)
+
+	var hash uint64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			if luminance(small, x, y) > luminance(small, x+1, y) {
+				hash |= 1 << uint(y*8+x)
+			}
+		}
+	}
+
+	return hash
+}
+
+// Distance returns how many bits two hashes differ by
+func Distance(a uint64, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// Clusters groups the hashes within the distance of one another, a hash close to any hash of a group joins it.
+// Two hashes differing by at most d bits have at least one of d+1 slices of their bits in common,
+// so only the hashes sharing a slice are compared. The groups of two hashes or more are returned as indexes
+func Clusters(hashes []uint64, maxDistance int) [][]int {
+	if maxDistance < 0 {
+		return nil
+	}
+
+	parents := make([]int, len(hashes))
+	for i := range parents {
+		parents[i] = i
+	}
+
+	var root func(i int) int
+	root = func(i int) int {
+		if parents[i] != i {
+			parents[i] = root(parents[i])
+		}
+		return parents[i]
+	}
+
+	slices := minInt(maxDistance+1, 64)
+	for s := 0; s < slices; s++ {
+		low, high := s*64/slices, (s+1)*64/slices
+		mask := uint64(1)<<uint(high-low) - 1
+
+		buckets := map[uint64][]int{}
+		for i, hash := range hashes {
+			key := hash >> uint(low) & mask
+			buckets[key] = append(buckets[key], i)
+		}
+
+		for _, bucket := range buckets {
+			for i := 0; i < len(bucket); i++ {
+				for j := i + 1; j < len(bucket); j++ {
+					a, b := bucket[i], bucket[j]
+					if root(a) != root(b) && Distance(hashes[a], hashes[b]) <= maxDistance {
+						parents[root(a)] = root(b)
+					}
+				}
+			}
+		}
+	}
+
+	groups := map[int][]int{}
+	for i := range hashes {
+		groups[root(i)] = append(groups[root(i)], i)
+	}
+
+	var clusters [][]int
+	for _, group := range groups {
+		if len(group) > 1 {
+			clusters = append(clusters, group)
+		}
+	}
+
+	sort.Slice(clusters, func(i, j int) bool { return clusters[i][0] < clusters[j][0] })
+	return clusters
+}
+
+func luminance(img *image.RGBA, x int, y int) float64 {
+	pixel := img.RGBAAt(x, y)
+	return 0.299*float64(pixel.R) + 0.587*float64(pixel.G) + 0.114*float64(pixel.B)
+}
@@ -0,0 +1,191 @@
+// Package imaging resizes the uploaded images in pure Go, downscaling with a triangle
+// filter widened to the scale so every source pixel weighs in the result
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// Fit returns the size of an image scaled down to fit in the box keeping its aspect ratio, an image already fitting keeps its size
+func Fit(width int, height int, maxWidth int, maxHeight int) (int, int) {
+	if width <= maxWidth && height <= maxHeight {
+		return width, height
+	}
+
+	scale := math.Min(float64(maxWidth)/float64(width), float64(maxHeight)/float64(height))
+	return maxInt(1, int(math.Round(float64(width)*scale))), maxInt(1, int(math.Round(float64(height)*scale)))
+}
+
+// Thumbnail fits the image in the box, or fills the box cropping the center of the image to its aspect ratio when crop is set
+func Thumbnail(src image.Image, maxWidth int, maxHeight int, crop bool) *image.NRGBA {
+	bounds := src.Bounds()
+	if !crop {
+		width, height := Fit(bounds.Dx(), bounds.Dy(), maxWidth, maxHeight)
+		return Resize(src, width, height)
+	}
+
+	// the largest center area of the aspect ratio of the box
+	cropWidth, cropHeight := bounds.Dx(), bounds.Dy()
+	if cropWidth*maxHeight > cropHeight*maxWidth {
+		cropWidth = maxInt(1, cropHeight*maxWidth/maxHeight)
+	} else {
+		cropHeight = maxInt(1, cropWidth*maxHeight/maxWidth)
+	}
+
+	x0 := bounds.Min.X + (bounds.Dx()-cropWidth)/2
+	y0 := bounds.Min.Y + (bounds.Dy()-cropHeight)/2
+	area := image.Rect(x0, y0, x0+cropWidth, y0+cropHeight)
+
+	width, height := Fit(cropWidth, cropHeight, maxWidth, maxHeight)
+	return Resize(subImage(src, area), width, height)
+}
+
+// Resize resamples the image to the given size, horizontally first then vertically,
+// the source is read one row at a time so only the horizontally resampled image is held in memory
+func Resize(src image.Image, width int, height int) *image.NRGBA {
+	bounds := src.Bounds()
+
+	columns := weights(bounds.Dx(), width)
+	row := make([]float32, bounds.Dx()*4)
+	horizontal := make([]float32, width*bounds.Dy()*4)
+	for y := 0; y < bounds.Dy(); y++ {
+		readRow(src, bounds.Min.Y+y, row)
+
+		for x, column := range columns {
+			out := horizontal[(y*width+x)*4:]
+			for i, weight := range column.weights {
+				in := row[(column.start+i)*4:]
+				out[0] += in[0] * weight
+				out[1] += in[1] * weight
+				out[2] += in[2] * weight
+				out[3] += in[3] * weight
+			}
+		}
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	rows := weights(bounds.Dy(), height)
+	for y, row := range rows {
+		for x := 0; x < width; x++ {
+			var r, g, b, a float32
+			for i, weight := range row.weights {
+				in := horizontal[((row.start+i)*width+x)*4:]
+				r += in[0] * weight
+				g += in[1] * weight
+				b += in[2] * weight
+				a += in[3] * weight
+			}
+
+			if a <= 0 {
+				continue
+			}
+
+			offset := dst.PixOffset(x, y)
+			dst.Pix[offset] = clamp(r / a * 255)
+			dst.Pix[offset+1] = clamp(g / a * 255)
+			dst.Pix[offset+2] = clamp(b / a * 255)
+			dst.Pix[offset+3] = clamp(a * 255)
+		}
+	}
+
+	return dst
+}
+
+// Flatten draws the image over a background, formats without transparency like JPEG need it
+func Flatten(src image.Image, background color.Color) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, src.Bounds().Dx(), src.Bounds().Dy()))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), src, src.Bounds().Min, draw.Over)
+	return dst
+}
+
+// contribution is the span of source pixels making one destination pixel, with their normalized weights
+type contribution struct {
+	start   int
+	weights []float32
+}
+
+// weights computes the triangle filter contributions of every destination pixel along one axis
+func weights(srcSize int, dstSize int) []contribution {
+	scale := float64(srcSize) / float64(dstSize)
+	support := math.Max(scale, 1)
+
+	contributions := make([]contribution, dstSize)
+	for i := range contributions {
+		center := (float64(i)+0.5)*scale - 0.5
+		start := maxInt(0, int(math.Ceil(center-support)))
+		end := minInt(srcSize-1, int(math.Floor(center+support)))
+
+		var total float64
+		spans := make([]float64, 0, end-start+1)
+		for j := start; j <= end; j++ {
+			weight := 1 - math.Abs(float64(j)-center)/support
+			if weight < 0 {
+				weight = 0
+			}
+			spans = append(spans, weight)
+			total += weight
+		}
+
+		if total == 0 {
+			// a destination pixel falling between source pixels takes the nearest
+			nearest := minInt(srcSize-1, maxInt(0, int(math.Round(center))))
+			contributions[i] = contribution{start: nearest, weights: []float32{1}}
+			continue
+		}
+
+		normalized := make([]float32, len(spans))
+		for j, span := range spans {
+			normalized[j] = float32(span / total)
+		}
+		contributions[i] = contribution{start: start, weights: normalized}
+	}
+
+	return contributions
+}
+
+// readRow reads a row of the image as premultiplied channels between 0 and 1, so transparent pixels do not bleed their color
+func readRow(src image.Image, y int, row []float32) {
+	bounds := src.Bounds()
+	for x := bounds.Min.X; x < bounds.Max.X; x++ {
+		r, g, b, a := src.At(x, y).RGBA()
+		i := (x - bounds.Min.X) * 4
+		row[i] = float32(r) / 0xffff
+		row[i+1] = float32(g) / 0xffff
+		row[i+2] = float32(b) / 0xffff
+		row[i+3] = float32(a) / 0xffff
+	}
+}
+
+func subImage(src image.Image, area image.Rectangle) image.Image {
+	if cropper, ok := src.(interface {
+		SubImage(image.Rectangle) image.Image
+	}); ok {
+		return cropper.SubImage(area)
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, area.Dx(), area.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, area.Min, draw.Src)
+	return dst
+}
+
+func clamp(value float32) uint8 {
+	return uint8(math.Min(255, math.Max(0, math.Round(float64(value)))))
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,136 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	markerSOI   = 0xd8
+	markerEOI   = 0xd9
+	markerSOS   = 0xda
+	markerAPP0  = 0xe0
+	markerAPP1  = 0xe1
+	markerAPP2  = 0xe2
+	markerAPP14 = 0xee
+	markerAPP15 = 0xef
+	markerCOM   = 0xfe
+)
+
+// ErrNotJPEG is returned when the data does not hold a well formed JPEG
+var ErrNotJPEG = errors.New("imaging: not a well formed jpeg")
+
+// segment is a marker segment of a JPEG, data spans the marker and its length, entropy the coded data following a start of scan
+type segment struct {
+	marker  byte
+	data    []byte
+	payload []byte
+	entropy []byte
+}
+
+// StripJPEG removes every metadata segment of a JPEG without decoding it, the EXIF and XMP blocks, the Photoshop and IPTC records
+// and the comments are dropped along with anything after the end of the image, only the JFIF header, the color profile and the
+// Adobe color transform are kept as the image would not look the same without them
+func StripJPEG(data []byte) ([]byte, error) {
+	segments, err := readSegments(data, false)
+	if err != nil {
+		return nil, err
+	}
+
+	stripped := make([]byte, 0, len(data))
+	stripped = append(stripped, 0xff, markerSOI)
+	for _, s := range segments {
+		if !keptSegment(s) {
+			continue
+		}
+
+		stripped = append(stripped, s.data...)
+		stripped = append(stripped, s.entropy...)
+	}
+
+	return append(stripped, 0xff, markerEOI), nil
+}
+
+func keptSegment(s segment) bool {
+	switch {
+	case s.marker == markerAPP0:
+		return bytes.HasPrefix(s.payload, []byte("JFIF\x00"))
+	case s.marker == markerAPP2:
+		return bytes.HasPrefix(s.payload, []byte("ICC_PROFILE\x00"))
+	case s.marker == markerAPP14:
+		return bytes.HasPrefix(s.payload, []byte("Adobe"))
+	case s.marker >= markerAPP0 && s.marker <= markerAPP15, s.marker == markerCOM:
+		return false
+	}
+
+	return true
+}
+
+// readSegments walks the segments of a JPEG up to its end, or up to the first scan when only the headers are needed
+func readSegments(data []byte, headersOnly bool) ([]segment, error) {
+	if len(data) < 4 || data[0] != 0xff || data[1] != markerSOI {
+		return nil, ErrNotJPEG
+	}
+
+	var segments []segment
+	pos := 2
+	for pos < len(data) {
+		if data[pos] != 0xff {
+			return nil, ErrNotJPEG
+		}
+
+		// a marker may be preceded by any number of fill bytes
+		for pos < len(data) && data[pos] == 0xff {
+			pos++
+		}
+		if pos == len(data) {
+			return nil, ErrNotJPEG
+		}
+
+		marker := data[pos]
+		pos++
+
+		switch {
+		case marker == markerEOI:
+			return segments, nil
+		case marker == 0x01 || marker >= 0xd0 && marker <= 0xd7:
+			segments = append(segments, segment{marker: marker, data: []byte{0xff, marker}})
+			continue
+		}
+
+		if pos+2 > len(data) {
+			return nil, ErrNotJPEG
+		}
+		length := int(binary.BigEndian.Uint16(data[pos:]))
+		if length < 2 || pos+length > len(data) {
+			return nil, ErrNotJPEG
+		}
+
+		s := segment{marker: marker, data: append([]byte{0xff, marker}, data[pos:pos+length]...), payload: data[pos+2 : pos+length]}
+		pos += length
+
+		if marker == markerSOS {
+			if headersOnly {
+				return append(segments, s), nil
+			}
+
+			// the coded data runs up to the next marker, a 0xff in it is followed by a stuffed zero or a restart marker
+			end := pos
+			for end+1 < len(data) && !(data[end] == 0xff && data[end+1] != 0 && (data[end+1] < 0xd0 || data[end+1] > 0xd7)) {
+				end++
+			}
+			if end+1 >= len(data) {
+				// a missing end of image is tolerated by the decoders, the rest of the data is the scan
+				end = len(data)
+			}
+
+			s.entropy = data[pos:end]
+			pos = end
+		}
+
+		segments = append(segments, s)
+	}
+
+	return segments, nil
+}
@@ -0,0 +1,102 @@
+package jwt
+
+import (
+	"fmt"
+
+	"hexagonal-fiber/application/security/keyring"
+	secureDomain "hexagonal-fiber/domain/security"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/gofiber/fiber/v2"
+	"github.com/spf13/viper"
+)
+
+// getAlgorithm returns the configured signing algorithm of the token type, RS256 when it is not set
+func getAlgorithm(tokenType string) (string, error) {
+	viper.SetConfigFile("config.json")
+	if err := viper.ReadInConfig(); err != nil {
+		return "", err
+	}
+
+	algorithm := viper.GetString(TokenTypeAlgorithm[tokenType])
+	if algorithm == "" {
+		algorithm = secureDomain.AlgorithmRS256
+	}
+
+	switch algorithm {
+	case secureDomain.AlgorithmHS256, secureDomain.AlgorithmRS256, secureDomain.AlgorithmES256, secureDomain.AlgorithmEdDSA:
+		return algorithm, nil
+	}
+
+	return "", fmt.Errorf("unsupported signing algorithm %s for %s tokens", algorithm, tokenType)
+}
+
+// getSecret returns the shared HS256 secret of the token type
+func getSecret(tokenType string) ([]byte, error) {
+	viper.SetConfigFile("config.json")
+	if err := viper.ReadInConfig(); err != nil {
+		return nil, err
+	}
+
+	keyName, ok := TokenTypeKeyName[tokenType]
+	if !ok || viper.GetString(keyName) == "" {
+		return nil, fmt.Errorf("no secret configured for %s tokens", tokenType)
+	}
+
+	return []byte(viper.GetString(keyName)), nil
+}
+
+// signingKey returns the key signing the token type and its kid, HS256 tokens have none
+func signingKey(tokenType string, algorithm string) (key interface{}, kid string, err error) {
+	if algorithm == secureDomain.AlgorithmHS256 {
+		key, err = getSecret(tokenType)
+		return
+	}
+
+	ringKey, err := keyring.Keys.Signing(algorithm)
+	if err != nil {
+		return nil, "", fmt.Errorf("no active %s key in the key ring: %w", algorithm, err)
+	}
+
+	return ringKey.PrivateKey, ringKey.ID, nil
+}
+
+// verifyingKey returns the key verifying the token, only the configured algorithm of its type is accepted
+func verifyingKey(token *jwt.Token, tokenType string, algorithm string) (interface{}, error) {
+	if token.Method.Alg() != algorithm {
+		message := fmt.Sprintf("unexpected signing method: %v", token.Header["alg"])
+		return nil, fiber.NewError(fiber.StatusUnauthorized, message)
+	}
+
+	if algorithm == secureDomain.AlgorithmHS256 {
+		return getSecret(tokenType)
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	ringKey, err := keyring.Keys.Verifying(kid)
+	if err != nil {
+		return nil, err
+	}
+
+	if ringKey.Algorithm != algorithm {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "unexpected signing key")
+	}
+
+	return ringKey.PublicKey, nil
+}
+
+// ValidateAlgorithms checks that every token type can be signed with its configured algorithm
+func ValidateAlgorithms() error {
+	for tokenType := range TokenTypeExpTime {
+		algorithm, err := getAlgorithm(tokenType)
+		if err != nil {
+			return err
+		}
+
+		if _, _, err = signingKey(tokenType, algorithm); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -2,37 +2,52 @@
 package jwt
 
 import (
-	"fmt"
 	secureDomain "hexagonal-fiber/domain/security"
 	"time"
 
 	jwt "github.com/dgrijalva/jwt-go"
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 )
 
-// GenerateJWTToken generates a JWT token (refresh or access)
-func GenerateJWTToken(userID string, tokenType string, roleName string) (appToken *secureDomain.AppToken, err error) {
+// GenerateJWTToken generates a JWT token (refresh or access) for the given claims,
+// signed with the algorithm configured for its type
+func GenerateJWTToken(tokenType string, tokenClaims *secureDomain.Claims) (appToken *secureDomain.AppToken, err error) {
 	tokenTimeUnix, err := getTimeExpire(tokenType)
 	if err != nil {
 		return
 	}
 
+	return GenerateJWTTokenFor(tokenType, tokenClaims, tokenTimeUnix)
+}
+
+// GenerateJWTTokenFor generates a JWT token of the given type living for the given duration instead of the configured one
+func GenerateJWTTokenFor(tokenType string, tokenClaims *secureDomain.Claims, tokenTimeUnix time.Duration) (appToken *secureDomain.AppToken, err error) {
 	nowTime := time.Now()
 	expirationTokenTime := nowTime.Add(tokenTimeUnix)
 
-	tokenClaims := &secureDomain.Claims{
-		UserID: userID,
-		Type:   tokenType,
-		Role:   roleName,
-		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: expirationTokenTime.Unix(),
-			IssuedAt:  nowTime.UTC().Unix(),
-		},
+	tokenClaims.Type = tokenType
+	tokenClaims.Id = uuid.New().String()
+	tokenClaims.ExpiresAt = expirationTokenTime.Unix()
+	tokenClaims.IssuedAt = nowTime.UTC().Unix()
+
+	algorithm, err := getAlgorithm(tokenType)
+	if err != nil {
+		return
+	}
+
+	key, kid, err := signingKey(tokenType, algorithm)
+	if err != nil {
+		return
+	}
+
+	tokenWithClaims := jwt.NewWithClaims(jwt.GetSigningMethod(algorithm), tokenClaims)
+	if kid != "" {
+		tokenWithClaims.Header["kid"] = kid
 	}
-	tokenWithClaims := jwt.NewWithClaims(jwt.GetSigningMethod("RS256"), tokenClaims)
 
-	// Sign and get the complete encoded token as a string using the secret
-	tokenStr, err := tokenWithClaims.SignedString(secureDomain.PrivateKey)
+	// Sign and get the complete encoded token as a string using the key of the token type
+	tokenStr, err := tokenWithClaims.SignedString(key)
 	if err != nil {
 		return
 	}
@@ -40,37 +55,38 @@ func GenerateJWTToken(userID string, tokenType string, roleName string) (appToke
 	appToken = &secureDomain.AppToken{
 		Token:          tokenStr,
 		TokenType:      tokenType,
+		TokenID:        tokenClaims.Id,
 		ExpirationTime: expirationTokenTime,
 	}
 
 	return
 }
 
-// GetClaimsAndVerifyToken verifies the token and returns the claims
-func GetClaimsAndVerifyToken(tokenString string, tokenType string) (claims jwt.MapClaims, err error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
-			message := fmt.Sprintf("unexpected signing method: %v", token.Header["alg"])
-			return nil, fiber.NewError(fiber.StatusUnauthorized, message)
-		}
+// GetClaimsAndVerifyToken verifies the token and returns the claims,
+// a token signed with another algorithm than the one configured for its type is rejected
+func GetClaimsAndVerifyToken(tokenString string, tokenType string) (claims *secureDomain.Claims, err error) {
+	algorithm, err := getAlgorithm(tokenType)
+	if err != nil {
+		return nil, err
+	}
 
-		return secureDomain.PublicKey, nil
+	claims = &secureDomain.Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return verifyingKey(token, tokenType, algorithm)
 	})
+	if err != nil || !token.Valid {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "invalid token")
+	}
 
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		if claims["type"] != tokenType {
-			return nil, fiber.NewError(fiber.StatusUnauthorized, "invalid token type")
-		}
-
-		var timeExpire = claims["exp"].(float64)
-		if time.Now().Unix() > int64(timeExpire) {
-			return nil, fiber.NewError(fiber.StatusUnauthorized, "token expired")
-		}
+	if claims.Type != tokenType {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "invalid token type")
+	}
 
-		return claims, nil
+	if time.Now().Unix() > claims.ExpiresAt {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "token expired")
 	}
 
-	return nil, err
+	return claims, nil
 }
 
 // ReGenerateCustomJWT regenerate jwt with custom modified data
@@ -23,9 +23,9 @@ func getTimeExpire(tokenType string) (tokenTimeUnix time.Duration, err error) {
 
 	tokenTimeUnix = time.Duration(tokenTimeConverted)
 	switch tokenType {
-	case Refresh:
+	case Refresh, Verify:
 		tokenTimeUnix *= time.Hour
-	case Access:
+	case Access, MFA:
 		tokenTimeUnix *= time.Minute
 	default:
 		err = errors.New("invalid token type")
@@ -3,6 +3,8 @@ package jwt
 const (
 	Access  = "access"
 	Refresh = "refresh"
+	Verify  = "verify"
+	MFA     = "mfa"
 )
 
 // TokenTypeKeyName is a map that contains the key name of the JWT in config.json
@@ -15,4 +17,14 @@ var TokenTypeKeyName = map[string]string{
 var TokenTypeExpTime = map[string]string{
 	Access:  "Secure.JWTAccessTimeMinute",
 	Refresh: "Secure.JWTRefreshTimeHour",
+	Verify:  "Secure.JWTVerifyTimeHour",
+	MFA:     "Secure.JWTMFATimeMinute",
+}
+
+// TokenTypeAlgorithm is a map that contains the key name of the signing algorithm of the JWT in config.json
+var TokenTypeAlgorithm = map[string]string{
+	Access:  "Secure.JWTAccessAlgorithm",
+	Refresh: "Secure.JWTRefreshAlgorithm",
+	Verify:  "Secure.JWTVerifyAlgorithm",
+	MFA:     "Secure.JWTMFAAlgorithm",
 }
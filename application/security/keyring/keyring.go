@@ -0,0 +1,246 @@
+// Package keyring loads the signing keys of the tokens and manages their rotation
+package keyring
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	secureDomain "hexagonal-fiber/domain/security"
+)
+
+const (
+	// legacyKeyID is the kid given to the historical single key pair when there is no manifest
+	legacyKeyID = "app"
+
+	// keyRingReloadInterval is how often the manifest is checked for a rotation made by the toolbox
+	keyRingReloadInterval = 30 * time.Second
+
+	// keyRingMissInterval limits the reloads caused by tokens with an unknown kid
+	keyRingMissInterval = time.Second
+)
+
+// KeyRing contains every key able to verify tokens, one key per algorithm signs the new tokens
+type KeyRing struct {
+	mu        sync.RWMutex
+	path      string
+	modTime   time.Time
+	checkedAt time.Time
+	active    map[string]*secureDomain.Key
+	keys      map[string]*secureDomain.Key
+}
+
+// Keys is the key ring of the application, loaded with Load
+var Keys = &KeyRing{}
+
+// Load loads the key ring of the manifest at path into Keys
+func Load(path string) error {
+	Keys.mu.Lock()
+	defer Keys.mu.Unlock()
+
+	Keys.path = path
+	return Keys.load()
+}
+
+// Signing returns the active key of the ring for the algorithm
+func (r *KeyRing) Signing(algorithm string) (*secureDomain.Key, error) {
+	r.refresh(keyRingReloadInterval)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key, ok := r.active[algorithm]
+	if !ok {
+		return nil, secureDomain.ErrUnknownKey
+	}
+
+	return key, nil
+}
+
+// Verifying returns the key of the given kid, a kid unknown to the ring triggers a reload
+// so the tokens signed by a key rotated on another instance verify without a restart
+func (r *KeyRing) Verifying(kid string) (*secureDomain.Key, error) {
+	r.refresh(keyRingReloadInterval)
+
+	if key := r.get(kid); key != nil {
+		return key, nil
+	}
+
+	r.refresh(keyRingMissInterval)
+
+	if key := r.get(kid); key != nil {
+		return key, nil
+	}
+
+	return nil, secureDomain.ErrUnknownKey
+}
+
+// JWKS returns the public keys of the ring, the next and retiring keys included
+func (r *KeyRing) JWKS() *secureDomain.JWKS {
+	r.refresh(keyRingReloadInterval)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	jwks := &secureDomain.JWKS{Keys: []secureDomain.JWK{}}
+	for _, key := range r.keys {
+		jwk := secureDomain.JWK{KeyID: key.ID, Use: "sig", Algorithm: key.Algorithm}
+
+		switch publicKey := key.PublicKey.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+		case *ecdsa.PublicKey:
+			size := (publicKey.Curve.Params().BitSize + 7) / 8
+			jwk.KeyType = "EC"
+			jwk.Curve = publicKey.Curve.Params().Name
+			jwk.X = base64.RawURLEncoding.EncodeToString(publicKey.X.FillBytes(make([]byte, size)))
+			jwk.Y = base64.RawURLEncoding.EncodeToString(publicKey.Y.FillBytes(make([]byte, size)))
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(publicKey)
+		}
+
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+
+	sort.Slice(jwks.Keys, func(i, j int) bool { return jwks.Keys[i].KeyID < jwks.Keys[j].KeyID })
+	return jwks
+}
+
+func (r *KeyRing) get(kid string) *secureDomain.Key {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.keys[kid]
+}
+
+// refresh reloads the manifest when it changed, at most once per interval
+func (r *KeyRing) refresh(interval time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.path == "" || time.Since(r.checkedAt) < interval {
+		return
+	}
+	r.checkedAt = time.Now()
+
+	info, err := os.Stat(r.path)
+	if err != nil || info.ModTime().Equal(r.modTime) {
+		return
+	}
+
+	// a broken manifest keeps the keys already loaded
+	_ = r.load()
+}
+
+// load reads the manifest, falling back to the legacy key pair next to it when there is none
+func (r *KeyRing) load() error {
+	r.checkedAt = time.Now()
+
+	manifest, err := ReadManifest(r.path)
+	if errors.Is(err, os.ErrNotExist) {
+		manifest = &secureDomain.KeyManifest{Keys: []secureDomain.KeyManifestEntry{{
+			ID:         legacyKeyID,
+			Status:     secureDomain.KeyActive,
+			PrivateKey: "app.rsa",
+			PublicKey:  "app.rsa.pub",
+		}}}
+	} else if err != nil {
+		return err
+	} else if info, err := os.Stat(r.path); err == nil {
+		r.modTime = info.ModTime()
+	}
+
+	dir := filepath.Dir(r.path)
+	keys := map[string]*secureDomain.Key{}
+	active := map[string]*secureDomain.Key{}
+
+	for _, entry := range manifest.Keys {
+		key := &secureDomain.Key{ID: entry.ID, Algorithm: entry.Algorithm, Status: entry.Status}
+
+		// manifests written before the algorithms were configurable only hold RSA keys
+		if key.Algorithm == "" {
+			key.Algorithm = secureDomain.AlgorithmRS256
+		}
+
+		publicBytes, err := os.ReadFile(filepath.Join(dir, entry.PublicKey))
+		if err != nil {
+			return err
+		}
+
+		key.PublicKey, err = parsePublicKey(publicBytes)
+		if err != nil {
+			return fmt.Errorf("key %s: %w", entry.ID, err)
+		}
+
+		if entry.Status == secureDomain.KeyActive {
+			privateBytes, err := os.ReadFile(filepath.Join(dir, entry.PrivateKey))
+			if err != nil {
+				return err
+			}
+
+			key.PrivateKey, err = parsePrivateKey(privateBytes)
+			if err != nil {
+				return fmt.Errorf("key %s: %w", entry.ID, err)
+			}
+
+			if _, ok := active[key.Algorithm]; ok {
+				return fmt.Errorf("key ring has more than one active %s key", key.Algorithm)
+			}
+			active[key.Algorithm] = key
+		}
+
+		keys[entry.ID] = key
+	}
+
+	r.keys = keys
+	r.active = active
+	return nil
+}
+
+// parsePrivateKey parses a PEM encoded PKCS8, PKCS1 or SEC1 private key
+func parsePrivateKey(pemBytes []byte) (crypto.PrivateKey, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, errors.New("key must be PEM encoded")
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	return x509.ParseECPrivateKey(block.Bytes)
+}
+
+// parsePublicKey parses a PEM encoded PKIX or PKCS1 public key
+func parsePublicKey(pemBytes []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, errors.New("key must be PEM encoded")
+	}
+
+	if key, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	return x509.ParsePKCS1PublicKey(block.Bytes)
+}
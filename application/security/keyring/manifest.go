@@ -0,0 +1,206 @@
+package keyring
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	secureDomain "hexagonal-fiber/domain/security"
+)
+
+// keyBits is the size of the generated RSA keys
+const keyBits = 2048
+
+// keyExtensions are the file extensions of the generated key pairs
+var keyExtensions = map[string]string{
+	secureDomain.AlgorithmRS256: ".rsa",
+	secureDomain.AlgorithmES256: ".ec",
+	secureDomain.AlgorithmEdDSA: ".ed25519",
+}
+
+// newPrivateKey generates a private key for the algorithm
+func newPrivateKey(algorithm string) (crypto.Signer, error) {
+	switch algorithm {
+	case secureDomain.AlgorithmRS256:
+		return rsa.GenerateKey(rand.Reader, keyBits)
+	case secureDomain.AlgorithmES256:
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case secureDomain.AlgorithmEdDSA:
+		_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		return privateKey, err
+	}
+
+	return nil, fmt.Errorf("algorithm %s does not use the key ring", algorithm)
+}
+
+// ReadManifest reads the key manifest at path
+func ReadManifest(path string) (*secureDomain.KeyManifest, error) {
+	manifestBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &secureDomain.KeyManifest{}
+	if err = json.Unmarshal(manifestBytes, manifest); err != nil {
+		return nil, err
+	}
+
+	for i := range manifest.Keys {
+		if manifest.Keys[i].Algorithm == "" {
+			manifest.Keys[i].Algorithm = secureDomain.AlgorithmRS256
+		}
+	}
+
+	return manifest, nil
+}
+
+// WriteManifest replaces the key manifest at path, through a rename so readers never see a partial file
+func WriteManifest(path string, manifest *secureDomain.KeyManifest) error {
+	manifestBytes, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmpPath := path + ".tmp"
+	if err = os.WriteFile(tmpPath, append(manifestBytes, '\n'), 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, path)
+}
+
+// GenerateKey writes a new key pair of the algorithm next to the manifest and registers it as the next key
+func GenerateKey(path string, algorithm string) (*secureDomain.KeyManifestEntry, error) {
+	manifest, err := ReadManifest(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range manifest.Keys {
+		if entry.Status == secureDomain.KeyNext && entry.Algorithm == algorithm {
+			return nil, fmt.Errorf("key %s is already waiting for rotation", entry.ID)
+		}
+	}
+
+	privateKey, err := newPrivateKey(algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	privateBytes, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	publicBytes, err := x509.MarshalPKIXPublicKey(privateKey.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	suffix := make([]byte, 4)
+	if _, err = rand.Read(suffix); err != nil {
+		return nil, err
+	}
+
+	nowTime := time.Now().UTC()
+	kid := nowTime.Format("20060102") + "-" + hex.EncodeToString(suffix)
+	entry := secureDomain.KeyManifestEntry{
+		ID:         kid,
+		Algorithm:  algorithm,
+		Status:     secureDomain.KeyNext,
+		PrivateKey: kid + keyExtensions[algorithm],
+		PublicKey:  kid + keyExtensions[algorithm] + ".pub",
+		CreatedAt:  nowTime,
+	}
+
+	dir := filepath.Dir(path)
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateBytes})
+	if err = os.WriteFile(filepath.Join(dir, entry.PrivateKey), privatePEM, 0600); err != nil {
+		return nil, err
+	}
+
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicBytes})
+	if err = os.WriteFile(filepath.Join(dir, entry.PublicKey), publicPEM, 0644); err != nil {
+		return nil, err
+	}
+
+	manifest.Keys = append(manifest.Keys, entry)
+	if err = WriteManifest(path, manifest); err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// RotateKey makes the next key of the algorithm active and keeps its previous active key as retiring
+func RotateKey(path string, algorithm string) (*secureDomain.KeyManifestEntry, error) {
+	manifest, err := ReadManifest(path)
+	if err != nil {
+		return nil, err
+	}
+
+	next := -1
+	for i, entry := range manifest.Keys {
+		if entry.Status == secureDomain.KeyNext && entry.Algorithm == algorithm {
+			next = i
+		}
+	}
+
+	if next == -1 {
+		return nil, fmt.Errorf("there is no next %s key, generate one first", algorithm)
+	}
+
+	for i := range manifest.Keys {
+		if manifest.Keys[i].Status == secureDomain.KeyActive && manifest.Keys[i].Algorithm == algorithm {
+			manifest.Keys[i].Status = secureDomain.KeyRetiring
+		}
+	}
+	manifest.Keys[next].Status = secureDomain.KeyActive
+
+	if err = WriteManifest(path, manifest); err != nil {
+		return nil, err
+	}
+
+	return &manifest.Keys[next], nil
+}
+
+// RetireKey removes a retiring key from the manifest along with its files, the tokens it signed stop verifying
+func RetireKey(path string, kid string) error {
+	manifest, err := ReadManifest(path)
+	if err != nil {
+		return err
+	}
+
+	for i, entry := range manifest.Keys {
+		if entry.ID != kid {
+			continue
+		}
+
+		if entry.Status != secureDomain.KeyRetiring {
+			return fmt.Errorf("key %s is %s, only retiring keys can be retired", kid, entry.Status)
+		}
+
+		manifest.Keys = append(manifest.Keys[:i], manifest.Keys[i+1:]...)
+		if err = WriteManifest(path, manifest); err != nil {
+			return err
+		}
+
+		dir := filepath.Dir(path)
+		_ = os.Remove(filepath.Join(dir, entry.PrivateKey))
+		_ = os.Remove(filepath.Join(dir, entry.PublicKey))
+		return nil
+	}
+
+	return fmt.Errorf("key %s not found", kid)
+}
@@ -0,0 +1,330 @@
+// Package oidc implements the relying party side of an OpenID Connect login,
+// the authorization code flow with PKCE and the validation of the ID token
+package oidc
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"hexagonal-fiber/application/security/keyring"
+	secureDomain "hexagonal-fiber/domain/security"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/gofiber/fiber/v2"
+	"github.com/spf13/viper"
+)
+
+const (
+	// discoveryTTL is how long the discovery document of a provider is trusted
+	discoveryTTL = time.Hour
+
+	// keysMissInterval limits the JWKS downloads caused by ID tokens with an unknown kid
+	keysMissInterval = 10 * time.Second
+
+	// leeway is the clock skew accepted on the times of the ID token
+	leeway = time.Minute
+
+	// maxResponseSize caps the documents read from a provider
+	maxResponseSize = 1 << 20
+)
+
+// ErrInvalidIDToken is returned when the ID token of a provider does not validate
+var ErrInvalidIDToken = errors.New("invalid id token")
+
+// Client talks to the configured identity providers, it caches their discovery documents and signing keys
+type Client struct {
+	HTTPClient *http.Client
+
+	mu          sync.Mutex
+	discoveries map[string]cachedDiscovery
+	keys        map[string]cachedKeys
+}
+
+type cachedDiscovery struct {
+	document  *secureDomain.OIDCDiscovery
+	fetchedAt time.Time
+}
+
+type cachedKeys struct {
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+// NewClient returns a client with a bounded http timeout
+func NewClient() *Client {
+	return &Client{HTTPClient: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Providers returns the identity providers of the config
+func Providers() ([]secureDomain.OIDCProvider, error) {
+	viper.SetConfigFile("config.json")
+	if err := viper.ReadInConfig(); err != nil {
+		return nil, err
+	}
+
+	var providers []secureDomain.OIDCProvider
+	if err := viper.UnmarshalKey("OIDC.Providers", &providers); err != nil {
+		return nil, err
+	}
+
+	return providers, nil
+}
+
+// Provider returns the identity provider of the config with the given name
+func Provider(name string) (*secureDomain.OIDCProvider, error) {
+	providers, err := Providers()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range providers {
+		if providers[i].Name == name {
+			return &providers[i], nil
+		}
+	}
+
+	return nil, fiber.NewError(fiber.StatusNotFound, "unknown identity provider")
+}
+
+// CodeChallenge returns the S256 PKCE challenge of the verifier, RFC 7636 section 4.2
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// Discover returns the discovery document of the issuer, it must name the issuer it was fetched from
+func (c *Client) Discover(issuer string) (*secureDomain.OIDCDiscovery, error) {
+	c.mu.Lock()
+	cached, ok := c.discoveries[issuer]
+	c.mu.Unlock()
+
+	if ok && time.Since(cached.fetchedAt) < discoveryTTL {
+		return cached.document, nil
+	}
+
+	var document secureDomain.OIDCDiscovery
+	if err := c.getJSON(strings.TrimSuffix(issuer, "/")+"/.well-known/openid-configuration", &document); err != nil {
+		return nil, err
+	}
+
+	if document.Issuer != issuer {
+		return nil, fmt.Errorf("discovery document of %s names the issuer %s", issuer, document.Issuer)
+	}
+
+	if document.AuthorizationEndpoint == "" || document.TokenEndpoint == "" || document.JWKSURI == "" {
+		return nil, fmt.Errorf("incomplete discovery document of %s", issuer)
+	}
+
+	c.mu.Lock()
+	if c.discoveries == nil {
+		c.discoveries = map[string]cachedDiscovery{}
+	}
+	c.discoveries[issuer] = cachedDiscovery{document: &document, fetchedAt: time.Now()}
+	c.mu.Unlock()
+
+	return &document, nil
+}
+
+// AuthCodeURL returns the authorization endpoint url the user is sent to, asking for a code bound to the PKCE verifier
+func (c *Client) AuthCodeURL(provider *secureDomain.OIDCProvider, state string, nonce string, verifier string) (string, error) {
+	document, err := c.Discover(provider.Issuer)
+	if err != nil {
+		return "", err
+	}
+
+	scopes := provider.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "email", "profile"}
+	}
+
+	values := url.Values{}
+	values.Set("response_type", "code")
+	values.Set("client_id", provider.ClientID)
+	values.Set("redirect_uri", provider.RedirectURL)
+	values.Set("scope", strings.Join(scopes, " "))
+	values.Set("state", state)
+	values.Set("nonce", nonce)
+	values.Set("code_challenge", CodeChallenge(verifier))
+	values.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(document.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+
+	return document.AuthorizationEndpoint + separator + values.Encode(), nil
+}
+
+// Authenticate exchanges the code of the callback for an ID token and returns the identity it vouches for
+func (c *Client) Authenticate(provider *secureDomain.OIDCProvider, code string, verifier string, nonce string) (*secureDomain.OIDCIdentity, error) {
+	rawIDToken, err := c.Exchange(provider, code, verifier)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.VerifyIDToken(provider, rawIDToken, nonce)
+}
+
+// Exchange redeems the authorization code at the token endpoint and returns the raw ID token
+func (c *Client) Exchange(provider *secureDomain.OIDCProvider, code string, verifier string) (string, error) {
+	document, err := c.Discover(provider.Issuer)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", provider.RedirectURL)
+	form.Set("code_verifier", verifier)
+
+	request, err := http.NewRequest(http.MethodPost, document.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationForm)
+	request.Header.Set(fiber.HeaderAccept, fiber.MIMEApplicationJSON)
+	request.SetBasicAuth(url.QueryEscape(provider.ClientID), url.QueryEscape(provider.ClientSecret))
+
+	response, err := c.HTTPClient.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	var tokens struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err = json.NewDecoder(io.LimitReader(response.Body, maxResponseSize)).Decode(&tokens); err != nil {
+		return "", fmt.Errorf("unreadable token response of %s: %w", provider.Name, err)
+	}
+
+	if response.StatusCode != http.StatusOK || tokens.Error != "" {
+		return "", fiber.NewError(fiber.StatusUnauthorized, "identity provider refused the code: "+tokens.Error)
+	}
+
+	if tokens.IDToken == "" {
+		return "", fiber.NewError(fiber.StatusUnauthorized, "identity provider returned no id token")
+	}
+
+	return tokens.IDToken, nil
+}
+
+// VerifyIDToken validates the signature, issuer, audience, times and nonce of the ID token, OpenID Connect Core section 3.1.3.7
+func (c *Client) VerifyIDToken(provider *secureDomain.OIDCProvider, rawIDToken string, nonce string) (*secureDomain.OIDCIdentity, error) {
+	document, err := c.Discover(provider.Issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := &idTokenClaims{}
+	_, err = jwt.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		switch token.Method.Alg() {
+		case secureDomain.AlgorithmRS256, secureDomain.AlgorithmES256:
+		default:
+			return nil, fmt.Errorf("unexpected signing algorithm %s", token.Method.Alg())
+		}
+
+		kid, _ := token.Header["kid"].(string)
+		return c.key(document.JWKSURI, kid)
+	})
+	if err != nil {
+		return nil, ErrInvalidIDToken
+	}
+
+	if claims.Issuer != provider.Issuer || !claims.Audience.contains(provider.ClientID) {
+		return nil, ErrInvalidIDToken
+	}
+
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != provider.ClientID {
+		return nil, ErrInvalidIDToken
+	}
+
+	if claims.Subject == "" || claims.Nonce == "" || claims.Nonce != nonce {
+		return nil, ErrInvalidIDToken
+	}
+
+	return &secureDomain.OIDCIdentity{
+		Provider:          provider.Name,
+		Subject:           claims.Subject,
+		Email:             strings.ToLower(strings.TrimSpace(claims.Email)),
+		EmailVerified:     bool(claims.EmailVerified),
+		Name:              claims.Name,
+		PreferredUsername: claims.PreferredUsername,
+	}, nil
+}
+
+// key returns the signing key of the provider with the kid, the key set is downloaded again on a miss
+// so a rotation at the provider is picked up, tokens without kid are accepted when the set holds one key
+func (c *Client) key(jwksURI string, kid string) (crypto.PublicKey, error) {
+	c.mu.Lock()
+	cached, ok := c.keys[jwksURI]
+	c.mu.Unlock()
+
+	if !ok || (lookup(cached.keys, kid) == nil && time.Since(cached.fetchedAt) > keysMissInterval) {
+		var jwks secureDomain.JWKS
+		if err := c.getJSON(jwksURI, &jwks); err != nil {
+			return nil, err
+		}
+
+		cached = cachedKeys{keys: map[string]crypto.PublicKey{}, fetchedAt: time.Now()}
+		for _, jwk := range jwks.Keys {
+			if jwk.Use != "" && jwk.Use != "sig" {
+				continue
+			}
+
+			if publicKey, err := keyring.PublicKey(jwk); err == nil {
+				cached.keys[jwk.KeyID] = publicKey
+			}
+		}
+
+		c.mu.Lock()
+		if c.keys == nil {
+			c.keys = map[string]cachedKeys{}
+		}
+		c.keys[jwksURI] = cached
+		c.mu.Unlock()
+	}
+
+	if publicKey := lookup(cached.keys, kid); publicKey != nil {
+		return publicKey, nil
+	}
+
+	return nil, secureDomain.ErrUnknownKey
+}
+
+func lookup(keys map[string]crypto.PublicKey, kid string) crypto.PublicKey {
+	if kid == "" && len(keys) == 1 {
+		for _, publicKey := range keys {
+			return publicKey
+		}
+	}
+
+	return keys[kid]
+}
+
+func (c *Client) getJSON(documentURL string, target interface{}) error {
+	response, err := c.HTTPClient.Get(documentURL)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s answered %d", documentURL, response.StatusCode)
+	}
+
+	return json.NewDecoder(io.LimitReader(response.Body, maxResponseSize)).Decode(target)
+}
@@ -0,0 +1,156 @@
+// Package password implements the password hashing. New hashes are Argon2id stored in the PHC
+// string format, bcrypt hashes of older accounts are still verified and flagged for a rehash
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	secureDomain "hexagonal-fiber/domain/security"
+
+	"github.com/spf13/viper"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrMalformedHash is returned for a stored hash in no known format
+var ErrMalformedHash = errors.New("malformed password hash")
+
+// default Argon2id parameters, the second recommended option of RFC 9106 section 4
+const (
+	defaultMemoryKiB   = 64 * 1024
+	defaultIterations  = 3
+	defaultParallelism = 4
+	defaultSaltLength  = 16
+	defaultKeyLength   = 32
+)
+
+// Argon2id is a struct that contains the parameters of the Argon2id hashing
+type Argon2id struct {
+	MemoryKiB   uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// NewHasher returns the password hasher with the Argon2id parameters of the config, missing ones take their default
+func NewHasher() (secureDomain.PasswordHasher, error) {
+	viper.SetConfigFile("config.json")
+	if err := viper.ReadInConfig(); err != nil {
+		return nil, err
+	}
+
+	hasher := &Argon2id{
+		MemoryKiB:   viper.GetUint32("Secure.Argon2MemoryKiB"),
+		Iterations:  viper.GetUint32("Secure.Argon2Iterations"),
+		Parallelism: uint8(viper.GetUint("Secure.Argon2Parallelism")),
+		SaltLength:  viper.GetUint32("Secure.Argon2SaltLength"),
+		KeyLength:   viper.GetUint32("Secure.Argon2KeyLength"),
+	}
+
+	if hasher.MemoryKiB == 0 {
+		hasher.MemoryKiB = defaultMemoryKiB
+	}
+	if hasher.Iterations == 0 {
+		hasher.Iterations = defaultIterations
+	}
+	if hasher.Parallelism == 0 {
+		hasher.Parallelism = defaultParallelism
+	}
+	if hasher.SaltLength == 0 {
+		hasher.SaltLength = defaultSaltLength
+	}
+	if hasher.KeyLength == 0 {
+		hasher.KeyLength = defaultKeyLength
+	}
+
+	return hasher, nil
+}
+
+// Hash returns the PHC string of the password, $argon2id$v=19$m=<KiB>,t=<iterations>,p=<parallelism>$<salt>$<key>
+func (a *Argon2id) Hash(password string) (string, error) {
+	salt := make([]byte, a.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, a.Iterations, a.MemoryKiB, a.Parallelism, a.KeyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, a.MemoryKiB, a.Iterations, a.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// Verify checks the password against an Argon2id or a bcrypt hash. Bcrypt hashes and Argon2id hashes
+// made with other parameters than the current ones ask for a rehash
+func (a *Argon2id) Verify(password string, hash string) (bool, bool, error) {
+	if isBcrypt(hash) {
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+		if err == bcrypt.ErrMismatchedHashAndPassword {
+			return false, false, nil
+		}
+
+		if err != nil {
+			return false, false, ErrMalformedHash
+		}
+
+		return true, true, nil
+	}
+
+	params, salt, key, err := decode(hash)
+	if err != nil {
+		return false, false, err
+	}
+
+	computed := argon2.IDKey([]byte(password), salt, params.Iterations, params.MemoryKiB, params.Parallelism, uint32(len(key)))
+	if subtle.ConstantTimeCompare(computed, key) != 1 {
+		return false, false, nil
+	}
+
+	rehash := params.MemoryKiB != a.MemoryKiB || params.Iterations != a.Iterations ||
+		params.Parallelism != a.Parallelism || uint32(len(salt)) != a.SaltLength || uint32(len(key)) != a.KeyLength
+
+	return true, rehash, nil
+}
+
+// decode parses an Argon2id PHC string
+func decode(hash string) (*Argon2id, []byte, []byte, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != "argon2id" {
+		return nil, nil, nil, ErrMalformedHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, nil, nil, ErrMalformedHash
+	}
+
+	params := &Argon2id{}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.MemoryKiB, &params.Iterations, &params.Parallelism); err != nil {
+		return nil, nil, nil, ErrMalformedHash
+	}
+
+	if params.MemoryKiB == 0 || params.Iterations == 0 || params.Parallelism == 0 {
+		return nil, nil, nil, ErrMalformedHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return nil, nil, nil, ErrMalformedHash
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return nil, nil, nil, ErrMalformedHash
+	}
+
+	return params, salt, key, nil
+}
+
+func isBcrypt(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
@@ -0,0 +1,86 @@
+// Package policy implements the ownership rules of the resources.
+// Usecases fetch the resource first so a missing one answers 404,
+// then authorize the actor on it so a resource of someone else answers 403
+package policy
+
+import (
+	apiKeyDomain "hexagonal-fiber/domain/apikey"
+	secureDomain "hexagonal-fiber/domain/security"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// actions on a resource
+const (
+	Read   = "read"
+	Create = "create"
+	Update = "update"
+	Delete = "delete"
+	// Restore brings a deleted resource back from the trash, listing the trash of a user needs it too
+	Restore = "restore"
+)
+
+// Policy is the ownership rule of a resource kind, the owner can perform every action
+// while other actors need the "<resource>:<action>:any" permission
+type Policy struct {
+	Resource string
+	Public   []string
+}
+
+// policies of the resources
+var (
+	Photo       = Policy{Resource: "photo", Public: []string{Read}}
+	Comment     = Policy{Resource: "comment", Public: []string{Read}}
+	SocialMedia = Policy{Resource: "sosmed", Public: []string{Read}}
+	Album       = Policy{Resource: "album"}
+	User        = Policy{Resource: "user"}
+	APIKey      = Policy{Resource: "apikey"}
+)
+
+// Authorize returns a forbidden error unless the actor may perform the action on a resource of the owner
+func (p Policy) Authorize(actor *secureDomain.Claims, action string, ownerID string) error {
+	for _, public := range p.Public {
+		if public == action {
+			return nil
+		}
+	}
+
+	if actor == nil {
+		return fiber.NewError(fiber.StatusForbidden, "you are not allowed to "+action+" this "+p.Resource)
+	}
+
+	if ownerID != "" && ownerID == actor.UserID {
+		return nil
+	}
+
+	if actor.Can(p.Permission(action)) {
+		return nil
+	}
+
+	return fiber.NewError(fiber.StatusForbidden, "you are not allowed to "+action+" this "+p.Resource)
+}
+
+// NotImpersonating returns a forbidden error when the actor impersonates a user, some actions
+// like changing the password or deleting the account stay with the user themself
+func NotImpersonating(actor *secureDomain.Claims, action string) error {
+	if actor != nil && actor.IsImpersonated() {
+		return fiber.NewError(fiber.StatusForbidden, "you are not allowed to "+action+" while impersonating")
+	}
+
+	return nil
+}
+
+// NotAPIKey returns a forbidden error when the actor authenticates with an api key. The scopes of a key only narrow the
+// permissions on the resources of others, so the account security of the owner stays with the owner logged in
+func NotAPIKey(actor *secureDomain.Claims, action string) error {
+	if actor != nil && actor.Type == apiKeyDomain.ClaimsType {
+		return fiber.NewError(fiber.StatusForbidden, "you are not allowed to "+action+" with an api key")
+	}
+
+	return nil
+}
+
+// Permission returns the permission letting an actor perform the action on resources it does not own
+func (p Policy) Permission(action string) string {
+	return p.Resource + ":" + action + ":any"
+}
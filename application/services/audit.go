@@ -0,0 +1,46 @@
+package services
+
+import (
+	"log"
+	"time"
+
+	auditDomain "hexagonal-fiber/domain/audit"
+	secureDomain "hexagonal-fiber/domain/security"
+)
+
+// Audit appends an action of the actor to the audit log. The audit log never fails the request,
+// a failing recorder is reported in the application log instead.
+// Under impersonation the admin is the actor and the impersonated user is recorded next to it
+func Audit(recorder auditDomain.Recorder, actor *secureDomain.Claims, event auditDomain.Event) {
+	if recorder == nil {
+		return
+	}
+
+	if actor != nil {
+		event.ActorID = actor.UserID
+		if actor.IsImpersonated() {
+			event.ActorID = actor.ActorID
+			event.ImpersonatedID = actor.UserID
+		}
+		event.IP = actor.Client.IP
+		event.UserAgent = actor.Client.UserAgent
+	}
+
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	if err := recorder.Record(event); err != nil {
+		log.Printf("failed recording audit event %s: %s", event.Action, err)
+	}
+}
+
+// AuditEdit appends an edit of a resource to the audit log unless the owner made it themself.
+// Owners editing their own resources is everyday use, only the edits made through a permission or an impersonation are audited
+func AuditEdit(recorder auditDomain.Recorder, actor *secureDomain.Claims, ownerID string, event auditDomain.Event) {
+	if actor != nil && actor.UserID == ownerID && !actor.IsImpersonated() {
+		return
+	}
+
+	Audit(recorder, actor, event)
+}
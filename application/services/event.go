@@ -0,0 +1,58 @@
+package services
+
+import (
+	"encoding/json"
+	"log"
+
+	auditDomain "hexagonal-fiber/domain/audit"
+	secureDomain "hexagonal-fiber/domain/security"
+)
+
+// LogEventPublisher publishes the security events to the application log and to the audit log
+type LogEventPublisher struct {
+	Audit auditDomain.Recorder
+}
+
+// NewEventPublisher returns the security event publisher of the application
+func NewEventPublisher(recorder auditDomain.Recorder) secureDomain.EventPublisher {
+	return &LogEventPublisher{Audit: recorder}
+}
+
+// Publish writes the event as a JSON log line and appends it to the audit log
+func (p *LogEventPublisher) Publish(event secureDomain.Event) error {
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("security event: %s", eventJSON)
+
+	if p.Audit == nil {
+		return nil
+	}
+
+	var changes auditDomain.Changes
+	if len(event.Detail) > 0 {
+		changes = auditDomain.Diff(nil, event.Detail)
+	}
+
+	targetID := event.UserID
+	if targetID == "" {
+		targetID = event.Subject
+	}
+
+	var targetType string
+	if targetID != "" {
+		targetType = auditDomain.TargetUser
+	}
+
+	return p.Audit.Record(auditDomain.Event{
+		Action:     auditDomain.ActionSecurityPrefix + event.Type,
+		ActorID:    event.ActorID,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Changes:    changes,
+		IP:         event.IP,
+		OccurredAt: event.OccurredAt,
+	})
+}
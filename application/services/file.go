@@ -0,0 +1,37 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	mailDomain "hexagonal-fiber/domain/mail"
+)
+
+// FileMailer is a mail sink for local development that writes every email to a file
+type FileMailer struct {
+	Directory string
+	From      string
+}
+
+// Send writes the message as an .eml file and logs where it was written
+func (m *FileMailer) Send(message mailDomain.Message) error {
+	directory := m.Directory
+	if directory == "" {
+		directory = "archives/mails"
+	}
+
+	if err := os.MkdirAll(directory, 0o755); err != nil {
+		return err
+	}
+
+	filename := filepath.Join(directory, fmt.Sprintf("%d_%s.eml", time.Now().UnixNano(), message.To))
+	if err := os.WriteFile(filename, composeMail(m.From, message), 0o600); err != nil {
+		return err
+	}
+
+	log.Printf("mail %q to %s written to %s", message.Subject, message.To, filename)
+	return nil
+}
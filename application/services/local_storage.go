@@ -0,0 +1,92 @@
+package services
+
+import (
+	"io"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	storageDomain "hexagonal-fiber/domain/storage"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// LocalStorage is a blob storage adapter that keeps every blob as a file under a directory
+type LocalStorage struct {
+	Directory string
+}
+
+// Put writes the blob to a temporary file first and moves it in place, a reader never sees half a blob
+func (s *LocalStorage) Put(key string, body io.Reader, size int64, contentType string) error {
+	filename := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(filename), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err = io.Copy(file, body); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err = file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), filename)
+}
+
+// Get opens the blob, its content type comes from the extension of the key
+func (s *LocalStorage) Get(key string) (*storageDomain.Object, error) {
+	file, err := os.Open(s.path(key))
+	if os.IsNotExist(err) {
+		return nil, fiber.NewError(fiber.StatusNotFound, "object not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &storageDomain.Object{
+		Key:         key,
+		ContentType: mime.TypeByExtension(path.Ext(key)),
+		Size:        info.Size(),
+		Body:        file,
+	}, nil
+}
+
+// Delete removes the blob, a missing one is already deleted
+func (s *LocalStorage) Delete(key string) error {
+	if err := os.Remove(s.path(key)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// URL is always empty, the local blobs are served through the api
+func (s *LocalStorage) URL(key string, expiry time.Duration) (string, error) {
+	return "", nil
+}
+
+// path maps the key under the directory, the key is cleaned as an absolute path first so it cannot climb out of it
+func (s *LocalStorage) path(key string) string {
+	directory := s.Directory
+	if directory == "" {
+		directory = "archives/objects"
+	}
+
+	return filepath.Join(directory, filepath.FromSlash(path.Clean("/"+key)))
+}
@@ -0,0 +1,60 @@
+// Package services provides external services connections
+package services
+
+import (
+	"fmt"
+
+	mailDomain "hexagonal-fiber/domain/mail"
+
+	"github.com/spf13/viper"
+)
+
+// NewMailer returns the mail adapter selected by the Mail.Driver config
+func NewMailer() (mailer mailDomain.Mailer, err error) {
+	viper.SetConfigFile("config.json")
+	if err = viper.ReadInConfig(); err != nil {
+		return
+	}
+
+	from := viper.GetString("Mail.From")
+
+	switch driver := viper.GetString("Mail.Driver"); driver {
+	case "smtp":
+		mailer = &SMTPMailer{
+			Hostname: viper.GetString("Mail.SMTP.Hostname"),
+			Port:     viper.GetString("Mail.SMTP.Port"),
+			Username: viper.GetString("Mail.SMTP.Username"),
+			Password: viper.GetString("Mail.SMTP.Password"),
+			From:     from,
+		}
+	case "file", "":
+		mailer = &FileMailer{
+			Directory: viper.GetString("Mail.FileDirectory"),
+			From:      from,
+		}
+	default:
+		err = fmt.Errorf("unknown mail driver: %s", driver)
+	}
+
+	return
+}
+
+// SendSimpleMail is a function that sends a simple email through the configured mailer
+func SendSimpleMail(to string, subject string, body string) (err error) {
+	mailer, err := NewMailer()
+	if err != nil {
+		return
+	}
+
+	return mailer.Send(mailDomain.Message{
+		To:      to,
+		Subject: subject,
+		Body:    body,
+	})
+}
+
+// composeMail renders the message as a plain text RFC 5322 email
+func composeMail(from string, message mailDomain.Message) []byte {
+	return []byte(fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nMIME-Version: 1.0\r\nContent-Type: text/plain; charset=\"utf-8\"\r\n\r\n%s\r\n",
+		from, message.To, message.Subject, message.Body))
+}
@@ -0,0 +1,310 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	storageDomain "hexagonal-fiber/domain/storage"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	s3Algorithm      = "AWS4-HMAC-SHA256"
+	s3Service        = "s3"
+	s3DateFormat     = "20060102T150405Z"
+	s3UnsignedBody   = "UNSIGNED-PAYLOAD"
+	s3MaxPresignTime = 7 * 24 * time.Hour
+)
+
+// S3Storage is a blob storage adapter for Amazon S3 and the S3 compatible servers, requests are signed with Signature Version 4
+type S3Storage struct {
+	// Endpoint is the scheme and host of the server like https://s3.amazonaws.com or http://localhost:9000
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	// PathStyle puts the bucket in the path instead of the host, most self hosted servers need it
+	PathStyle bool
+	Client    *http.Client
+}
+
+// Put uploads the blob, the body is streamed so the payload is left unsigned
+func (s *S3Storage) Put(key string, body io.Reader, size int64, contentType string) error {
+	request, err := http.NewRequest(http.MethodPut, s.objectURL(key), body)
+	if err != nil {
+		return err
+	}
+
+	request.ContentLength = size
+	request.Header.Set("Content-Type", contentType)
+
+	response, err := s.do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return s3Error(response, key)
+}
+
+// Get downloads the blob, the body is read straight from the response
+func (s *S3Storage) Get(key string) (*storageDomain.Object, error) {
+	request, err := http.NewRequest(http.MethodGet, s.objectURL(key), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s3Error(response, key); err != nil {
+		response.Body.Close()
+		return nil, err
+	}
+
+	return &storageDomain.Object{
+		Key:         key,
+		ContentType: response.Header.Get("Content-Type"),
+		Size:        response.ContentLength,
+		Body:        response.Body,
+	}, nil
+}
+
+// Delete removes the blob, the server answers a missing one as deleted too
+func (s *S3Storage) Delete(key string) error {
+	request, err := http.NewRequest(http.MethodDelete, s.objectURL(key), nil)
+	if err != nil {
+		return err
+	}
+
+	response, err := s.do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return s3Error(response, key)
+}
+
+// URL returns a presigned address the blob can be downloaded from without credentials until it expires
+func (s *S3Storage) URL(key string, expiry time.Duration) (string, error) {
+	return s.Presign(http.MethodGet, key, expiry, time.Now())
+}
+
+// Sign adds the Signature Version 4 authorization of the request, the headers to sign must be set already
+func (s *S3Storage) Sign(request *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.UTC().Format(s3DateFormat)
+	request.Header.Set("X-Amz-Date", amzDate)
+	request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	names, canonicalHeaders := s3CanonicalHeaders(request)
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		s3CanonicalURI(request.URL),
+		s3CanonicalQuery(request.URL.Query()),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := s.scope(amzDate)
+	signature := s.signature(amzDate, scope, canonicalRequest)
+
+	request.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.AccessKey, scope, signedHeaders, signature))
+}
+
+// Presign returns the address of the object signed in its query, only the host header is signed
+func (s *S3Storage) Presign(method string, key string, expiry time.Duration, now time.Time) (string, error) {
+	if expiry <= 0 || expiry > s3MaxPresignTime {
+		return "", fmt.Errorf("presigned url expiry must be within %s", s3MaxPresignTime)
+	}
+
+	objectURL, err := url.Parse(s.objectURL(key))
+	if err != nil {
+		return "", err
+	}
+
+	amzDate := now.UTC().Format(s3DateFormat)
+	scope := s.scope(amzDate)
+
+	query := objectURL.Query()
+	query.Set("X-Amz-Algorithm", s3Algorithm)
+	query.Set("X-Amz-Credential", s.AccessKey+"/"+scope)
+	query.Set("X-Amz-Date", amzDate)
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expiry.Seconds())))
+	query.Set("X-Amz-SignedHeaders", "host")
+
+	canonicalQuery := s3CanonicalQuery(query)
+	canonicalRequest := strings.Join([]string{
+		method,
+		s3CanonicalURI(objectURL),
+		canonicalQuery,
+		"host:" + objectURL.Host + "\n",
+		"host",
+		s3UnsignedBody,
+	}, "\n")
+
+	objectURL.RawQuery = canonicalQuery + "&X-Amz-Signature=" + s.signature(amzDate, scope, canonicalRequest)
+	return objectURL.String(), nil
+}
+
+func (s *S3Storage) do(request *http.Request) (*http.Response, error) {
+	s.Sign(request, s3UnsignedBody, time.Now())
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return client.Do(request)
+}
+
+// objectURL addresses the object in the virtual hosted style unless the path style is configured
+func (s *S3Storage) objectURL(key string) string {
+	endpoint, err := url.Parse(s.Endpoint)
+	if err != nil || endpoint.Host == "" {
+		return strings.TrimSuffix(s.Endpoint, "/") + "/" + s.Bucket + "/" + key
+	}
+
+	if s.PathStyle {
+		endpoint.Path = "/" + s.Bucket + "/" + key
+	} else {
+		endpoint.Host = s.Bucket + "." + endpoint.Host
+		endpoint.Path = "/" + key
+	}
+
+	return endpoint.String()
+}
+
+func (s *S3Storage) scope(amzDate string) string {
+	return amzDate[:8] + "/" + s.Region + "/" + s3Service + "/aws4_request"
+}
+
+// signature derives the signing key of the day and signs the canonical request with it
+func (s *S3Storage) signature(amzDate string, scope string, canonicalRequest string) string {
+	hashedRequest := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{s3Algorithm, amzDate, scope, hex.EncodeToString(hashedRequest[:])}, "\n")
+
+	key := []byte("AWS4" + s.SecretKey)
+	for _, part := range []string{amzDate[:8], s.Region, s3Service, "aws4_request"} {
+		key = s3HMAC(key, part)
+	}
+
+	return hex.EncodeToString(s3HMAC(key, stringToSign))
+}
+
+func s3HMAC(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// s3CanonicalHeaders signs the host, the content headers and every x-amz header
+func s3CanonicalHeaders(request *http.Request) ([]string, string) {
+	host := request.Host
+	if host == "" {
+		host = request.URL.Host
+	}
+
+	values := map[string]string{"host": host}
+	for name, value := range request.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-amz-") || lower == "content-type" || lower == "content-md5" || lower == "range" {
+			values[lower] = strings.TrimSpace(strings.Join(value, ","))
+		}
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonical strings.Builder
+	for _, name := range names {
+		canonical.WriteString(name + ":" + values[name] + "\n")
+	}
+
+	return names, canonical.String()
+}
+
+func s3CanonicalURI(objectURL *url.URL) string {
+	uri := objectURL.EscapedPath()
+	if uri == "" {
+		return "/"
+	}
+
+	segments := strings.Split(uri, "/")
+	for i, segment := range segments {
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil {
+			unescaped = segment
+		}
+		segments[i] = s3Escape(unescaped)
+	}
+
+	return strings.Join(segments, "/")
+}
+
+func s3CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var pairs []string
+	for _, key := range keys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, s3Escape(key)+"="+s3Escape(value))
+		}
+	}
+
+	return strings.Join(pairs, "&")
+}
+
+// s3Escape percent encodes everything but the unreserved characters as Signature Version 4 expects
+func s3Escape(value string) string {
+	var escaped strings.Builder
+	for _, b := range []byte(value) {
+		if 'A' <= b && b <= 'Z' || 'a' <= b && b <= 'z' || '0' <= b && b <= '9' || b == '-' || b == '_' || b == '.' || b == '~' {
+			escaped.WriteByte(b)
+		} else {
+			fmt.Fprintf(&escaped, "%%%02X", b)
+		}
+	}
+
+	return escaped.String()
+}
+
+// s3Error turns a failed response into an error, a missing object answers not found
+func s3Error(response *http.Response, key string) error {
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return nil
+	}
+
+	if response.StatusCode == http.StatusNotFound {
+		return fiber.NewError(fiber.StatusNotFound, "object not found")
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+	return fmt.Errorf("s3 %s %s answered %s: %s", response.Request.Method, key, response.Status, strings.TrimSpace(string(body)))
+}
@@ -1,8 +0,0 @@
-// Package services provides external services connections
-package services
-
-// SendSimpleMail is a function that sends a simple email
-func SendSimpleMail() (err error) {
-
-	return
-}
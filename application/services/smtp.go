@@ -0,0 +1,26 @@
+package services
+
+import (
+	"net/smtp"
+
+	mailDomain "hexagonal-fiber/domain/mail"
+)
+
+// SMTPMailer is a mail adapter that delivers emails through an SMTP server
+type SMTPMailer struct {
+	Hostname string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// Send delivers the message through the SMTP server
+func (m *SMTPMailer) Send(message mailDomain.Message) error {
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Hostname)
+	}
+
+	return smtp.SendMail(m.Hostname+":"+m.Port, auth, m.From, []string{message.To}, composeMail(m.From, message))
+}
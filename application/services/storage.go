@@ -0,0 +1,47 @@
+package services
+
+import (
+	"fmt"
+	"log"
+
+	storageDomain "hexagonal-fiber/domain/storage"
+
+	"github.com/spf13/viper"
+)
+
+// NewStorage returns the blob storage adapter selected by the Storage.Driver config
+func NewStorage() (storage storageDomain.Storage, err error) {
+	viper.SetConfigFile("config.json")
+	if err = viper.ReadInConfig(); err != nil {
+		return
+	}
+
+	switch driver := viper.GetString("Storage.Driver"); driver {
+	case "s3":
+		storage = &S3Storage{
+			Endpoint:  viper.GetString("Storage.S3.Endpoint"),
+			Region:    viper.GetString("Storage.S3.Region"),
+			Bucket:    viper.GetString("Storage.S3.Bucket"),
+			AccessKey: viper.GetString("Storage.S3.AccessKey"),
+			SecretKey: viper.GetString("Storage.S3.SecretKey"),
+			PathStyle: viper.GetBool("Storage.S3.PathStyle"),
+		}
+	case "local", "":
+		storage = &LocalStorage{
+			Directory: viper.GetString("Storage.Local.Directory"),
+		}
+	default:
+		err = fmt.Errorf("unknown storage driver: %s", driver)
+	}
+
+	return
+}
+
+// RemoveObjects deletes the blobs whose records are gone, a failure only leaves an orphan blob behind so it is logged
+func RemoveObjects(storage storageDomain.Storage, keys []string) {
+	for _, key := range keys {
+		if err := storage.Delete(key); err != nil {
+			log.Printf("failed removing object %s: %s", key, err)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	userRepository "hexagonal-fiber/infrastructure/repository/postgres/user"
+	tokenRepository "hexagonal-fiber/infrastructure/repository/redis/token"
+)
+
+// TokenVersions keeps the token version of the users. Tokens carry the version of their user when issued,
+// bumping it invalidates every token issued before. Postgres holds the version and redis caches it
+type TokenVersions struct {
+	UserRepository  userRepository.Repository
+	TokenRepository tokenRepository.Repository
+}
+
+// Current returns the token version of the user
+func (t *TokenVersions) Current(userID string) (int, error) {
+	version, found, err := t.TokenRepository.GetVersion(userID)
+	if err != nil || found {
+		return version, err
+	}
+
+	version, err = t.UserRepository.GetTokenVersion(userID)
+	if err != nil {
+		return 0, err
+	}
+
+	return version, t.TokenRepository.FillVersion(userID, version)
+}
+
+// Bump invalidates every token of the user
+func (t *TokenVersions) Bump(userID string) error {
+	version, err := t.UserRepository.BumpTokenVersion(userID)
+	if err != nil {
+		return err
+	}
+
+	return t.TokenRepository.SetVersion(userID, version)
+}
+
+// BumpRole invalidates every token of the users of a role
+func (t *TokenVersions) BumpRole(roleID string) error {
+	versions, err := t.UserRepository.BumpRoleTokenVersion(roleID)
+	if err != nil {
+		return err
+	}
+
+	for userID, version := range versions {
+		if err = t.TokenRepository.SetVersion(userID, version); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
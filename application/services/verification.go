@@ -0,0 +1,31 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"hexagonal-fiber/application/security/jwt"
+	mailDomain "hexagonal-fiber/domain/mail"
+	secureDomain "hexagonal-fiber/domain/security"
+	userDomain "hexagonal-fiber/domain/user"
+
+	"github.com/spf13/viper"
+)
+
+// SendVerification mails a signed and expiring verification link to the user, the link is bound to the current email
+func SendVerification(mailer mailDomain.Mailer, user *userDomain.User) error {
+	verifyClaims := &secureDomain.Claims{UserID: user.ID.String()}
+	verifyClaims.Subject = user.Email
+
+	verifyToken, err := jwt.GenerateJWTToken(jwt.Verify, verifyClaims)
+	if err != nil {
+		return err
+	}
+
+	return mailer.Send(mailDomain.Message{
+		To:      user.Email,
+		Subject: "Verify your email",
+		Body: fmt.Sprintf("Hi %s,\n\nplease verify your email by opening the link below before %s.\n\n%s/v1/auth/verify?token=%s\n",
+			user.UserName, verifyToken.ExpirationTime.Format(time.RFC1123), viper.GetString("ServerURL"), verifyToken.Token),
+	})
+}
@@ -0,0 +1,141 @@
+package album
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	albumDomain "hexagonal-fiber/domain/album"
+	secureDomain "hexagonal-fiber/domain/security"
+	albumController "hexagonal-fiber/infrastructure/restapi/controllers/album"
+	authConst "hexagonal-fiber/utils/constant/auth"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	first  = "cef47ee2-7211-452a-a087-79ce4b8ec3a3"
+	second = "0b6f3a4e-8c1d-4f7e-9a52-3d2e1c0b9a87"
+	third  = "5d9c2b1a-7e6f-4a3b-8c2d-1e0f9a8b7c6d"
+)
+
+type AlbumTestSuite struct {
+	suite.Suite
+}
+
+func TestAlbumTestSuite(t *testing.T) {
+	suite.Run(t, &AlbumTestSuite{})
+}
+
+func (ts *AlbumTestSuite) TestNewAlbumDefaults() {
+	album := (&albumDomain.NewAlbum{Title: "Holidays", UserID: "owner"}).ToDomainMapper()
+	ts.Equal(albumDomain.Manual, album.Ordering)
+	ts.Equal(albumDomain.Private, album.Visibility, "an album is private until told otherwise")
+	ts.Equal("owner", album.UserID)
+
+	album = (&albumDomain.NewAlbum{Title: "Holidays", Ordering: albumDomain.Taken, Visibility: albumDomain.Public}).ToDomainMapper()
+	ts.Equal(albumDomain.Taken, album.Ordering)
+	ts.Equal(albumDomain.Public, album.Visibility)
+}
+
+func (ts *AlbumTestSuite) TestUpdateAlbumColumns() {
+	title, empty, visibility := "Summer", "", albumDomain.Public
+
+	columns := (&albumDomain.UpdateAlbum{Title: &title, Visibility: &visibility}).ToMap()
+	ts.Equal(map[string]interface{}{"title": "Summer", "visibility": albumDomain.Public}, columns)
+
+	columns = (&albumDomain.UpdateAlbum{Description: &empty, CoverPhotoID: &empty}).ToMap()
+	ts.Equal("", columns["description"], "an empty description clears it")
+	ts.Contains(columns, "cover_photo_id")
+	ts.Nil(columns["cover_photo_id"], "an empty cover removes it")
+
+	ts.Empty((&albumDomain.UpdateAlbum{}).ToMap())
+}
+
+func (ts *AlbumTestSuite) TestReorderListsEveryShownPhotoOnce() {
+	shown := []string{first, second, third}
+
+	ts.True(albumDomain.IsOrderOf([]string{third, first, second}, shown))
+	ts.False(albumDomain.IsOrderOf([]string{third, first}, shown), "a photo is missing")
+	ts.False(albumDomain.IsOrderOf([]string{third, first, first}, shown), "a photo is given twice")
+	ts.False(albumDomain.IsOrderOf([]string{third, first, second, second}, shown), "a photo is given twice")
+	ts.False(albumDomain.IsOrderOf([]string{third, first, "unknown"}, shown), "a photo is not shown by the album")
+
+	// a deleted photo is not shown, its link is kept after the others without being listed
+	ts.True(albumDomain.IsOrderOf([]string{second, first}, []string{first, second}))
+	ts.False(albumDomain.IsOrderOf([]string{second, first, third}, []string{first, second}))
+}
+
+func (ts *AlbumTestSuite) TestAddOrRemoveTheSamePhotoOnce() {
+	ts.Equal([]string{second, first}, albumDomain.UniquePhotoIDs([]string{second, first, second, first}))
+	ts.Empty(albumDomain.UniquePhotoIDs(nil))
+}
+
+func (ts *AlbumTestSuite) TestCoverAfterAdding() {
+	album := albumDomain.Album{}
+	cover, changed := album.CoverAfterAdding([]string{second, first})
+	ts.True(changed, "the first photo added to an album without a cover becomes its cover")
+	ts.Equal(second, cover)
+
+	_, changed = album.CoverAfterAdding(nil)
+	ts.False(changed)
+
+	current := first
+	album.CoverPhotoID = &current
+	cover, changed = album.CoverAfterAdding([]string{second})
+	ts.False(changed, "an album keeps its cover")
+	ts.Equal(first, cover)
+}
+
+func (ts *AlbumTestSuite) TestCoverIsAShownPhoto() {
+	shown := []string{first, second}
+
+	ts.True(albumDomain.IsValidCover(second, shown))
+	ts.True(albumDomain.IsValidCover("", shown), "an empty cover removes it")
+	ts.False(albumDomain.IsValidCover(third, shown))
+	ts.False(albumDomain.IsValidCover(first, nil))
+}
+
+// request sends the body to the album routes, the invalid requests are refused before reaching the service
+func (ts *AlbumTestSuite) request(method string, target string, body string) int {
+	controller := &albumController.Controller{}
+
+	app := fiber.New()
+	app.Use(func(ctx *fiber.Ctx) error {
+		ctx.Locals(authConst.Authorized, &secureDomain.Claims{UserID: "owner"})
+		return ctx.Next()
+	})
+	app.Put("/albums/:id", controller.UpdateAlbum)
+	app.Post("/albums/:id/photos", controller.AddAlbumPhotos)
+	app.Delete("/albums/:id/photos", controller.RemoveAlbumPhotos)
+	app.Put("/albums/:id/photos/order", controller.ReorderAlbumPhotos)
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+
+	resp, err := app.Test(req)
+	ts.Require().NoError(err)
+	return resp.StatusCode
+}
+
+func (ts *AlbumTestSuite) TestPhotosRequestValidation() {
+	tooMany := strings.TrimSuffix(strings.Repeat(`"`+first+`",`, 101), ",")
+
+	for _, method := range []string{fiber.MethodPost, fiber.MethodDelete} {
+		ts.Equal(fiber.StatusBadRequest, ts.request(method, "/albums/"+first+"/photos", `{"photo_ids": []}`), method)
+		ts.Equal(fiber.StatusBadRequest, ts.request(method, "/albums/"+first+"/photos", `{"photo_ids": [`+tooMany+`]}`), method)
+		ts.Equal(fiber.StatusBadRequest, ts.request(method, "/albums/"+first+"/photos", `{"photo_ids": ["not a photo"]}`), method)
+		ts.Equal(fiber.StatusBadRequest, ts.request(method, "/albums/"+first+"/photos", `{"photo_ids": ["`+strings.ToUpper(first)+`"]}`), method,
+			"the ids are only accepted the way they are stored")
+	}
+
+	ts.Equal(fiber.StatusBadRequest, ts.request(fiber.MethodPut, "/albums/"+first+"/photos/order", `{"photo_ids": []}`))
+	ts.Equal(fiber.StatusBadRequest, ts.request(fiber.MethodPut, "/albums/"+first+"/photos/order", `{"photo_ids": ["not a photo"]}`))
+}
+
+func (ts *AlbumTestSuite) TestCoverRequestValidation() {
+	ts.Equal(fiber.StatusBadRequest, ts.request(fiber.MethodPut, "/albums/"+first, `{"cover_photo_id": "not a photo"}`))
+	ts.Equal(fiber.StatusBadRequest, ts.request(fiber.MethodPut, "/albums/"+first, `{"title": ""}`))
+	ts.Equal(fiber.StatusBadRequest, ts.request(fiber.MethodPut, "/albums/"+first, `{"ordering": "random"}`))
+}
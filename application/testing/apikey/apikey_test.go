@@ -0,0 +1,137 @@
+package apikey
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	apiKeyService "hexagonal-fiber/application/usecases/apikey"
+	userService "hexagonal-fiber/application/usecases/user"
+	apiKeyDomain "hexagonal-fiber/domain/apikey"
+	secureDomain "hexagonal-fiber/domain/security"
+	userDomain "hexagonal-fiber/domain/user"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/suite"
+)
+
+type APIKeyTestSuite struct {
+	suite.Suite
+}
+
+func TestAPIKeyTestSuite(t *testing.T) {
+	suite.Run(t, &APIKeyTestSuite{})
+}
+
+func (as *APIKeyTestSuite) TestGeneratedKeyParsesBack() {
+	key, prefix, secretHash, err := apiKeyDomain.GenerateKey()
+	as.Require().NoError(err)
+
+	parsedPrefix, secret, ok := apiKeyDomain.ParseKey(key)
+
+	as.True(ok)
+	as.True(strings.HasPrefix(prefix, "hfk_"))
+	as.Equal(prefix, parsedPrefix)
+	as.Equal(secretHash, secureDomain.HashToken(secret))
+	as.NotContains(secretHash, secret)
+}
+
+func (as *APIKeyTestSuite) TestGeneratedKeysDiffer() {
+	first, firstPrefix, _, err := apiKeyDomain.GenerateKey()
+	as.Require().NoError(err)
+	second, secondPrefix, _, err := apiKeyDomain.GenerateKey()
+	as.Require().NoError(err)
+
+	as.NotEqual(first, second)
+	as.NotEqual(firstPrefix, secondPrefix)
+}
+
+func (as *APIKeyTestSuite) TestParseRejectsMalformedKeys() {
+	for _, key := range []string{"", "hfk_", "hfk_abc", "hfk_abc_", "hfk__secret", "Bearer abc_def"} {
+		_, _, ok := apiKeyDomain.ParseKey(key)
+		as.False(ok, key)
+	}
+}
+
+func (as *APIKeyTestSuite) TestExpiry() {
+	now := time.Now()
+	past, future := now.Add(-time.Minute), now.Add(time.Minute)
+
+	as.False((&apiKeyDomain.APIKey{}).IsExpired(now))
+	as.True((&apiKeyDomain.APIKey{ExpiresAt: &past}).IsExpired(now))
+	as.False((&apiKeyDomain.APIKey{ExpiresAt: &future}).IsExpired(now))
+}
+
+func (as *APIKeyTestSuite) TestEffectivePermissionsNeverOutrankOwner() {
+	key := apiKeyDomain.APIKey{Scopes: []string{"photo:update:any", "audit:read"}}
+
+	as.Equal([]string{"photo:update:any"}, key.EffectivePermissions([]string{"photo:update:any", "role:manage"}))
+	as.Empty(key.EffectivePermissions(nil))
+}
+
+func (as *APIKeyTestSuite) TestCreateRefusesScopesActorLacks() {
+	service := apiKeyService.Service{}
+	actor := &secureDomain.Claims{UserID: "owner", Permissions: []string{"photo:update:any"}}
+
+	_, err := service.Create(actor, apiKeyDomain.NewAPIKey{Name: "export", Scopes: []string{"role:manage"}})
+
+	as.Equal(fiber.StatusForbidden, err.(*fiber.Error).Code)
+}
+
+func (as *APIKeyTestSuite) TestCreateRefusesAPIKeyActor() {
+	service := apiKeyService.Service{}
+	actor := &secureDomain.Claims{UserID: "owner", Type: apiKeyDomain.ClaimsType}
+
+	_, err := service.Create(actor, apiKeyDomain.NewAPIKey{Name: "export"})
+
+	as.Equal(fiber.StatusForbidden, err.(*fiber.Error).Code)
+}
+
+func (as *APIKeyTestSuite) TestDeleteRefusesAPIKeyActor() {
+	service := apiKeyService.Service{}
+	actor := &secureDomain.Claims{UserID: "owner", Type: apiKeyDomain.ClaimsType}
+
+	err := service.Delete(actor, "8d2b0a55-5b0e-4c1a-9a39-1f0f5f6c1a01")
+
+	as.Equal(fiber.StatusForbidden, err.(*fiber.Error).Code)
+}
+
+func (as *APIKeyTestSuite) TestCreateForAnotherUserNeedsPermission() {
+	service := apiKeyService.Service{}
+	actor := &secureDomain.Claims{UserID: "owner"}
+
+	_, err := service.Create(actor, apiKeyDomain.NewAPIKey{Name: "export", UserID: "someone-else"})
+
+	as.Equal(fiber.StatusForbidden, err.(*fiber.Error).Code)
+}
+
+func (as *APIKeyTestSuite) TestAuthenticateRejectsMalformedKey() {
+	service := apiKeyService.Service{}
+
+	_, err := service.Authenticate("not-a-key")
+
+	as.Equal(fiber.StatusUnauthorized, err.(*fiber.Error).Code)
+}
+
+// TestKeyCannotTakeTheAccountOver checks a key without scopes, acting as its owner, cannot change the password or the email
+// nor delete the account
+func (as *APIKeyTestSuite) TestKeyCannotTakeTheAccountOver() {
+	ownerID := "8d2b0a55-5b0e-4c1a-9a39-1f0f5f6c1a01"
+	key := &secureDomain.Claims{UserID: ownerID, Type: apiKeyDomain.ClaimsType}
+	password := "N3w-Passw0rd!"
+
+	service := userService.Service{}
+
+	_, err := service.Update(key, ownerID, userDomain.UpdateUser{Password: &password})
+	as.Require().Error(err)
+	as.Equal(fiber.StatusForbidden, err.(*fiber.Error).Code)
+
+	email := "taken@over.com"
+	_, err = service.Update(key, ownerID, userDomain.UpdateUser{Email: &email})
+	as.Require().Error(err)
+	as.Equal(fiber.StatusForbidden, err.(*fiber.Error).Code)
+
+	err = service.Delete(key, ownerID)
+	as.Require().Error(err)
+	as.Equal(fiber.StatusForbidden, err.(*fiber.Error).Code)
+}
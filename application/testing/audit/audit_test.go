@@ -0,0 +1,118 @@
+package audit
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"hexagonal-fiber/application/services"
+	auditDomain "hexagonal-fiber/domain/audit"
+	photoDomain "hexagonal-fiber/domain/photo"
+	secureDomain "hexagonal-fiber/domain/security"
+	userDomain "hexagonal-fiber/domain/user"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type memoryRecorder struct {
+	events []auditDomain.Event
+	err    error
+}
+
+func (m *memoryRecorder) Record(event auditDomain.Event) error {
+	if m.err != nil {
+		return m.err
+	}
+
+	m.events = append(m.events, event)
+	return nil
+}
+
+type AuditTestSuite struct {
+	suite.Suite
+}
+
+func TestAuditTestSuite(t *testing.T) {
+	suite.Run(t, &AuditTestSuite{})
+}
+
+func (as *AuditTestSuite) TestDiffKeepsChangedFields() {
+	before := photoDomain.Photo{Title: "old", Caption: "same", UserID: "owner", UpdatedAt: time.Unix(1, 0)}
+	after := photoDomain.Photo{Title: "new", Caption: "same", UserID: "owner", UpdatedAt: time.Unix(2, 0)}
+
+	changes := auditDomain.Diff(before, after)
+
+	as.Equal(auditDomain.Changes{"title": {Before: "old", After: "new"}}, changes)
+}
+
+func (as *AuditTestSuite) TestDiffOfDeletionSnapshotsTarget() {
+	before := &photoDomain.Photo{Title: "title", PhotoUrl: "www.photo.com"}
+
+	changes := auditDomain.Diff(before, nil)
+
+	as.Equal("title", changes["title"].Before)
+	as.Nil(changes["title"].After)
+	as.Equal("www.photo.com", changes["photo_url"].Before)
+}
+
+func (as *AuditTestSuite) TestDiffRedactsCredentials() {
+	before := userDomain.User{Email: "user@mail.com", HashPassword: "hash-one", TOTPSecret: "secret"}
+	after := userDomain.User{Email: "user@mail.com", HashPassword: "hash-two", TOTPSecret: "other"}
+
+	changes := auditDomain.Diff(before, after)
+
+	as.Equal(auditDomain.Changes{"hash_password": {Before: "[redacted]", After: "[redacted]"}}, changes)
+}
+
+func (as *AuditTestSuite) TestAuditRecordsActorAndClient() {
+	recorder := &memoryRecorder{}
+	actor := &secureDomain.Claims{
+		UserID: "admin",
+		Client: secureDomain.ClientInfo{IP: "127.0.0.1", UserAgent: "Mozilla/5.0"},
+	}
+
+	services.Audit(recorder, actor, auditDomain.Event{Action: auditDomain.ActionPhotoDelete, TargetID: "photo"})
+
+	as.Len(recorder.events, 1)
+	as.Equal("admin", recorder.events[0].ActorID)
+	as.Equal("127.0.0.1", recorder.events[0].IP)
+	as.Equal("Mozilla/5.0", recorder.events[0].UserAgent)
+	as.False(recorder.events[0].OccurredAt.IsZero())
+}
+
+func (as *AuditTestSuite) TestAuditNeverFailsTheRequest() {
+	as.NotPanics(func() {
+		services.Audit(nil, nil, auditDomain.Event{Action: auditDomain.ActionLogin})
+		services.Audit(&memoryRecorder{err: errors.New("down")}, nil, auditDomain.Event{Action: auditDomain.ActionLogin})
+	})
+}
+
+func (as *AuditTestSuite) TestSecurityEventsReachTheAuditLog() {
+	recorder := &memoryRecorder{}
+	publisher := services.NewEventPublisher(recorder)
+
+	err := publisher.Publish(secureDomain.Event{
+		Type:    secureDomain.EventAccountLocked,
+		UserID:  "user",
+		IP:      "127.0.0.1",
+		Detail:  map[string]interface{}{"failures": 5},
+		Subject: "user@mail.com",
+	})
+
+	as.NoError(err)
+	as.Len(recorder.events, 1)
+	as.Equal(auditDomain.ActionSecurityPrefix+secureDomain.EventAccountLocked, recorder.events[0].Action)
+	as.Equal("user", recorder.events[0].TargetID)
+	as.Equal(float64(5), recorder.events[0].Changes["failures"].After)
+}
+
+func (as *AuditTestSuite) TestAuditAttributesImpersonationToAdmin() {
+	recorder := &memoryRecorder{}
+	actor := &secureDomain.Claims{UserID: "user", ActorID: "admin"}
+
+	services.Audit(recorder, actor, auditDomain.Event{Action: auditDomain.ActionImpersonationWrite, TargetID: "user"})
+
+	as.Len(recorder.events, 1)
+	as.Equal("admin", recorder.events[0].ActorID)
+	as.Equal("user", recorder.events[0].ImpersonatedID)
+}
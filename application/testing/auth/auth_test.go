@@ -0,0 +1,308 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"hexagonal-fiber/application/security/jwt"
+	"hexagonal-fiber/application/security/keyring"
+	"hexagonal-fiber/application/services"
+	authService "hexagonal-fiber/application/usecases/auth"
+	userRepository "hexagonal-fiber/infrastructure/repository/postgres/user"
+	redisRepo "hexagonal-fiber/infrastructure/repository/redis"
+	limiterRepository "hexagonal-fiber/infrastructure/repository/redis/limiter"
+	lockoutRepository "hexagonal-fiber/infrastructure/repository/redis/lockout"
+	sessionRepository "hexagonal-fiber/infrastructure/repository/redis/session"
+	tokenRepository "hexagonal-fiber/infrastructure/repository/redis/token"
+
+	apiKeyDomain "hexagonal-fiber/domain/apikey"
+	auditDomain "hexagonal-fiber/domain/audit"
+	secureDomain "hexagonal-fiber/domain/security"
+	userDomain "hexagonal-fiber/domain/user"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type memoryRecorder struct {
+	events []auditDomain.Event
+}
+
+func (m *memoryRecorder) Record(event auditDomain.Event) error {
+	m.events = append(m.events, event)
+	return nil
+}
+
+// AuthTestSuite runs the auth use case on an in memory redis. Postgres is left out, its queries are only built
+// so every email is unknown to it, which is all the rules under test need
+type AuthTestSuite struct {
+	suite.Suite
+	redis   *miniredis.Miniredis
+	service authService.Service
+}
+
+func TestAuthTestSuite(t *testing.T) {
+	suite.Run(t, &AuthTestSuite{})
+}
+
+func (as *AuthTestSuite) SetupSuite() {
+	// the auth usecase reads config.json from the working directory
+	as.NoError(os.Chdir("../../.."))
+
+	// the mfa tokens are signed with the asymmetric key of the configuration
+	path := filepath.Join(as.T().TempDir(), "keyring.json")
+	as.Require().NoError(keyring.WriteManifest(path, &secureDomain.KeyManifest{}))
+
+	_, err := keyring.GenerateKey(path, secureDomain.AlgorithmRS256)
+	as.Require().NoError(err)
+
+	_, err = keyring.RotateKey(path, secureDomain.AlgorithmRS256)
+	as.Require().NoError(err)
+
+	as.Require().NoError(keyring.Load(path))
+}
+
+func (as *AuthTestSuite) SetupTest() {
+	as.redis = miniredis.RunT(as.T())
+
+	infoRedis := &redisRepo.InfoDatabaseRedis{CTX: context.Background()}
+	infoRedis.Write.Hostname, infoRedis.Write.Port = as.redis.Host(), as.redis.Port()
+
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	as.Require().NoError(err)
+
+	uRepository := userRepository.Repository{DB: db}
+	tRepository := tokenRepository.Repository{InfoRedis: infoRedis}
+
+	as.service = authService.Service{
+		UserRepository:    uRepository,
+		TokenRepository:   tRepository,
+		SessionRepository: sessionRepository.Repository{InfoRedis: infoRedis},
+		LimiterRepository: limiterRepository.Repository{InfoRedis: infoRedis},
+		LockoutRepository: lockoutRepository.Repository{InfoRedis: infoRedis},
+		TokenVersions:     services.TokenVersions{UserRepository: uRepository, TokenRepository: tRepository},
+	}
+}
+
+func (as *AuthTestSuite) login(email string, ip string) error {
+	_, _, err := as.service.LoginJWT(userDomain.LoginRequest{Email: email, Password: "password"}, secureDomain.ClientInfo{IP: ip})
+	return err
+}
+
+func (as *AuthTestSuite) assertStatus(code int, err error, msgAndArgs ...interface{}) {
+	as.Require().Error(err, msgAndArgs...)
+
+	fiberErr, ok := err.(*fiber.Error)
+	as.Require().True(ok, err.Error())
+	as.Equal(code, fiberErr.Code, msgAndArgs...)
+}
+
+// TestResendLimitPerMailbox checks the casing or the spaces typed do not give a mailbox a new limit
+func (as *AuthTestSuite) TestResendLimitPerMailbox() {
+	for _, email := range []string{"user@mail.com", "User@Mail.com", " USER@MAIL.COM"} {
+		as.NoError(as.service.ResendVerification(email))
+	}
+
+	as.assertStatus(fiber.StatusTooManyRequests, as.service.ResendVerification("user@MAIL.com "))
+	as.NoError(as.service.ResendVerification("someone@mail.com"), "another mailbox has its own limit")
+}
+
+// TestResendWindowStartsOnce checks the window of a resend counter left without expiration starts on the next resend
+func (as *AuthTestSuite) TestResendWindowStartsOnce() {
+	as.Require().NoError(as.redis.Set("verify:resend:user@mail.com", "1"))
+
+	as.NoError(as.service.ResendVerification("user@mail.com"))
+	as.Equal(15*time.Minute, as.redis.TTL("verify:resend:user@mail.com"))
+}
+
+// TestForgotLimitPerMailbox checks the casing or the spaces typed do not give a mailbox more reset mails
+func (as *AuthTestSuite) TestForgotLimitPerMailbox() {
+	for _, email := range []string{"user@mail.com", "User@Mail.com", " USER@MAIL.COM"} {
+		as.NoError(as.service.ForgotPassword(email))
+	}
+
+	as.assertStatus(fiber.StatusTooManyRequests, as.service.ForgotPassword("user@MAIL.com "))
+	as.NoError(as.service.ForgotPassword("someone@mail.com"), "another mailbox has its own limit")
+}
+
+// TestMFATokenRevokedWithItsVersion checks a logout from everywhere between the two steps of a login stops the mfa token
+func (as *AuthTestSuite) TestMFATokenRevokedWithItsVersion() {
+	mfaToken, err := jwt.GenerateJWTToken(jwt.MFA, &secureDomain.Claims{UserID: "user", Version: 1})
+	as.Require().NoError(err)
+
+	as.Require().NoError(as.service.TokenRepository.SetVersion("user", 2))
+
+	_, err = as.service.LoginMFA(userDomain.LoginMFARequest{MFAToken: mfaToken.Token, Code: "123456"}, secureDomain.ClientInfo{})
+	as.assertStatus(fiber.StatusUnauthorized, err)
+}
+
+// TestReusedRefreshTokenRevokesFamily checks a refresh token presented again once rotated drops its whole family,
+// the token issued by the rotation included
+func (as *AuthTestSuite) TestReusedRefreshTokenRevokesFamily() {
+	expiration := time.Now().Add(time.Hour)
+	tokens := as.service.TokenRepository
+
+	as.Require().NoError(tokens.SaveFamily("session", "first", expiration))
+	as.Require().NoError(tokens.RotateFamily("session", "first", "second", expiration))
+
+	current, err := tokens.GetFamily("session")
+	as.Require().NoError(err)
+	as.Equal("second", current)
+
+	as.assertStatus(fiber.StatusUnauthorized, tokens.RotateFamily("session", "first", "third", expiration))
+
+	active, err := tokens.IsFamilyActive("session")
+	as.Require().NoError(err)
+	as.False(active)
+	as.assertStatus(fiber.StatusUnauthorized, tokens.RotateFamily("session", "second", "third", expiration))
+}
+
+// TestLogoutRevokesFamily checks a logout drops the token family of its session only
+func (as *AuthTestSuite) TestLogoutRevokesFamily() {
+	expiration := time.Now().Add(time.Hour)
+	for _, sessionID := range []string{"session", "other"} {
+		as.Require().NoError(as.service.TokenRepository.SaveFamily(sessionID, "token", expiration))
+		as.Require().NoError(as.service.SessionRepository.Save(&secureDomain.Session{ID: sessionID, UserID: "user", ExpiresAt: expiration}))
+	}
+
+	as.Require().NoError(as.service.Logout(&secureDomain.Claims{UserID: "user", SessionID: "session"}))
+
+	active, err := as.service.TokenRepository.IsFamilyActive("session")
+	as.Require().NoError(err)
+	as.False(active)
+
+	active, err = as.service.TokenRepository.IsFamilyActive("other")
+	as.Require().NoError(err)
+	as.True(active, "the other sessions of the user stay open")
+}
+
+// TestSessionsListedPerUser checks each user only lists its own sessions, the one of the request flagged as current
+func (as *AuthTestSuite) TestSessionsListedPerUser() {
+	expiration := time.Now().Add(time.Hour)
+	as.Require().NoError(as.service.SessionRepository.Save(&secureDomain.Session{ID: "phone", UserID: "user", ExpiresAt: expiration}))
+	as.Require().NoError(as.service.SessionRepository.Save(&secureDomain.Session{ID: "laptop", UserID: "user", ExpiresAt: expiration}))
+	as.Require().NoError(as.service.SessionRepository.Save(&secureDomain.Session{ID: "neighbour", UserID: "other", ExpiresAt: expiration}))
+
+	sessions, err := as.service.GetSessions(&secureDomain.Claims{UserID: "user", SessionID: "phone"})
+	as.Require().NoError(err)
+
+	current := map[string]bool{}
+	for _, session := range *sessions {
+		current[session.ID] = session.Current
+	}
+	as.Equal(map[string]bool{"phone": true, "laptop": false}, current)
+}
+
+// TestRevokeSession checks a user revokes its own sessions only and a revoked session cannot refresh anymore
+func (as *AuthTestSuite) TestRevokeSession() {
+	expiration := time.Now().Add(time.Hour)
+	for sessionID, userID := range map[string]string{"phone": "user", "neighbour": "other"} {
+		as.Require().NoError(as.service.TokenRepository.SaveFamily(sessionID, "token", expiration))
+		as.Require().NoError(as.service.SessionRepository.Save(&secureDomain.Session{ID: sessionID, UserID: userID, ExpiresAt: expiration}))
+	}
+
+	as.assertStatus(fiber.StatusNotFound, as.service.RevokeSession("user", "neighbour"))
+	_, err := as.service.SessionRepository.GetByID("neighbour")
+	as.NoError(err, "the session of another user is left open")
+
+	as.Require().NoError(as.service.RevokeSession("user", "phone"))
+	sessions, err := as.service.GetSessions(&secureDomain.Claims{UserID: "user"})
+	as.Require().NoError(err)
+	as.Empty(*sessions)
+
+	as.assertStatus(fiber.StatusUnauthorized, as.service.TokenRepository.RotateFamily("phone", "token", "next", expiration))
+}
+
+// TestAccountLockedAtThreshold checks an account is locked out on its fifth failure, whatever the casing typed,
+// with a lockout doubling on every failure past the threshold until an admin unlocks it
+func (as *AuthTestSuite) TestAccountLockedAtThreshold() {
+	for i := 0; i < 5; i++ {
+		as.assertStatus(fiber.StatusUnauthorized, as.login("User@Mail.com", "10.0.0.1"))
+	}
+
+	as.assertStatus(fiber.StatusTooManyRequests, as.login("user@mail.com", "10.0.0.2"))
+	as.Equal(30*time.Second, as.redis.TTL("login:lock:account:user@mail.com"))
+	as.assertStatus(fiber.StatusUnauthorized, as.login("someone@mail.com", "10.0.0.1"), "the other accounts of the IP are not locked")
+
+	as.redis.FastForward(30 * time.Second)
+	as.assertStatus(fiber.StatusUnauthorized, as.login("user@mail.com", "10.0.0.1"))
+	as.Equal(time.Minute, as.redis.TTL("login:lock:account:user@mail.com"), "the next failure doubles the lockout")
+
+	as.Require().NoError(as.service.Unlock(userDomain.UnlockRequest{Email: "USER@mail.com"}, "admin"))
+	as.assertStatus(fiber.StatusUnauthorized, as.login("user@mail.com", "10.0.0.1"))
+	as.False(as.redis.Exists("login:lock:account:user@mail.com"), "the failures are counted from zero again")
+}
+
+// TestIPLockedAtThreshold checks an IP probing many accounts is locked out on its twentieth failure
+func (as *AuthTestSuite) TestIPLockedAtThreshold() {
+	for i := 0; i < 20; i++ {
+		as.assertStatus(fiber.StatusUnauthorized, as.login(fmt.Sprintf("user%d@mail.com", i), "10.0.0.1"))
+	}
+
+	as.assertStatus(fiber.StatusTooManyRequests, as.login("someone@mail.com", "10.0.0.1"))
+	as.assertStatus(fiber.StatusUnauthorized, as.login("someone@mail.com", "10.0.0.2"), "the other IPs are not locked")
+}
+
+// TestImpersonationNeedsOwnAdminSession checks an impersonation cannot be started from another impersonation,
+// from an api key or on the actor itself
+func (as *AuthTestSuite) TestImpersonationNeedsOwnAdminSession() {
+	impersonating := &secureDomain.Claims{UserID: "user", ActorID: "admin"}
+	_, err := as.service.Impersonate(impersonating, "other")
+	as.assertStatus(fiber.StatusForbidden, err)
+
+	apiKey := &secureDomain.Claims{UserID: "admin", Type: apiKeyDomain.ClaimsType}
+	_, err = as.service.Impersonate(apiKey, "user")
+	as.assertStatus(fiber.StatusForbidden, err)
+
+	_, err = as.service.Impersonate(&secureDomain.Claims{UserID: "admin"}, "admin")
+	as.assertStatus(fiber.StatusBadRequest, err)
+}
+
+// TestStopImpersonation checks stopping revokes the impersonation token and is recorded under the admin
+func (as *AuthTestSuite) TestStopImpersonation() {
+	recorder := &memoryRecorder{}
+	as.service.Audit = recorder
+
+	as.assertStatus(fiber.StatusBadRequest, as.service.StopImpersonation(&secureDomain.Claims{UserID: "admin", SessionID: "session"}))
+
+	as.Require().NoError(as.service.TokenRepository.SaveFamily("impersonation", "token", time.Now().Add(time.Hour)))
+	as.Require().NoError(as.service.StopImpersonation(&secureDomain.Claims{UserID: "user", ActorID: "admin", SessionID: "impersonation"}))
+
+	active, err := as.service.TokenRepository.IsFamilyActive("impersonation")
+	as.Require().NoError(err)
+	as.False(active)
+
+	as.Require().Len(recorder.events, 1)
+	as.Equal(auditDomain.ActionImpersonationStop, recorder.events[0].Action)
+	as.Equal("admin", recorder.events[0].ActorID)
+	as.Equal("user", recorder.events[0].ImpersonatedID)
+}
+
+// TestTokenVersionCachedOnRead checks the version read from postgres is cached for the next requests
+func (as *AuthTestSuite) TestTokenVersionCachedOnRead() {
+	version, err := as.service.TokenVersions.Current("user")
+	as.Require().NoError(err)
+	as.Equal(0, version)
+
+	cached, err := as.redis.Get("token:version:user")
+	as.Require().NoError(err)
+	as.Equal("0", cached)
+}
+
+// TestBumpedTokenVersionWins checks a version read from postgres before a bump does not replace the bumped one in
+// the cache, the tokens invalidated by the bump would be accepted again
+func (as *AuthTestSuite) TestBumpedTokenVersionWins() {
+	as.Require().NoError(as.service.TokenRepository.SetVersion("user", 3))
+	as.Require().NoError(as.service.TokenRepository.FillVersion("user", 2))
+
+	version, err := as.service.TokenVersions.Current("user")
+	as.Require().NoError(err)
+	as.Equal(3, version)
+}
@@ -0,0 +1,315 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"math"
+	"testing"
+	"time"
+
+	"hexagonal-fiber/application/imaging"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ImagingTestSuite struct {
+	suite.Suite
+}
+
+func TestImagingTestSuite(t *testing.T) {
+	suite.Run(t, &ImagingTestSuite{})
+}
+
+func (ts *ImagingTestSuite) uniform(width int, height int, fill color.Color) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, fill)
+		}
+	}
+	return img
+}
+
+func (ts *ImagingTestSuite) TestFit() {
+	width, height := imaging.Fit(4000, 3000, 1200, 1200)
+	ts.Equal(1200, width)
+	ts.Equal(900, height)
+
+	width, height = imaging.Fit(1000, 4000, 480, 480)
+	ts.Equal(120, width)
+	ts.Equal(480, height)
+
+	width, height = imaging.Fit(300, 200, 480, 480)
+	ts.Equal(300, width, "a smaller image is never upscaled")
+	ts.Equal(200, height)
+}
+
+func (ts *ImagingTestSuite) TestThumbnailCropFillsTheBox() {
+	thumbnail := imaging.Thumbnail(ts.uniform(640, 360, color.White), 200, 200, true)
+	ts.Equal(image.Rect(0, 0, 200, 200), thumbnail.Bounds())
+
+	fitted := imaging.Thumbnail(ts.uniform(640, 360, color.White), 200, 200, false)
+	ts.Equal(image.Rect(0, 0, 200, 113), fitted.Bounds())
+}
+
+// TestThumbnailCropKeepsTheCenter crops a picture whose left and right thirds are red around a blue center
+func (ts *ImagingTestSuite) TestThumbnailCropKeepsTheCenter() {
+	img := ts.uniform(300, 100, color.NRGBA{R: 255, A: 255})
+	for y := 0; y < 100; y++ {
+		for x := 100; x < 200; x++ {
+			img.Set(x, y, color.NRGBA{B: 255, A: 255})
+		}
+	}
+
+	thumbnail := imaging.Thumbnail(img, 50, 50, true)
+	ts.Equal(color.NRGBA{B: 255, A: 255}, thumbnail.NRGBAAt(0, 0))
+	ts.Equal(color.NRGBA{B: 255, A: 255}, thumbnail.NRGBAAt(49, 49))
+}
+
+func (ts *ImagingTestSuite) TestResizeKeepsAUniformColor() {
+	fill := color.NRGBA{R: 12, G: 200, B: 97, A: 255}
+	resized := imaging.Resize(ts.uniform(333, 217, fill), 50, 31)
+
+	ts.Equal(image.Rect(0, 0, 50, 31), resized.Bounds())
+	for _, point := range []image.Point{{0, 0}, {25, 15}, {49, 30}} {
+		ts.Equal(fill, resized.NRGBAAt(point.X, point.Y))
+	}
+}
+
+// TestResizeDoesNotBleedTransparentColor shrinks an opaque white half next to a fully transparent red half,
+// the transparent pixels must not tint the white ones they are averaged with
+func (ts *ImagingTestSuite) TestResizeDoesNotBleedTransparentColor() {
+	img := ts.uniform(100, 10, color.NRGBA{R: 255, A: 0})
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 50; x++ {
+			img.Set(x, y, color.White)
+		}
+	}
+
+	resized := imaging.Resize(img, 10, 1)
+	edge := resized.NRGBAAt(4, 0)
+	ts.Equal(edge.R, edge.G, "the edge stays white")
+	ts.Equal(edge.R, edge.B)
+	ts.Greater(edge.A, uint8(0))
+	ts.Less(edge.A, uint8(255))
+
+	flattened := imaging.Flatten(resized, color.White)
+	ts.Equal(color.RGBA{R: 255, G: 255, B: 255, A: 255}, flattened.RGBAAt(9, 0))
+}
+
+type exifEntry struct {
+	tag   uint16
+	kind  uint16
+	count uint32
+	value []byte
+}
+
+func ascii(tag uint16, value string) exifEntry {
+	return exifEntry{tag: tag, kind: 2, count: uint32(len(value) + 1), value: append([]byte(value), 0)}
+}
+
+func short(tag uint16, value uint16) exifEntry {
+	return exifEntry{tag: tag, kind: 3, count: 1, value: binary.LittleEndian.AppendUint16(nil, value)}
+}
+
+func long(tag uint16, value uint32) exifEntry {
+	return exifEntry{tag: tag, kind: 4, count: 1, value: binary.LittleEndian.AppendUint32(nil, value)}
+}
+
+func rationals(tag uint16, values ...uint32) exifEntry {
+	var value []byte
+	for _, v := range values {
+		value = binary.LittleEndian.AppendUint32(value, v)
+	}
+	return exifEntry{tag: tag, kind: 5, count: uint32(len(values) / 2), value: value}
+}
+
+// buildIFD lays out a little endian IFD starting at the given offset, the values too long to fit an entry follow it
+func buildIFD(start uint32, entries ...exifEntry) []byte {
+	out := make([]byte, 2+12*len(entries)+4)
+	binary.LittleEndian.PutUint16(out, uint16(len(entries)))
+
+	var data []byte
+	for i, e := range entries {
+		raw := out[2+12*i:]
+		binary.LittleEndian.PutUint16(raw, e.tag)
+		binary.LittleEndian.PutUint16(raw[2:], e.kind)
+		binary.LittleEndian.PutUint32(raw[4:], e.count)
+		if len(e.value) <= 4 {
+			copy(raw[8:], e.value)
+		} else {
+			binary.LittleEndian.PutUint32(raw[8:], start+uint32(len(out)+len(data)))
+			data = append(data, e.value...)
+		}
+	}
+
+	return append(out, data...)
+}
+
+func segment(marker byte, payload []byte) []byte {
+	return append([]byte{0xff, marker, byte((len(payload) + 2) >> 8), byte(len(payload) + 2)}, payload...)
+}
+
+// cameraJPEG encodes a picture a phone held sideways could have taken, along with its EXIF, XMP and a comment
+func (ts *ImagingTestSuite) cameraJPEG(src image.Image) []byte {
+	exifIFD := buildIFD(8,
+		ascii(0x9003, "2021:02:24 20:19:39"),
+		ascii(0x9011, "+07:00"),
+		ascii(0xa431, "SERIAL-0042"),
+		ascii(0xa433, "FUJIFILM"),
+		ascii(0xa434, "XF23mmF1.4 R"),
+	)
+	gpsStart := 8 + uint32(len(exifIFD))
+	gpsIFD := buildIFD(gpsStart,
+		ascii(0x0001, "S"),
+		rationals(0x0002, 6, 1, 10, 1, 3141, 100),
+		ascii(0x0003, "E"),
+		rationals(0x0004, 106, 1, 49, 1, 3775, 100),
+	)
+	mainStart := gpsStart + uint32(len(gpsIFD))
+	mainIFD := buildIFD(mainStart,
+		ascii(0x010f, "FUJIFILM"),
+		ascii(0x0110, "X-T4"),
+		short(0x0112, 6),
+		long(0x8769, 8),
+		long(0x8825, gpsStart),
+	)
+
+	tiff := append([]byte{'I', 'I', 42, 0}, binary.LittleEndian.AppendUint32(nil, mainStart)...)
+	tiff = append(append(append(tiff, exifIFD...), gpsIFD...), mainIFD...)
+
+	var encoded bytes.Buffer
+	ts.Require().NoError(jpeg.Encode(&encoded, src, &jpeg.Options{Quality: 90}))
+
+	data := []byte{0xff, 0xd8}
+	data = append(data, segment(0xe1, append([]byte("Exif\x00\x00"), tiff...))...)
+	data = append(data, segment(0xe1, []byte("http://ns.adobe.com/xap/1.0/\x00<x:xmpmeta>SERIAL-0042</x:xmpmeta>"))...)
+	data = append(data, segment(0xfe, []byte("shot at home"))...)
+	return append(data, encoded.Bytes()[2:]...)
+}
+
+func (ts *ImagingTestSuite) TestReadEXIF() {
+	exif, err := imaging.ReadEXIF(ts.cameraJPEG(ts.uniform(8, 4, color.White)))
+	ts.Require().NoError(err)
+
+	ts.Equal("FUJIFILM", exif.Make)
+	ts.Equal("X-T4", exif.Model)
+	ts.Equal("FUJIFILM XF23mmF1.4 R", exif.Lens)
+	ts.Equal(6, exif.Orientation)
+	ts.Require().NotNil(exif.TakenAt)
+	ts.True(exif.TakenAt.Equal(time.Date(2021, 2, 24, 13, 19, 39, 0, time.UTC)))
+	ts.Require().NotNil(exif.Latitude)
+	ts.InDelta(-6.175392, *exif.Latitude, 1e-6)
+	ts.InDelta(106.827153, *exif.Longitude, 1e-6)
+
+	var plain bytes.Buffer
+	ts.Require().NoError(jpeg.Encode(&plain, ts.uniform(8, 4, color.White), nil))
+	exif, err = imaging.ReadEXIF(plain.Bytes())
+	ts.NoError(err)
+	ts.Equal(&imaging.EXIF{Orientation: 1}, exif, "a jpeg without exif is upright")
+
+	_, err = imaging.ReadEXIF([]byte("GIF89a"))
+	ts.ErrorIs(err, imaging.ErrNotJPEG)
+}
+
+func (ts *ImagingTestSuite) TestReadEXIFRejectsOutOfRangeOffsets() {
+	data := []byte{0xff, 0xd8}
+	data = append(data, segment(0xe1, []byte("Exif\x00\x00II*\x00\xff\xff\xff\x00"))...)
+	data = append(data, 0xff, 0xd9)
+
+	_, err := imaging.ReadEXIF(data)
+	ts.ErrorIs(err, imaging.ErrBadEXIF)
+}
+
+func (ts *ImagingTestSuite) TestStripJPEG() {
+	src := ts.uniform(8, 4, color.NRGBA{R: 200, G: 30, B: 60, A: 255})
+	data := append(ts.cameraJPEG(src), []byte("trailing preview with SERIAL-0042")...)
+
+	stripped, err := imaging.StripJPEG(data)
+	ts.Require().NoError(err)
+
+	ts.NotContains(string(stripped), "SERIAL-0042")
+	ts.NotContains(string(stripped), "Exif")
+	ts.NotContains(string(stripped), "shot at home")
+
+	original, err := jpeg.Decode(bytes.NewReader(data))
+	ts.Require().NoError(err)
+	decoded, err := jpeg.Decode(bytes.NewReader(stripped))
+	ts.Require().NoError(err)
+	ts.Equal(original, decoded, "the coded image is left untouched")
+
+	exif, err := imaging.ReadEXIF(stripped)
+	ts.NoError(err)
+	ts.Equal(1, exif.Orientation)
+}
+
+// TestOrient turns a picture whose left half is red and right half is blue
+func (ts *ImagingTestSuite) TestOrient() {
+	red, blue := color.NRGBA{R: 255, A: 255}, color.NRGBA{B: 255, A: 255}
+	img := ts.uniform(2, 1, red)
+	img.Set(1, 0, blue)
+
+	cases := map[int][]color.NRGBA{
+		1: {red, blue},
+		2: {blue, red},
+		3: {blue, red},
+		6: {red, blue},
+		8: {blue, red},
+	}
+	for orientation, expected := range cases {
+		oriented := imaging.Orient(img, orientation).(*image.NRGBA)
+		if orientation >= 5 {
+			ts.Equal(image.Rect(0, 0, 1, 2), oriented.Bounds(), "orientation %d", orientation)
+			ts.Equal(expected, []color.NRGBA{oriented.NRGBAAt(0, 0), oriented.NRGBAAt(0, 1)}, "orientation %d", orientation)
+		} else {
+			ts.Equal(image.Rect(0, 0, 2, 1), oriented.Bounds(), "orientation %d", orientation)
+			ts.Equal(expected, []color.NRGBA{oriented.NRGBAAt(0, 0), oriented.NRGBAAt(1, 0)}, "orientation %d", orientation)
+		}
+	}
+}
+
+// gradient draws a picture whose brightness follows the given function of the position
+func (ts *ImagingTestSuite) gradient(width int, height int, brightness func(x float64, y float64) float64) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			value := uint8(255 * brightness(float64(x)/float64(width), float64(y)/float64(height)))
+			img.Set(x, y, color.NRGBA{R: value, G: value, B: value, A: 255})
+		}
+	}
+	return img
+}
+
+func (ts *ImagingTestSuite) TestDHashSurvivesResizeAndRecompression() {
+	waves := func(x float64, y float64) float64 { return 0.5 + 0.25*math.Sin(9*x) + 0.25*math.Cos(7*y+3*x) }
+	original := ts.gradient(640, 480, waves)
+
+	var recompressed bytes.Buffer
+	ts.Require().NoError(jpeg.Encode(&recompressed, imaging.Resize(original, 320, 240), &jpeg.Options{Quality: 40}))
+	decoded, err := jpeg.Decode(&recompressed)
+	ts.Require().NoError(err)
+
+	ts.LessOrEqual(imaging.Distance(imaging.DHash(original), imaging.DHash(decoded)), 4)
+
+	other := ts.gradient(640, 480, func(x float64, y float64) float64 { return 0.5 + 0.5*math.Sin(23*y-11*x) })
+	ts.Greater(imaging.Distance(imaging.DHash(original), imaging.DHash(other)), 10)
+}
+
+func (ts *ImagingTestSuite) TestClusters() {
+	hashes := []uint64{
+		0x0000000000000000,
+		0xffffffffffffffff,
+		0x0000000000000007, // 3 bits from the first
+		0x00000000000000ff, // 5 bits from the third only
+		0xf0f0f0f0f0f0f0f0,
+		0xffffffffffffff00, // 8 bits from the second
+	}
+
+	ts.Equal([][]int{{0, 2, 3}}, imaging.Clusters(hashes, 5), "a hash joins the group of any hash close to it")
+	ts.Equal([][]int{{0, 2, 3}, {1, 5}}, imaging.Clusters(hashes, 8))
+	ts.Empty(imaging.Clusters(hashes, 2))
+	ts.Equal([][]int{{0, 1}}, imaging.Clusters([]uint64{42, 42}, 0), "identical hashes are duplicates at any distance")
+}
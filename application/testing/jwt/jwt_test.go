@@ -0,0 +1,117 @@
+package jwt
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"hexagonal-fiber/application/security/jwt"
+	"hexagonal-fiber/application/security/keyring"
+
+	secureDomain "hexagonal-fiber/domain/security"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/suite"
+)
+
+var algorithms = []string{
+	secureDomain.AlgorithmHS256,
+	secureDomain.AlgorithmRS256,
+	secureDomain.AlgorithmES256,
+	secureDomain.AlgorithmEdDSA,
+}
+
+type JWTTestSuite struct {
+	suite.Suite
+}
+
+func TestJWTTestSuite(t *testing.T) {
+	suite.Run(t, &JWTTestSuite{})
+}
+
+func (js *JWTTestSuite) SetupSuite() {
+	// the jwt package reads config.json from the working directory
+	js.NoError(os.Chdir("../../.."))
+
+	path := filepath.Join(js.T().TempDir(), "keyring.json")
+	js.NoError(keyring.WriteManifest(path, &secureDomain.KeyManifest{}))
+
+	for _, algorithm := range algorithms[1:] {
+		_, err := keyring.GenerateKey(path, algorithm)
+		js.NoError(err)
+
+		_, err = keyring.RotateKey(path, algorithm)
+		js.NoError(err)
+	}
+
+	js.NoError(keyring.Load(path))
+}
+
+func (js *JWTTestSuite) TearDownTest() {
+	viper.Set("Secure.JWTAccessAlgorithm", nil)
+}
+
+func (js *JWTTestSuite) TestAlgorithms() {
+	for _, algorithm := range algorithms {
+		viper.Set("Secure.JWTAccessAlgorithm", algorithm)
+
+		token, err := jwt.GenerateJWTToken(jwt.Access, &secureDomain.Claims{UserID: "user"})
+		js.NoError(err, algorithm)
+
+		claims, err := jwt.GetClaimsAndVerifyToken(token.Token, jwt.Access)
+		js.NoError(err, algorithm)
+		js.Equal("user", claims.UserID, algorithm)
+	}
+}
+
+func (js *JWTTestSuite) TestStrictAlgorithm() {
+	for _, signed := range algorithms {
+		viper.Set("Secure.JWTAccessAlgorithm", signed)
+		token, err := jwt.GenerateJWTToken(jwt.Access, &secureDomain.Claims{UserID: "user"})
+		js.NoError(err)
+
+		for _, configured := range algorithms {
+			if configured == signed {
+				continue
+			}
+
+			viper.Set("Secure.JWTAccessAlgorithm", configured)
+			_, err = jwt.GetClaimsAndVerifyToken(token.Token, jwt.Access)
+			js.Error(err, "%s token accepted as %s", signed, configured)
+		}
+	}
+}
+
+func (js *JWTTestSuite) TestNoneAlgorithm() {
+	// {"alg":"none","typ":"JWT"}.{"user_id":"user","type":"access"}.
+	token := "eyJhbGciOiJub25lIiwidHlwIjoiSldUIn0.eyJ1c2VyX2lkIjoidXNlciIsInR5cGUiOiJhY2Nlc3MifQ."
+
+	_, err := jwt.GetClaimsAndVerifyToken(token, jwt.Access)
+	js.Error(err)
+}
+
+func (js *JWTTestSuite) TestVersionClaim() {
+	viper.Set("Secure.JWTAccessAlgorithm", secureDomain.AlgorithmRS256)
+
+	token, err := jwt.GenerateJWTToken(jwt.Access, &secureDomain.Claims{UserID: "user", Version: 3})
+	js.NoError(err)
+
+	claims, err := jwt.GetClaimsAndVerifyToken(token.Token, jwt.Access)
+	js.NoError(err)
+	js.Equal(3, claims.Version)
+}
+
+func (js *JWTTestSuite) TestImpersonationClaim() {
+	viper.Set("Secure.JWTAccessAlgorithm", secureDomain.AlgorithmRS256)
+
+	token, err := jwt.GenerateJWTTokenFor(jwt.Access, &secureDomain.Claims{UserID: "user", ActorID: "admin"}, time.Minute)
+	js.NoError(err)
+	js.WithinDuration(time.Now().Add(time.Minute), token.ExpirationTime, time.Second)
+
+	claims, err := jwt.GetClaimsAndVerifyToken(token.Token, jwt.Access)
+	js.NoError(err)
+	js.True(claims.IsImpersonated())
+	js.Equal("admin", claims.ActorID)
+	js.Equal("user", claims.UserID)
+}
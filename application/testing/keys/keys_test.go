@@ -0,0 +1,65 @@
+package keys
+
+import (
+	"path/filepath"
+	"testing"
+
+	"hexagonal-fiber/application/security/keyring"
+	secureDomain "hexagonal-fiber/domain/security"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type KeyRingTestSuite struct {
+	suite.Suite
+	path string
+}
+
+func TestKeyRingTestSuite(t *testing.T) {
+	suite.Run(t, &KeyRingTestSuite{})
+}
+
+func (ks *KeyRingTestSuite) SetupTest() {
+	ks.path = filepath.Join(ks.T().TempDir(), "keyring.json")
+	ks.NoError(keyring.WriteManifest(ks.path, &secureDomain.KeyManifest{}))
+}
+
+func (ks *KeyRingTestSuite) TestRotation() {
+	first, err := keyring.GenerateKey(ks.path, secureDomain.AlgorithmRS256)
+	ks.NoError(err)
+	ks.Equal(secureDomain.KeyNext, first.Status)
+
+	_, err = keyring.GenerateKey(ks.path, secureDomain.AlgorithmRS256)
+	ks.Error(err, "only one next key at a time")
+
+	_, err = keyring.RotateKey(ks.path, secureDomain.AlgorithmRS256)
+	ks.NoError(err)
+	ks.NoError(keyring.Load(ks.path))
+
+	signing, err := keyring.Keys.Signing(secureDomain.AlgorithmRS256)
+	ks.NoError(err)
+	ks.Equal(first.ID, signing.ID)
+
+	second, err := keyring.GenerateKey(ks.path, secureDomain.AlgorithmRS256)
+	ks.NoError(err)
+	ks.NoError(keyring.Load(ks.path))
+	ks.Len(keyring.Keys.JWKS().Keys, 2, "the next key is published before rotation")
+
+	_, err = keyring.RotateKey(ks.path, secureDomain.AlgorithmRS256)
+	ks.NoError(err)
+	ks.NoError(keyring.Load(ks.path))
+
+	signing, err = keyring.Keys.Signing(secureDomain.AlgorithmRS256)
+	ks.NoError(err)
+	ks.Equal(second.ID, signing.ID)
+
+	_, err = keyring.Keys.Verifying(first.ID)
+	ks.NoError(err, "the retiring key still verifies")
+
+	ks.Error(keyring.RetireKey(ks.path, second.ID), "the active key cannot be retired")
+	ks.NoError(keyring.RetireKey(ks.path, first.ID))
+	ks.NoError(keyring.Load(ks.path))
+
+	_, err = keyring.Keys.Verifying(first.ID)
+	ks.ErrorIs(err, secureDomain.ErrUnknownKey)
+}
@@ -0,0 +1,57 @@
+package lockout
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	redisRepo "hexagonal-fiber/infrastructure/repository/redis"
+	lockoutRepository "hexagonal-fiber/infrastructure/repository/redis/lockout"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/suite"
+)
+
+type LockoutTestSuite struct {
+	suite.Suite
+	redis      *miniredis.Miniredis
+	repository lockoutRepository.Repository
+}
+
+func TestLockoutTestSuite(t *testing.T) {
+	suite.Run(t, &LockoutTestSuite{})
+}
+
+func (ls *LockoutTestSuite) SetupTest() {
+	ls.redis = miniredis.RunT(ls.T())
+
+	infoRedis := &redisRepo.InfoDatabaseRedis{CTX: context.Background()}
+	infoRedis.Write.Hostname, infoRedis.Write.Port = ls.redis.Host(), ls.redis.Port()
+	ls.repository = lockoutRepository.Repository{InfoRedis: infoRedis}
+}
+
+func (ls *LockoutTestSuite) TestFailuresExpireWithTheirWindow() {
+	for i := int64(1); i <= 3; i++ {
+		count, err := ls.repository.Fail(lockoutRepository.Account, "user@mail.com", time.Minute)
+		ls.Require().NoError(err)
+		ls.Equal(i, count)
+	}
+
+	ls.Equal(time.Minute, ls.redis.TTL("login:fail:account:user@mail.com"), "the window starts on the first failure")
+
+	ls.redis.FastForward(time.Minute)
+	count, err := ls.repository.Fail(lockoutRepository.Account, "user@mail.com", time.Minute)
+	ls.Require().NoError(err)
+	ls.Equal(int64(1), count, "a new window starts once the previous one is over")
+}
+
+// TestCounterWithoutExpirationHeals checks a counter left without expiration, by a failure between counting and
+// expiring it, gets its window on the next failure instead of locking the account out for good
+func (ls *LockoutTestSuite) TestCounterWithoutExpirationHeals() {
+	ls.Require().NoError(ls.redis.Set("login:fail:account:user@mail.com", "7"))
+
+	count, err := ls.repository.Fail(lockoutRepository.Account, "user@mail.com", time.Minute)
+	ls.Require().NoError(err)
+	ls.Equal(int64(8), count)
+	ls.Equal(time.Minute, ls.redis.TTL("login:fail:account:user@mail.com"))
+}
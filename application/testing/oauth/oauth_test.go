@@ -0,0 +1,115 @@
+package oauth
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	oauthService "hexagonal-fiber/application/usecases/oauth"
+	oauthController "hexagonal-fiber/infrastructure/restapi/controllers/oauth"
+	"hexagonal-fiber/infrastructure/restapi/routes"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/suite"
+)
+
+// the client configured in config.json
+const (
+	clientID     = "internal-service"
+	clientSecret = "introspectionsecretyoumayneedtochangeit"
+)
+
+type OAuthTestSuite struct {
+	suite.Suite
+	app *fiber.App
+}
+
+func TestOAuthTestSuite(t *testing.T) {
+	suite.Run(t, &OAuthTestSuite{})
+}
+
+func (ts *OAuthTestSuite) SetupSuite() {
+	// the oauth use case reads config.json from the working directory
+	ts.NoError(os.Chdir("../../.."))
+
+	ts.app = fiber.New()
+	routes.OAuthRoutes(ts.app, &oauthController.Controller{OAuthService: oauthService.Service{}})
+}
+
+func (ts *OAuthTestSuite) post(path string, form url.Values, id string, secret string) (*http.Response, map[string]interface{}) {
+	request := httptest.NewRequest(fiber.MethodPost, path, strings.NewReader(form.Encode()))
+	request.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationForm)
+	if id != "" {
+		credentials := url.QueryEscape(id) + ":" + url.QueryEscape(secret)
+		request.Header.Set(fiber.HeaderAuthorization, "Basic "+base64.StdEncoding.EncodeToString([]byte(credentials)))
+	}
+
+	response, err := ts.app.Test(request)
+	ts.NoError(err)
+
+	body := map[string]interface{}{}
+	_ = json.NewDecoder(response.Body).Decode(&body)
+	return response, body
+}
+
+func (ts *OAuthTestSuite) TestAuthenticateClient() {
+	service := oauthService.Service{}
+
+	ts.NoError(service.AuthenticateClient(clientID, clientSecret))
+	ts.Error(service.AuthenticateClient(clientID, "wrong"))
+	ts.Error(service.AuthenticateClient("unknown", clientSecret))
+	ts.Error(service.AuthenticateClient("", ""))
+}
+
+func (ts *OAuthTestSuite) TestIntrospectRequiresClient() {
+	response, body := ts.post("/oauth/introspect", url.Values{"token": {"some.token"}}, clientID, "wrong")
+
+	ts.Equal(fiber.StatusUnauthorized, response.StatusCode)
+	ts.Equal("invalid_client", body["error"])
+	ts.NotEmpty(response.Header.Get(fiber.HeaderWWWAuthenticate))
+
+	response, _ = ts.post("/oauth/introspect", url.Values{"token": {"some.token"}}, "", "")
+	ts.Equal(fiber.StatusUnauthorized, response.StatusCode)
+}
+
+func (ts *OAuthTestSuite) TestIntrospectInvalidTokenIsInactive() {
+	response, body := ts.post("/oauth/introspect", url.Values{"token": {"not.a.token"}}, clientID, clientSecret)
+
+	ts.Equal(fiber.StatusOK, response.StatusCode)
+	ts.Equal(map[string]interface{}{"active": false}, body)
+	ts.Equal("no-store", response.Header.Get(fiber.HeaderCacheControl))
+}
+
+func (ts *OAuthTestSuite) TestClientCredentialsInBody() {
+	form := url.Values{"token": {"not.a.token"}, "client_id": {clientID}, "client_secret": {clientSecret}}
+	response, body := ts.post("/oauth/introspect", form, "", "")
+
+	ts.Equal(fiber.StatusOK, response.StatusCode)
+	ts.Equal(false, body["active"])
+}
+
+func (ts *OAuthTestSuite) TestIntrospectRequiresToken() {
+	response, body := ts.post("/oauth/introspect", url.Values{}, clientID, clientSecret)
+
+	ts.Equal(fiber.StatusBadRequest, response.StatusCode)
+	ts.Equal("invalid_request", body["error"])
+}
+
+func (ts *OAuthTestSuite) TestRevokeInvalidTokenSucceeds() {
+	response, _ := ts.post("/oauth/revoke", url.Values{"token": {"not.a.token"}}, clientID, clientSecret)
+
+	ts.Equal(fiber.StatusOK, response.StatusCode)
+}
+
+func (ts *OAuthTestSuite) TestRevokeUnsupportedTokenType() {
+	form := url.Values{"token": {"not.a.token"}, "token_type_hint": {"id_token"}}
+	response, body := ts.post("/oauth/revoke", form, clientID, clientSecret)
+
+	ts.Equal(fiber.StatusBadRequest, response.StatusCode)
+	ts.Equal("unsupported_token_type", body["error"])
+}
@@ -0,0 +1,253 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"hexagonal-fiber/application/security/oidc"
+	secureDomain "hexagonal-fiber/domain/security"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	clientID     = "hexagonal-fiber"
+	clientSecret = "stubsecret"
+	goodCode     = "good-code"
+)
+
+// stubProvider is a local identity provider issuing the ID token of its claims for the good code
+type stubProvider struct {
+	server    *httptest.Server
+	key       *rsa.PrivateKey
+	challenge string
+	claims    jwt.MapClaims
+	method    jwt.SigningMethod
+}
+
+func newStubProvider() *stubProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+
+	stub := &stubProvider{key: key, method: jwt.SigningMethodRS256}
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(secureDomain.OIDCDiscovery{
+			Issuer:                stub.server.URL,
+			AuthorizationEndpoint: stub.server.URL + "/authorize",
+			TokenEndpoint:         stub.server.URL + "/token",
+			JWKSURI:               stub.server.URL + "/jwks",
+		})
+	})
+
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(secureDomain.JWKS{Keys: []secureDomain.JWK{{
+			KeyType:   "RSA",
+			KeyID:     "stub",
+			Use:       "sig",
+			Algorithm: secureDomain.AlgorithmRS256,
+			N:         base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		id, secret, ok := r.BasicAuth()
+		if !ok || id != clientID || secret != clientSecret {
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+			return
+		}
+
+		if r.PostFormValue("code") != goodCode || oidc.CodeChallenge(r.PostFormValue("code_verifier")) != stub.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]string{"id_token": stub.idToken(), "token_type": "Bearer"})
+	})
+
+	stub.server = httptest.NewServer(mux)
+	return stub
+}
+
+func (s *stubProvider) idToken() string {
+	token := jwt.NewWithClaims(s.method, s.claims)
+	token.Header["kid"] = "stub"
+
+	var key interface{} = s.key
+	if s.method == jwt.SigningMethodHS256 {
+		key = []byte(clientSecret)
+	}
+
+	signed, err := token.SignedString(key)
+	if err != nil {
+		panic(err)
+	}
+
+	return signed
+}
+
+type OIDCTestSuite struct {
+	suite.Suite
+	stub     *stubProvider
+	client   *oidc.Client
+	provider *secureDomain.OIDCProvider
+	verifier string
+	nonce    string
+}
+
+func TestOIDCTestSuite(t *testing.T) {
+	suite.Run(t, &OIDCTestSuite{})
+}
+
+func (ts *OIDCTestSuite) SetupTest() {
+	ts.stub = newStubProvider()
+	ts.client = oidc.NewClient()
+	ts.provider = &secureDomain.OIDCProvider{
+		Name:         "stub",
+		Issuer:       ts.stub.server.URL,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  "http://localhost:4000/v1/auth/oidc/stub/callback",
+	}
+
+	ts.verifier = "a-verifier-long-enough-to-satisfy-rfc-7636-requirements"
+	ts.nonce = "a-nonce"
+	ts.stub.challenge = oidc.CodeChallenge(ts.verifier)
+	ts.stub.claims = jwt.MapClaims{
+		"iss":            ts.stub.server.URL,
+		"sub":            "110169484474386276334",
+		"aud":            clientID,
+		"exp":            time.Now().Add(time.Hour).Unix(),
+		"iat":            time.Now().Unix(),
+		"nonce":          ts.nonce,
+		"email":          "User@Mail.com",
+		"email_verified": true,
+	}
+}
+
+func (ts *OIDCTestSuite) TearDownTest() {
+	ts.stub.server.Close()
+}
+
+func (ts *OIDCTestSuite) TestAuthCodeURLAsksForPKCE() {
+	authURL, err := ts.client.AuthCodeURL(ts.provider, "a-state", ts.nonce, ts.verifier)
+	ts.Require().NoError(err)
+
+	parsed, err := url.Parse(authURL)
+	ts.Require().NoError(err)
+	query := parsed.Query()
+
+	ts.Equal(ts.stub.server.URL+"/authorize", parsed.Scheme+"://"+parsed.Host+parsed.Path)
+	ts.Equal("code", query.Get("response_type"))
+	ts.Equal(clientID, query.Get("client_id"))
+	ts.Equal("a-state", query.Get("state"))
+	ts.Equal(ts.nonce, query.Get("nonce"))
+	ts.Equal("S256", query.Get("code_challenge_method"))
+	ts.Equal(oidc.CodeChallenge(ts.verifier), query.Get("code_challenge"))
+	ts.Equal("openid email profile", query.Get("scope"))
+}
+
+func (ts *OIDCTestSuite) TestCodeChallengeMatchesRFC() {
+	// RFC 7636 appendix B
+	ts.Equal("E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM", oidc.CodeChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"))
+}
+
+func (ts *OIDCTestSuite) TestAuthenticate() {
+	identity, err := ts.client.Authenticate(ts.provider, goodCode, ts.verifier, ts.nonce)
+	ts.Require().NoError(err)
+
+	ts.Equal("stub", identity.Provider)
+	ts.Equal("110169484474386276334", identity.Subject)
+	ts.Equal("user@mail.com", identity.Email)
+	ts.True(identity.EmailVerified)
+}
+
+func (ts *OIDCTestSuite) TestWrongVerifierIsRefused() {
+	_, err := ts.client.Authenticate(ts.provider, goodCode, "another-verifier", ts.nonce)
+
+	fiberErr, ok := err.(*fiber.Error)
+	ts.Require().True(ok)
+	ts.Equal(fiber.StatusUnauthorized, fiberErr.Code)
+}
+
+func (ts *OIDCTestSuite) TestNonceMismatchIsRefused() {
+	_, err := ts.client.Authenticate(ts.provider, goodCode, ts.verifier, "another-nonce")
+
+	ts.Equal(oidc.ErrInvalidIDToken, err)
+}
+
+func (ts *OIDCTestSuite) TestOtherAudienceIsRefused() {
+	ts.stub.claims["aud"] = "another-client"
+
+	_, err := ts.client.Authenticate(ts.provider, goodCode, ts.verifier, ts.nonce)
+
+	ts.Equal(oidc.ErrInvalidIDToken, err)
+}
+
+func (ts *OIDCTestSuite) TestAudienceArrayNeedsAuthorizedParty() {
+	ts.stub.claims["aud"] = []string{"another-client", clientID}
+
+	_, err := ts.client.Authenticate(ts.provider, goodCode, ts.verifier, ts.nonce)
+	ts.Equal(oidc.ErrInvalidIDToken, err)
+
+	ts.stub.claims["azp"] = clientID
+	_, err = ts.client.Authenticate(ts.provider, goodCode, ts.verifier, ts.nonce)
+	ts.NoError(err)
+}
+
+func (ts *OIDCTestSuite) TestOtherIssuerIsRefused() {
+	ts.stub.claims["iss"] = "https://evil.example.com"
+
+	_, err := ts.client.Authenticate(ts.provider, goodCode, ts.verifier, ts.nonce)
+
+	ts.Equal(oidc.ErrInvalidIDToken, err)
+}
+
+func (ts *OIDCTestSuite) TestExpiredTokenIsRefused() {
+	ts.stub.claims["exp"] = time.Now().Add(-time.Hour).Unix()
+
+	_, err := ts.client.Authenticate(ts.provider, goodCode, ts.verifier, ts.nonce)
+
+	ts.Equal(oidc.ErrInvalidIDToken, err)
+}
+
+func (ts *OIDCTestSuite) TestSharedSecretSignatureIsRefused() {
+	ts.stub.method = jwt.SigningMethodHS256
+
+	_, err := ts.client.Authenticate(ts.provider, goodCode, ts.verifier, ts.nonce)
+
+	ts.Equal(oidc.ErrInvalidIDToken, err)
+}
+
+func (ts *OIDCTestSuite) TestDiscoveryMustNameItsIssuer() {
+	ts.provider.Issuer = ts.stub.server.URL + "/"
+
+	_, err := ts.client.Discover(ts.provider.Issuer)
+
+	ts.Error(err)
+}
+
+func (ts *OIDCTestSuite) TestStringEmailVerified() {
+	ts.stub.claims["email_verified"] = "false"
+
+	identity, err := ts.client.Authenticate(ts.provider, goodCode, ts.verifier, ts.nonce)
+	ts.Require().NoError(err)
+
+	ts.False(identity.EmailVerified)
+}
@@ -0,0 +1,97 @@
+package password
+
+import (
+	"strings"
+	"testing"
+
+	"hexagonal-fiber/application/security/password"
+
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// cheap parameters keep the suite fast, the format does not depend on them
+func testHasher() *password.Argon2id {
+	return &password.Argon2id{MemoryKiB: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+}
+
+type PasswordTestSuite struct {
+	suite.Suite
+}
+
+func TestPasswordTestSuite(t *testing.T) {
+	suite.Run(t, &PasswordTestSuite{})
+}
+
+func (ps *PasswordTestSuite) TestHashIsPHCString() {
+	hash, err := testHasher().Hash("Pass@Word123")
+	ps.NoError(err)
+
+	ps.True(strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"))
+	ps.Len(strings.Split(hash, "$"), 6)
+
+	other, err := testHasher().Hash("Pass@Word123")
+	ps.NoError(err)
+	ps.NotEqual(hash, other, "every hash has its own salt")
+}
+
+func (ps *PasswordTestSuite) TestVerify() {
+	hasher := testHasher()
+	hash, err := hasher.Hash("Pass@Word123")
+	ps.NoError(err)
+
+	match, rehash, err := hasher.Verify("Pass@Word123", hash)
+	ps.NoError(err)
+	ps.True(match)
+	ps.False(rehash)
+
+	match, _, err = hasher.Verify("Pass@Word124", hash)
+	ps.NoError(err)
+	ps.False(match)
+}
+
+// a hash made by the reference implementation, password "password" and salt "somesalt"
+func (ps *PasswordTestSuite) TestVerifyReferenceHash() {
+	hash := "$argon2id$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc"
+
+	match, rehash, err := testHasher().Verify("password", hash)
+	ps.NoError(err)
+	ps.True(match)
+	ps.True(rehash, "parameters differ from the current ones")
+}
+
+func (ps *PasswordTestSuite) TestLegacyBcryptAsksForRehash() {
+	legacy, err := bcrypt.GenerateFromPassword([]byte("Pass@Word123"), bcrypt.MinCost)
+	ps.NoError(err)
+
+	match, rehash, err := testHasher().Verify("Pass@Word123", string(legacy))
+	ps.NoError(err)
+	ps.True(match)
+	ps.True(rehash)
+
+	match, rehash, err = testHasher().Verify("wrong", string(legacy))
+	ps.NoError(err)
+	ps.False(match)
+	ps.False(rehash)
+}
+
+func (ps *PasswordTestSuite) TestChangedParametersAskForRehash() {
+	hash, err := testHasher().Hash("Pass@Word123")
+	ps.NoError(err)
+
+	stronger := testHasher()
+	stronger.Iterations = 2
+
+	match, rehash, err := stronger.Verify("Pass@Word123", hash)
+	ps.NoError(err)
+	ps.True(match)
+	ps.True(rehash)
+}
+
+func (ps *PasswordTestSuite) TestMalformedHash() {
+	for _, hash := range []string{"", "plaintext", "$argon2i$v=19$m=1024,t=1,p=1$c29tZXNhbHQ$aGFzaA", "$argon2id$v=16$m=1024,t=1,p=1$c29tZXNhbHQ$aGFzaA", "$argon2id$v=19$m=0,t=1,p=1$c29tZXNhbHQ$aGFzaA"} {
+		match, _, err := testHasher().Verify("password", hash)
+		ps.ErrorIs(err, password.ErrMalformedHash, hash)
+		ps.False(match)
+	}
+}
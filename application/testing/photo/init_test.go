@@ -60,7 +60,7 @@ func (its *IntTestSuite) TearDownTest() {
 }
 
 func (its *IntTestSuite) TestGetByID() {
-	actual, err := its.photoCase.GetByID("1")
+	actual, err := its.photoCase.GetByID(nil, "1")
 
 	its.Nil(err)
 	its.Equal(uint(1), actual.ID)
@@ -68,7 +68,7 @@ func (its *IntTestSuite) TestGetByID() {
 }
 
 func (its *IntTestSuite) TestGetByID_Error() {
-	actual, err := its.photoCase.GetByID("")
+	actual, err := its.photoCase.GetByID(nil, "")
 
 	its.EqualError(err, mssgConst.StatusNotFound)
 	its.Equal(uint(0), actual.ID)
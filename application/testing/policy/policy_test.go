@@ -0,0 +1,137 @@
+package policy
+
+import (
+	"testing"
+
+	"hexagonal-fiber/application/security/policy"
+	userService "hexagonal-fiber/application/usecases/user"
+	secureDomain "hexagonal-fiber/domain/security"
+	userDomain "hexagonal-fiber/domain/user"
+	"hexagonal-fiber/utils/constant/permission"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	ownerID    = "8d2b0a55-5b0e-4c1a-9a39-1f0f5f6c1a01"
+	strangerID = "0c1e7f3a-2f44-4a8e-b6b2-7d9f0e1d2c03"
+)
+
+type PolicyTestSuite struct {
+	suite.Suite
+}
+
+func TestPolicyTestSuite(t *testing.T) {
+	suite.Run(t, &PolicyTestSuite{})
+}
+
+func (ts *PolicyTestSuite) assertForbidden(err error) {
+	ts.Error(err)
+
+	fiberErr, ok := err.(*fiber.Error)
+	ts.True(ok)
+	ts.Equal(fiber.StatusForbidden, fiberErr.Code)
+}
+
+func (ts *PolicyTestSuite) TestOwnerIsAllowed() {
+	owner := &secureDomain.Claims{UserID: ownerID}
+
+	for _, action := range []string{policy.Read, policy.Update, policy.Delete} {
+		ts.NoError(policy.Photo.Authorize(owner, action, ownerID))
+		ts.NoError(policy.User.Authorize(owner, action, ownerID))
+	}
+}
+
+func (ts *PolicyTestSuite) TestStrangerIsForbidden() {
+	stranger := &secureDomain.Claims{UserID: strangerID}
+
+	ts.assertForbidden(policy.Photo.Authorize(stranger, policy.Delete, ownerID))
+	ts.assertForbidden(policy.Comment.Authorize(stranger, policy.Update, ownerID))
+	ts.assertForbidden(policy.SocialMedia.Authorize(stranger, policy.Delete, ownerID))
+	ts.assertForbidden(policy.User.Authorize(stranger, policy.Read, ownerID))
+}
+
+func (ts *PolicyTestSuite) TestPublicRead() {
+	stranger := &secureDomain.Claims{UserID: strangerID}
+
+	ts.NoError(policy.Photo.Authorize(stranger, policy.Read, ownerID))
+	ts.NoError(policy.Comment.Authorize(stranger, policy.Read, ownerID))
+	ts.NoError(policy.SocialMedia.Authorize(nil, policy.Read, ownerID))
+}
+
+func (ts *PolicyTestSuite) TestPermissionIsScopedToAction() {
+	moderator := &secureDomain.Claims{UserID: strangerID, Permissions: []string{permission.PhotoUpdateAny}}
+
+	ts.NoError(policy.Photo.Authorize(moderator, policy.Update, ownerID))
+	ts.assertForbidden(policy.Photo.Authorize(moderator, policy.Delete, ownerID))
+	ts.assertForbidden(policy.Comment.Authorize(moderator, policy.Update, ownerID))
+}
+
+func (ts *PolicyTestSuite) TestPermissionsMatchConstants() {
+	ts.Equal(permission.PhotoDeleteAny, policy.Photo.Permission(policy.Delete))
+	ts.Equal(permission.CommentDeleteAny, policy.Comment.Permission(policy.Delete))
+	ts.Equal(permission.SocialMediaUpdateAny, policy.SocialMedia.Permission(policy.Update))
+	ts.Equal(permission.UserReadAny, policy.User.Permission(policy.Read))
+	ts.Equal(permission.PhotoRestoreAny, policy.Photo.Permission(policy.Restore))
+	ts.Equal(permission.CommentRestoreAny, policy.Comment.Permission(policy.Restore))
+	ts.Equal(permission.SocialMediaRestoreAny, policy.SocialMedia.Permission(policy.Restore))
+	ts.Equal(permission.UserRestoreAny, policy.User.Permission(policy.Restore))
+}
+
+func (ts *PolicyTestSuite) TestTrashIsNotPublic() {
+	owner := &secureDomain.Claims{UserID: ownerID}
+	stranger := &secureDomain.Claims{UserID: strangerID}
+	admin := &secureDomain.Claims{UserID: strangerID, Permissions: []string{permission.PhotoRestoreAny, permission.UserRestoreAny}}
+
+	ts.NoError(policy.Photo.Authorize(owner, policy.Restore, ownerID))
+	ts.assertForbidden(policy.Photo.Authorize(stranger, policy.Restore, ownerID))
+	ts.assertForbidden(policy.Comment.Authorize(nil, policy.Restore, ownerID))
+	ts.NoError(policy.Photo.Authorize(admin, policy.Restore, ownerID))
+
+	// the trash of the users belongs to no one, only a permission lists it
+	ts.assertForbidden(policy.User.Authorize(owner, policy.Restore, ""))
+	ts.NoError(policy.User.Authorize(admin, policy.Restore, ""))
+}
+
+func (ts *PolicyTestSuite) TestMissingActorOrOwner() {
+	ts.assertForbidden(policy.Photo.Authorize(nil, policy.Delete, ownerID))
+	ts.assertForbidden(policy.User.Authorize(&secureDomain.Claims{}, policy.Update, ""))
+}
+
+func (ts *PolicyTestSuite) TestImpersonationBlocksSensitiveActions() {
+	admin := &secureDomain.Claims{UserID: ownerID, ActorID: strangerID, Permissions: []string{permission.UserDeleteAny}}
+
+	ts.assertForbidden(policy.NotImpersonating(admin, "delete an account"))
+	ts.NoError(policy.NotImpersonating(&secureDomain.Claims{UserID: ownerID}, "delete an account"))
+	ts.NoError(policy.NotImpersonating(nil, "delete an account"))
+}
+
+// TestImpersonationCannotTakeTheAccountOver checks an impersonating admin can change neither the password nor the email,
+// the email receives the password resets
+func (ts *PolicyTestSuite) TestImpersonationCannotTakeTheAccountOver() {
+	admin := &secureDomain.Claims{UserID: ownerID, ActorID: strangerID, Permissions: []string{permission.UserUpdateAny}}
+	service := userService.Service{}
+
+	password, email := "N3w-Passw0rd!", "taken@over.com"
+	_, err := service.Update(admin, ownerID, userDomain.UpdateUser{Password: &password})
+	ts.assertForbidden(err)
+
+	_, err = service.Update(admin, ownerID, userDomain.UpdateUser{Email: &email})
+	ts.assertForbidden(err)
+}
+
+// TestAlbumIsNotPublic checks a private album, the use case lets the reads of a public one through before the policy
+func (ts *PolicyTestSuite) TestAlbumIsNotPublic() {
+	stranger := &secureDomain.Claims{UserID: strangerID}
+	moderator := &secureDomain.Claims{UserID: strangerID, Permissions: []string{permission.AlbumReadAny}}
+
+	ts.NoError(policy.Album.Authorize(&secureDomain.Claims{UserID: ownerID}, policy.Update, ownerID))
+	ts.assertForbidden(policy.Album.Authorize(stranger, policy.Read, ownerID))
+	ts.assertForbidden(policy.Album.Authorize(nil, policy.Read, ownerID))
+	ts.NoError(policy.Album.Authorize(moderator, policy.Read, ownerID))
+	ts.assertForbidden(policy.Album.Authorize(moderator, policy.Update, ownerID))
+
+	ts.Equal(permission.AlbumUpdateAny, policy.Album.Permission(policy.Update))
+	ts.Equal(permission.AlbumDeleteAny, policy.Album.Permission(policy.Delete))
+}
@@ -0,0 +1,115 @@
+package privacy
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	privacyService "hexagonal-fiber/application/usecases/privacy"
+	apiKeyDomain "hexagonal-fiber/domain/apikey"
+	photoDomain "hexagonal-fiber/domain/photo"
+	privacyDomain "hexagonal-fiber/domain/privacy"
+	secureDomain "hexagonal-fiber/domain/security"
+	userDomain "hexagonal-fiber/domain/user"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/suite"
+)
+
+type PrivacyTestSuite struct {
+	suite.Suite
+}
+
+func TestPrivacyTestSuite(t *testing.T) {
+	suite.Run(t, &PrivacyTestSuite{})
+}
+
+func (ts *PrivacyTestSuite) readZip(archive *privacyDomain.Archive) map[string][]byte {
+	var buffer bytes.Buffer
+	ts.Require().NoError(archive.WriteZip(&buffer))
+
+	reader, err := zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+	ts.Require().NoError(err)
+
+	files := map[string][]byte{}
+	for _, file := range reader.File {
+		content, err := file.Open()
+		ts.Require().NoError(err)
+
+		files[file.Name], err = io.ReadAll(content)
+		ts.Require().NoError(err)
+		content.Close()
+	}
+
+	return files
+}
+
+func (ts *PrivacyTestSuite) TestArchiveHoldsOneFilePerKind() {
+	user := &userDomain.User{UserName: "someUser", Email: "user@mail.com", HashPassword: "$argon2id$secret", TOTPSecret: "TOTP"}
+	archive := &privacyDomain.Archive{
+		Profile:    user.DomainToResponseMapper(),
+		Photos:     []photoDomain.Photo{{Title: "holiday"}},
+		APIKeys:    []apiKeyDomain.APIKey{{Name: "export", SecretHash: "secret-hash"}},
+		ExportedAt: time.Now(),
+	}
+
+	files := ts.readZip(archive)
+
+	ts.Len(files, 7)
+	for _, name := range []string{"profile.json", "photos.json", "comments.json", "social_media.json", "sessions.json", "identities.json", "api_keys.json"} {
+		ts.Contains(files, name)
+		ts.True(json.Valid(files[name]), name)
+	}
+
+	ts.Contains(string(files["profile.json"]), "user@mail.com")
+	ts.Contains(string(files["photos.json"]), "holiday")
+}
+
+func (ts *PrivacyTestSuite) TestArchiveLeaksNoCredentials() {
+	user := &userDomain.User{Email: "user@mail.com", HashPassword: "$argon2id$secret", TOTPSecret: "TOTPSECRET"}
+	archive := &privacyDomain.Archive{
+		Profile: user.DomainToResponseMapper(),
+		APIKeys: []apiKeyDomain.APIKey{{Name: "export", SecretHash: "secret-hash"}},
+	}
+
+	for name, content := range ts.readZip(archive) {
+		ts.NotContains(string(content), "$argon2id$secret", name)
+		ts.NotContains(string(content), "TOTPSECRET", name)
+		ts.NotContains(string(content), "secret-hash", name)
+	}
+}
+
+func (ts *PrivacyTestSuite) TestRequestsStayWithTheUser() {
+	service := privacyService.Service{}
+	impersonating := &secureDomain.Claims{UserID: "user", ActorID: "admin"}
+	apiKey := &secureDomain.Claims{UserID: "user", Type: apiKeyDomain.ClaimsType}
+
+	for _, actor := range []*secureDomain.Claims{impersonating, apiKey} {
+		_, err := service.RequestExport(actor)
+		ts.Equal(fiber.StatusForbidden, err.(*fiber.Error).Code)
+
+		_, err = service.ExportArchive(actor)
+		ts.Equal(fiber.StatusForbidden, err.(*fiber.Error).Code)
+
+		_, err = service.RequestErasure(actor)
+		ts.Equal(fiber.StatusForbidden, err.(*fiber.Error).Code)
+
+		err = service.CancelErasure(actor)
+		ts.Equal(fiber.StatusForbidden, err.(*fiber.Error).Code)
+	}
+}
+
+// TestPendingExportStalls checks an export pending past the build timeout is taken as lost, a ready one never is
+func (ts *PrivacyTestSuite) TestPendingExportStalls() {
+	requestedAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	export := &privacyDomain.Export{Status: privacyDomain.ExportPending, RequestedAt: requestedAt}
+
+	ts.False(export.Stalled(requestedAt.Add(10*time.Minute), 30*time.Minute))
+	ts.True(export.Stalled(requestedAt.Add(31*time.Minute), 30*time.Minute))
+
+	export.Status = privacyDomain.ExportReady
+	ts.False(export.Stalled(requestedAt.Add(24*time.Hour), 30*time.Minute))
+}
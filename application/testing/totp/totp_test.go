@@ -0,0 +1,75 @@
+package totp
+
+import (
+	"testing"
+	"time"
+
+	"hexagonal-fiber/application/security/totp"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// rfcSecret is the base32 encoding of the RFC 6238 sha1 test key "12345678901234567890"
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+type TOTPTestSuite struct {
+	suite.Suite
+}
+
+func TestTOTPTestSuite(t *testing.T) {
+	suite.Run(t, &TOTPTestSuite{})
+}
+
+func (ts *TOTPTestSuite) TestCodeRFCVectors() {
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1111111111: "050471",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+
+	for unix, expected := range vectors {
+		code, err := totp.Code(rfcSecret, time.Unix(unix, 0))
+		ts.NoError(err)
+		ts.Equal(expected, code, "time %d", unix)
+	}
+}
+
+func (ts *TOTPTestSuite) TestValidateSkew() {
+	now := time.Unix(1234567890, 0)
+	code, err := totp.Code(rfcSecret, now)
+	ts.NoError(err)
+
+	ts.True(totp.Validate(rfcSecret, code, now.Add(totp.Period)))
+	ts.True(totp.Validate(rfcSecret, code, now.Add(-totp.Period)))
+	ts.False(totp.Validate(rfcSecret, code, now.Add(3*totp.Period)))
+	ts.False(totp.Validate(rfcSecret, "12345", now))
+}
+
+// TestMatchStep checks the step a code belongs to is the one it was generated at, whatever the skew it is checked with
+func (ts *TOTPTestSuite) TestMatchStep() {
+	now := time.Unix(1234567890, 0)
+	code, err := totp.Code(rfcSecret, now)
+	ts.NoError(err)
+
+	expected := now.Unix() / int64(totp.Period.Seconds())
+	for _, at := range []time.Time{now, now.Add(totp.Period), now.Add(-totp.Period)} {
+		step, ok := totp.Match(rfcSecret, code, at)
+		ts.True(ok)
+		ts.Equal(expected, step)
+	}
+
+	_, ok := totp.Match(rfcSecret, code, now.Add(2*totp.Period))
+	ts.False(ok)
+}
+
+func (ts *TOTPTestSuite) TestGenerateSecret() {
+	secret, err := totp.GenerateSecret()
+	ts.NoError(err)
+	ts.Len(secret, 32)
+
+	code, err := totp.Code(secret, time.Now())
+	ts.NoError(err)
+	ts.True(totp.Validate(secret, code, time.Now()))
+}
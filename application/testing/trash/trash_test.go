@@ -0,0 +1,172 @@
+package trash
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	trashService "hexagonal-fiber/application/usecases/trash"
+	userDomain "hexagonal-fiber/domain/user"
+	commentRepository "hexagonal-fiber/infrastructure/repository/postgres/comment"
+	photoRepository "hexagonal-fiber/infrastructure/repository/postgres/photo"
+	sosmedRepository "hexagonal-fiber/infrastructure/repository/postgres/sosmed"
+	userRepository "hexagonal-fiber/infrastructure/repository/postgres/user"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+var errDryRun = errors.New("dry run")
+
+// dryConn stands for postgres, the queries are only built so it is never reached, it only opens the transactions
+type dryConn struct{}
+
+func (dryConn) PrepareContext(context.Context, string) (*sql.Stmt, error) {
+	return nil, errDryRun
+}
+
+func (dryConn) ExecContext(context.Context, string, ...interface{}) (sql.Result, error) {
+	return nil, errDryRun
+}
+
+func (dryConn) QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error) {
+	return nil, errDryRun
+}
+
+func (dryConn) QueryRowContext(context.Context, string, ...interface{}) *sql.Row {
+	return nil
+}
+
+func (dryConn) BeginTx(context.Context, *sql.TxOptions) (gorm.ConnPool, error) {
+	return &dryTx{}, nil
+}
+
+type dryTx struct {
+	dryConn
+}
+
+func (*dryTx) Commit() error {
+	return nil
+}
+
+func (*dryTx) Rollback() error {
+	return nil
+}
+
+// sqlRecorder keeps the statements built by the repositories
+type sqlRecorder struct {
+	logger.Interface
+	statements []string
+}
+
+func (r *sqlRecorder) Trace(_ context.Context, _ time.Time, fc func() (string, int64), _ error) {
+	statement, _ := fc()
+	r.statements = append(r.statements, statement)
+}
+
+// TrashTestSuite checks the statements of the soft delete, the restore and the purge, no postgres is needed
+type TrashTestSuite struct {
+	suite.Suite
+	sql     *sqlRecorder
+	service trashService.Service
+}
+
+func TestTrashTestSuite(t *testing.T) {
+	suite.Run(t, &TrashTestSuite{})
+}
+
+func (ts *TrashTestSuite) SetupSuite() {
+	// the trash usecase reads config.json from the working directory
+	ts.NoError(os.Chdir("../../.."))
+}
+
+func (ts *TrashTestSuite) SetupTest() {
+	ts.sql = &sqlRecorder{Interface: logger.Discard}
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: dryConn{}}), &gorm.Config{
+		Logger:                 ts.sql,
+		DryRun:                 true,
+		SkipDefaultTransaction: true,
+		NamingStrategy: schema.NamingStrategy{
+			SingularTable: true,
+		},
+	})
+	ts.Require().NoError(err)
+
+	ts.service = trashService.Service{
+		UserRepository:        userRepository.Repository{DB: db},
+		PhotoRepository:       photoRepository.Repository{DB: db},
+		CommentRepository:     commentRepository.Repository{DB: db},
+		SocialMediaRepository: sosmedRepository.Repository{DB: db},
+	}
+}
+
+func (ts *TrashTestSuite) TestDeleteMovesToTrash() {
+	// nothing matches in a dry run, the not found error is expected
+	_ = ts.service.SocialMediaRepository.Delete("1")
+
+	ts.Require().Len(ts.sql.statements, 1)
+	ts.True(strings.HasPrefix(ts.sql.statements[0], `UPDATE "social_media" SET "deleted_at"=`), ts.sql.statements[0])
+	ts.Contains(ts.sql.statements[0], `"social_media"."deleted_at" IS NULL`, "a deleted row is not deleted again")
+}
+
+func (ts *TrashTestSuite) TestQueriesSkipTrash() {
+	_, err := ts.service.SocialMediaRepository.GetByID("1")
+	ts.Require().NoError(err)
+	ts.Require().Len(ts.sql.statements, 1)
+	ts.Contains(ts.sql.statements[0], `"social_media"."deleted_at" IS NULL`)
+
+	ts.sql.statements = nil
+	_, err = ts.service.SocialMediaRepository.UserGetTrash("1", 1, 10)
+	ts.Require().NoError(err)
+	ts.Require().Len(ts.sql.statements, 2)
+	for _, statement := range ts.sql.statements {
+		ts.Contains(statement, "deleted_at IS NOT NULL", "the trash lists the deleted rows only")
+	}
+}
+
+// TestRestoreBringsBackTheUserDeletion checks restoring a user brings back what was deleted with it, the rows its
+// owner deleted before stay in the trash
+func (ts *TrashTestSuite) TestRestoreBringsBackTheUserDeletion() {
+	user := &userDomain.User{DeletedAt: gorm.DeletedAt{Time: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), Valid: true}}
+	user.ID = uuid.New()
+
+	ts.Require().NoError(ts.service.UserRepository.Restore(user))
+
+	last := len(ts.sql.statements) - 1
+	ts.Require().Greater(last, 0)
+	for _, statement := range ts.sql.statements[:last] {
+		ts.Contains(statement, `SET "deleted_at"=NULL`)
+		ts.Contains(statement, "deleted_at = '2026-10-01 00:00:00'")
+	}
+	ts.Equal(`UPDATE "users" SET "deleted_at"=NULL WHERE id = '`+user.ID.String()+`'`, ts.sql.statements[last])
+}
+
+// TestPurgeKeepsRetentionWindow checks the purge only deletes for good what is in the trash for longer than the
+// retention of config.json, 30 days
+func (ts *TrashTestSuite) TestPurgeKeepsRetentionWindow() {
+	_, err := ts.service.Purge(time.Date(2026, 10, 31, 12, 0, 0, 0, time.UTC))
+	ts.Require().NoError(err)
+
+	purged := map[string]bool{}
+	for _, statement := range ts.sql.statements {
+		if !strings.HasPrefix(statement, "DELETE") {
+			continue
+		}
+
+		ts.Contains(statement, "deleted_at < '2026-10-01 12:00:00'")
+		purged[strings.Fields(statement)[2]] = true
+	}
+
+	for _, table := range []string{`"photos"`, `"comments"`, `"social_media"`} {
+		ts.True(purged[table], table)
+	}
+}
@@ -0,0 +1,86 @@
+package upload
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"os"
+	"strings"
+	"testing"
+
+	"hexagonal-fiber/application/services"
+	photoService "hexagonal-fiber/application/usecases/photo"
+	photoDomain "hexagonal-fiber/domain/photo"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/suite"
+)
+
+type UploadTestSuite struct {
+	suite.Suite
+	service photoService.Service
+	root    string
+}
+
+func TestUploadTestSuite(t *testing.T) {
+	suite.Run(t, &UploadTestSuite{})
+}
+
+func (ts *UploadTestSuite) SetupSuite() {
+	// the photo usecase reads config.json from the working directory
+	ts.NoError(os.Chdir("../../.."))
+}
+
+func (ts *UploadTestSuite) SetupTest() {
+	ts.root = ts.T().TempDir()
+	ts.service = photoService.Service{Storage: &services.LocalStorage{Directory: ts.root}}
+}
+
+func (ts *UploadTestSuite) assertStatus(code int, err error) {
+	ts.Require().Error(err)
+
+	fiberErr, ok := err.(*fiber.Error)
+	ts.Require().True(ok, err.Error())
+	ts.Equal(code, fiberErr.Code)
+}
+
+func (ts *UploadTestSuite) upload(content []byte, size int64) error {
+	_, err := ts.service.Upload(&photoDomain.NewPhoto{Title: "title", UserID: "user"}, bytes.NewReader(content), size)
+	return err
+}
+
+func (ts *UploadTestSuite) TestDeclaredSizeTooLarge() {
+	ts.assertStatus(fiber.StatusRequestEntityTooLarge, ts.upload([]byte("GIF89a"), 1<<40))
+}
+
+func (ts *UploadTestSuite) TestActualSizeTooLarge() {
+	// a client may declare less than it sends, the bytes are counted while read
+	content := append([]byte("GIF89a"), make([]byte, 11<<20)...)
+	ts.assertStatus(fiber.StatusRequestEntityTooLarge, ts.upload(content, 10))
+}
+
+func (ts *UploadTestSuite) TestContentTypeIsSniffed() {
+	ts.assertStatus(fiber.StatusUnsupportedMediaType, ts.upload([]byte(strings.Repeat("<html>", 10)), 60))
+	ts.assertStatus(fiber.StatusUnsupportedMediaType, ts.upload([]byte("%PDF-1.7"), 8))
+}
+
+func (ts *UploadTestSuite) TestUnreadableImage() {
+	var encoded bytes.Buffer
+	ts.Require().NoError(png.Encode(&encoded, image.NewGray(image.Rect(0, 0, 4, 3))))
+
+	truncated := encoded.Bytes()[:20]
+	ts.assertStatus(fiber.StatusBadRequest, ts.upload(truncated, int64(len(truncated))))
+
+	entries, err := os.ReadDir(ts.root)
+	ts.NoError(err)
+	ts.Empty(entries, "nothing is stored for a rejected upload")
+}
+
+func (ts *UploadTestSuite) TestLinkURL() {
+	ts.True(photoDomain.IsLinkURL("https://example.com/a.jpg"))
+	ts.True(photoDomain.IsLinkURL("http://example.com/a.jpg"))
+
+	for _, raw := range []string{"javascript:alert(1)", "//evil.com/a.jpg", "www.photo.com/a.jpg", "ftp://example.com/a.jpg", "https://user@evil.com/a.jpg", ""} {
+		ts.False(photoDomain.IsLinkURL(raw), raw)
+	}
+}
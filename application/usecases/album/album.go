@@ -0,0 +1,225 @@
+// Package album provides the use case for album
+package album
+
+import (
+	"hexagonal-fiber/application/security/policy"
+	"hexagonal-fiber/application/services"
+	albumDomain "hexagonal-fiber/domain/album"
+	auditDomain "hexagonal-fiber/domain/audit"
+	photoDomain "hexagonal-fiber/domain/photo"
+	secureDomain "hexagonal-fiber/domain/security"
+
+	albumRepository "hexagonal-fiber/infrastructure/repository/postgres/album"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Service is a struct that contains the repository implementation for album use case
+type Service struct {
+	AlbumRepository albumRepository.Repository
+	Audit           auditDomain.Recorder
+}
+
+// GetPublic is a function that returns the public albums of every user
+func (s *Service) GetPublic(page int, limit int) (*albumDomain.PaginationAlbum, error) {
+	return s.AlbumRepository.GetPublic(page, limit)
+}
+
+// UserGetAll is a function that returns all albums of a user
+func (s *Service) UserGetAll(userId string, page int, limit int) (*albumDomain.PaginationAlbum, error) {
+	return s.AlbumRepository.UserGetAll(userId, page, limit)
+}
+
+// GetByID is a function that returns an Album by id, a public one to anyone and a private one to the actors allowed to read it
+func (s *Service) GetByID(actor *secureDomain.Claims, id string) (*albumDomain.Album, error) {
+	return s.readable(actor, id)
+}
+
+// Photos is a function that returns the photos of an Album the actor may read in the ordering of the album
+func (s *Service) Photos(actor *secureDomain.Claims, id string, page int, limit int) (*photoDomain.PaginationPhoto, error) {
+	album, err := s.readable(actor, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.AlbumRepository.GetPhotos(album, page, limit)
+}
+
+// Create is a function that creates an album
+func (s *Service) Create(newAlbum *albumDomain.NewAlbum) (*albumDomain.Album, error) {
+	return s.AlbumRepository.Create(newAlbum.ToDomainMapper())
+}
+
+// Update is a function that updates an Album by id when the actor owns it or may update any Album,
+// the cover must be one of the photos of the album
+func (s *Service) Update(actor *secureDomain.Claims, id string, updateAlbum albumDomain.UpdateAlbum) (*albumDomain.Album, error) {
+	before, err := s.authorized(actor, policy.Update, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if updateAlbum.CoverPhotoID != nil && *updateAlbum.CoverPhotoID != "" {
+		photoIds, err := s.AlbumRepository.PhotoIDs(id)
+		if err != nil {
+			return nil, err
+		}
+
+		if !albumDomain.IsValidCover(*updateAlbum.CoverPhotoID, photoIds) {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "cover photo must be a photo of the album")
+		}
+	}
+
+	updated, err := s.AlbumRepository.Update(id, updateAlbum.ToMap())
+	if err != nil {
+		return nil, err
+	}
+
+	services.AuditEdit(s.Audit, actor, before.UserID, auditDomain.Event{
+		Action:     auditDomain.ActionAlbumUpdate,
+		TargetType: auditDomain.TargetAlbum,
+		TargetID:   before.ID.String(),
+		Changes:    auditDomain.Diff(before, updated),
+	})
+
+	return updated, nil
+}
+
+// Delete is a function that deletes an Album by id when the actor owns it or may delete any Album, its photos are kept
+func (s *Service) Delete(actor *secureDomain.Claims, id string) (err error) {
+	before, err := s.authorized(actor, policy.Delete, id)
+	if err != nil {
+		return
+	}
+
+	if err = s.AlbumRepository.Delete(id); err != nil {
+		return
+	}
+
+	services.Audit(s.Audit, actor, auditDomain.Event{
+		Action:     auditDomain.ActionAlbumDelete,
+		TargetType: auditDomain.TargetAlbum,
+		TargetID:   id,
+		Changes:    auditDomain.Diff(before, nil),
+	})
+
+	return
+}
+
+// AddPhotos is a function that adds photos of the owner of an Album after the ones it has, the first photo added
+// to an album without a cover becomes its cover
+func (s *Service) AddPhotos(actor *secureDomain.Claims, id string, photoIds []string) (*albumDomain.Album, error) {
+	before, err := s.authorized(actor, policy.Update, id)
+	if err != nil {
+		return nil, err
+	}
+
+	photoIds = albumDomain.UniquePhotoIDs(photoIds)
+	count, err := s.AlbumRepository.CountUserPhotos(before.UserID, photoIds)
+	if err != nil {
+		return nil, err
+	}
+
+	if count != int64(len(photoIds)) {
+		return nil, fiber.NewError(fiber.StatusNotFound, "photo not found among the photos of the album owner")
+	}
+
+	added, err := s.AlbumRepository.AddPhotos(id, photoIds)
+	if err != nil {
+		return nil, err
+	}
+
+	if cover, changed := before.CoverAfterAdding(photoIds); added > 0 && changed {
+		if _, err = s.AlbumRepository.Update(id, map[string]interface{}{"cover_photo_id": cover}); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.afterPhotosChange(actor, before)
+}
+
+// RemovePhotos is a function that removes photos from an Album, the photos themselves are kept
+func (s *Service) RemovePhotos(actor *secureDomain.Claims, id string, photoIds []string) (*albumDomain.Album, error) {
+	before, err := s.authorized(actor, policy.Update, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = s.AlbumRepository.RemovePhotos(id, albumDomain.UniquePhotoIDs(photoIds)); err != nil {
+		return nil, err
+	}
+
+	return s.afterPhotosChange(actor, before)
+}
+
+// Reorder is a function that moves the photos of an Album to the order given, every photo the album shows must be given once,
+// the deleted photos it still links are kept after them
+func (s *Service) Reorder(actor *secureDomain.Claims, id string, photoIds []string) (*albumDomain.Album, error) {
+	before, err := s.authorized(actor, policy.Update, id)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := s.AlbumRepository.PhotoIDs(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !albumDomain.IsOrderOf(photoIds, current) {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "photo ids must list every photo of the album once")
+	}
+
+	if err = s.AlbumRepository.Reorder(id, photoIds); err != nil {
+		return nil, err
+	}
+
+	return s.afterPhotosChange(actor, before)
+}
+
+// afterPhotosChange fetches the Album again once its photos changed and audits the change like any other edit
+func (s *Service) afterPhotosChange(actor *secureDomain.Claims, before *albumDomain.Album) (*albumDomain.Album, error) {
+	updated, err := s.AlbumRepository.GetByID(before.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	services.AuditEdit(s.Audit, actor, before.UserID, auditDomain.Event{
+		Action:     auditDomain.ActionAlbumUpdate,
+		TargetType: auditDomain.TargetAlbum,
+		TargetID:   before.ID.String(),
+		Changes:    auditDomain.Diff(before, updated),
+	})
+
+	return updated, nil
+}
+
+// readable fetches the Album first so a missing one answers not found, a public album is readable by anyone
+func (s *Service) readable(actor *secureDomain.Claims, id string) (*albumDomain.Album, error) {
+	album, err := s.AlbumRepository.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if album.Visibility == albumDomain.Public {
+		return album, nil
+	}
+
+	if err = policy.Album.Authorize(actor, policy.Read, album.UserID); err != nil {
+		return nil, err
+	}
+
+	return album, nil
+}
+
+// authorized fetches the Album first so a missing one answers not found, then checks the actor against its owner
+func (s *Service) authorized(actor *secureDomain.Claims, action string, id string) (*albumDomain.Album, error) {
+	album, err := s.AlbumRepository.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = policy.Album.Authorize(actor, action, album.UserID); err != nil {
+		return nil, err
+	}
+
+	return album, nil
+}
@@ -0,0 +1,185 @@
+// Package apikey provides the use case for the api keys of the machine to machine clients
+package apikey
+
+import (
+	"crypto/subtle"
+	"log"
+	"time"
+
+	"hexagonal-fiber/application/security/policy"
+	"hexagonal-fiber/application/services"
+
+	apiKeyDomain "hexagonal-fiber/domain/apikey"
+	auditDomain "hexagonal-fiber/domain/audit"
+	secureDomain "hexagonal-fiber/domain/security"
+	apiKeyRepository "hexagonal-fiber/infrastructure/repository/postgres/apikey"
+	userRepository "hexagonal-fiber/infrastructure/repository/postgres/user"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Service is a struct that contains the repository implementation for api key use case
+type Service struct {
+	APIKeyRepository apiKeyRepository.Repository
+	UserRepository   userRepository.Repository
+	Audit            auditDomain.Recorder
+}
+
+// GetAll is a function that returns the api keys of the user, the actor's own when no user is given
+func (s *Service) GetAll(actor *secureDomain.Claims, userID string) (*[]apiKeyDomain.APIKey, error) {
+	if userID == "" {
+		userID = actor.UserID
+	}
+
+	if err := policy.APIKey.Authorize(actor, policy.Read, userID); err != nil {
+		return nil, err
+	}
+
+	return s.APIKeyRepository.UserGetAll(userID)
+}
+
+// Create is a function that issues a new api key, its scopes can only be permissions the actor owns.
+// The full key is only part of this response, afterwards only the hash of its secret is known
+func (s *Service) Create(actor *secureDomain.Claims, newKey apiKeyDomain.NewAPIKey) (*apiKeyDomain.CreatedAPIKey, error) {
+	// a leaked key must not be able to mint more keys
+	if actor.Type == apiKeyDomain.ClaimsType {
+		return nil, fiber.NewError(fiber.StatusForbidden, "api keys cannot create api keys")
+	}
+
+	ownerID := newKey.UserID
+	if ownerID == "" {
+		ownerID = actor.UserID
+	}
+
+	if err := policy.APIKey.Authorize(actor, policy.Create, ownerID); err != nil {
+		return nil, err
+	}
+
+	for _, scope := range newKey.Scopes {
+		if !actor.Can(scope) {
+			return nil, fiber.NewError(fiber.StatusForbidden, "scope "+scope+" is not one of your permissions")
+		}
+	}
+
+	if newKey.ExpiresAt != nil && !newKey.ExpiresAt.After(time.Now()) {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "expires_at must be in the future")
+	}
+
+	if _, err := s.UserRepository.GetByID(ownerID); err != nil {
+		return nil, err
+	}
+
+	key, prefix, secretHash, err := apiKeyDomain.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := newKey.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+
+	created, err := s.APIKeyRepository.Create(&apiKeyDomain.APIKey{
+		ID:         uuid.New(),
+		Name:       newKey.Name,
+		Prefix:     prefix,
+		SecretHash: secretHash,
+		UserID:     ownerID,
+		Service:    newKey.Service,
+		Scopes:     scopes,
+		ExpiresAt:  newKey.ExpiresAt,
+		CreatedBy:  actor.UserID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	services.Audit(s.Audit, actor, auditDomain.Event{
+		Action:     auditDomain.ActionAPIKeyCreate,
+		TargetType: auditDomain.TargetAPIKey,
+		TargetID:   created.ID.String(),
+		Changes:    auditDomain.Diff(nil, created),
+	})
+
+	return &apiKeyDomain.CreatedAPIKey{APIKey: *created, Key: key}, nil
+}
+
+// Delete is a function that revokes an api key when the actor owns it or may delete any api key,
+// a leaked key cannot revoke the other keys of its owner
+func (s *Service) Delete(actor *secureDomain.Claims, id string) error {
+	if err := policy.NotAPIKey(actor, "revoke api keys"); err != nil {
+		return err
+	}
+
+	key, err := s.APIKeyRepository.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err = policy.APIKey.Authorize(actor, policy.Delete, key.UserID); err != nil {
+		return err
+	}
+
+	if err = s.APIKeyRepository.Delete(id); err != nil {
+		return err
+	}
+
+	services.Audit(s.Audit, actor, auditDomain.Event{
+		Action:     auditDomain.ActionAPIKeyRevoke,
+		TargetType: auditDomain.TargetAPIKey,
+		TargetID:   id,
+		Changes:    auditDomain.Diff(key, nil),
+	})
+
+	return nil
+}
+
+// Authenticate returns the claims of the owner of the api key, limited to the scopes of the key
+// its owner still holds, so a role change of the owner takes effect on its keys right away
+func (s *Service) Authenticate(key string) (*secureDomain.Claims, error) {
+	invalid := fiber.NewError(fiber.StatusUnauthorized, "invalid api key")
+
+	prefix, secret, ok := apiKeyDomain.ParseKey(key)
+	if !ok {
+		return nil, invalid
+	}
+
+	apiKey, err := s.APIKeyRepository.GetByPrefix(prefix)
+	if err != nil {
+		if fiberErr, ok := err.(*fiber.Error); ok && fiberErr.Code == fiber.StatusNotFound {
+			return nil, invalid
+		}
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(secureDomain.HashToken(secret)), []byte(apiKey.SecretHash)) != 1 {
+		return nil, invalid
+	}
+
+	now := time.Now()
+	if apiKey.IsExpired(now) {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "api key expired")
+	}
+
+	owner, err := s.UserRepository.GetWithRole(apiKey.UserID)
+	if err != nil {
+		return nil, invalid
+	}
+
+	// the last used time is informative, failing to write it does not fail the request
+	if err = s.APIKeyRepository.TouchLastUsed(apiKey.ID.String(), now); err != nil {
+		log.Printf("failed touching api key %s: %s", apiKey.ID, err)
+	}
+
+	claims := &secureDomain.Claims{
+		UserID:      owner.ID.String(),
+		Type:        apiKeyDomain.ClaimsType,
+		Role:        owner.Role.Name,
+		Permissions: apiKey.EffectivePermissions(owner.Role.PermissionNames()),
+		Version:     owner.TokenVersion,
+	}
+	claims.Id = apiKey.ID.String()
+
+	return claims, nil
+}
@@ -0,0 +1,23 @@
+// Package audit provides the use case for the audit log
+package audit
+
+import (
+	auditDomain "hexagonal-fiber/domain/audit"
+	auditRepository "hexagonal-fiber/infrastructure/repository/postgres/audit"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Service is a struct that contains the repository implementation for audit use case
+type Service struct {
+	AuditRepository auditRepository.Repository
+}
+
+// GetAll is a function that returns the audit events matching the filter
+func (s *Service) GetAll(filter auditDomain.Filter, page int, limit int) (*auditDomain.PaginationEvent, error) {
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "from must be before to")
+	}
+
+	return s.AuditRepository.GetAll(filter, page, limit)
+}
@@ -2,101 +2,351 @@
 package auth
 
 import (
+	"log"
 	"time"
 
 	"hexagonal-fiber/application/security/jwt"
+	"hexagonal-fiber/application/security/oidc"
+	"hexagonal-fiber/application/services"
 
+	auditDomain "hexagonal-fiber/domain/audit"
+	mailDomain "hexagonal-fiber/domain/mail"
+	secureDomain "hexagonal-fiber/domain/security"
 	userDomain "hexagonal-fiber/domain/user"
+	identityRepository "hexagonal-fiber/infrastructure/repository/postgres/identity"
+	mfaRepository "hexagonal-fiber/infrastructure/repository/postgres/mfa"
 	roleRepository "hexagonal-fiber/infrastructure/repository/postgres/role"
 	userRepository "hexagonal-fiber/infrastructure/repository/postgres/user"
+	limiterRepository "hexagonal-fiber/infrastructure/repository/redis/limiter"
+	lockoutRepository "hexagonal-fiber/infrastructure/repository/redis/lockout"
+	oidcRepository "hexagonal-fiber/infrastructure/repository/redis/oidc"
+	resetRepository "hexagonal-fiber/infrastructure/repository/redis/reset"
+	sessionRepository "hexagonal-fiber/infrastructure/repository/redis/session"
+	tokenRepository "hexagonal-fiber/infrastructure/repository/redis/token"
 
 	"github.com/gofiber/fiber/v2"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
 )
 
 // Service is a struct that contains the repository implementation for auth use case
 type Service struct {
-	UserRepository userRepository.Repository
-	RoleRepository roleRepository.Repository
+	UserRepository     userRepository.Repository
+	RoleRepository     roleRepository.Repository
+	MFARepository      mfaRepository.Repository
+	TokenRepository    tokenRepository.Repository
+	SessionRepository  sessionRepository.Repository
+	LimiterRepository  limiterRepository.Repository
+	ResetRepository    resetRepository.Repository
+	LockoutRepository  lockoutRepository.Repository
+	IdentityRepository identityRepository.Repository
+	OIDCRepository     oidcRepository.Repository
+	OIDC               *oidc.Client
+	Mailer             mailDomain.Mailer
+	Events             secureDomain.EventPublisher
+	Audit              auditDomain.Recorder
+	Passwords          secureDomain.PasswordHasher
+	TokenVersions      services.TokenVersions
 }
 
-// Create is a function that creates a new user
+// Create is a function that creates a new user, with the default role when none is given
 func (s *Service) Create(newUser userDomain.NewUser) (*userDomain.User, error) {
-
-	_, err := s.RoleRepository.GetByID(newUser.RoleID)
+	role, err := s.registrationRole(newUser.RoleID)
 	if err != nil {
 		return nil, err
 	}
 
 	user := newUser.ToDomainMapper()
+	user.RoleID = role.ID.String()
 
-	hash, err := bcrypt.GenerateFromPassword([]byte(newUser.Password), bcrypt.DefaultCost)
+	hash, err := s.Passwords.Hash(newUser.Password)
 	if err != nil {
 		return nil, err
 	}
-	user.HashPassword = string(hash)
+	user.HashPassword = hash
+
+	createdUser, err := s.UserRepository.Create(user)
+	if err != nil {
+		return nil, err
+	}
+
+	// a failed delivery does not fail the registration, the user can ask for a new email
+	if err = s.sendVerification(createdUser); err != nil {
+		log.Printf("failed sending verification mail: %s", err)
+	}
 
-	return s.UserRepository.Create(user)
+	return createdUser, nil
 }
 
-// LoginJWT implements the login with jwt methode use case
-func (s *Service) LoginJWT(user userDomain.LoginRequest) (*userDomain.SecurityAuthenticatedUser, error) {
-	userMap := map[string]interface{}{"email": user.Email}
-	userRole, err := s.UserRepository.GetWithRoleByMap(userMap)
+// registrationRole returns the role a new user registers with, roles owning permissions are only given by an admin
+func (s *Service) registrationRole(roleID string) (*userDomain.Role, error) {
+	if roleID == "" {
+		viper.SetConfigFile("config.json")
+		if err := viper.ReadInConfig(); err != nil {
+			return nil, err
+		}
+
+		return s.RoleRepository.GetByName(viper.GetString("Secure.DefaultRole"))
+	}
+
+	role, err := s.RoleRepository.GetByID(roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(role.Permissions) > 0 {
+		return nil, fiber.NewError(fiber.StatusForbidden, "role can only be assigned by an admin")
+	}
+
+	return role, nil
+}
+
+// LoginJWT implements the login with jwt methode use case, every login opens a new session.
+// Users with two factor enabled, or whose role requires it, get a mfa challenge instead of a session.
+// Failed logins are counted per account and per IP, both get locked out past their threshold
+func (s *Service) LoginJWT(user userDomain.LoginRequest, client secureDomain.ClientInfo) (*userDomain.SecurityAuthenticatedUser, *userDomain.MFAChallenge, error) {
+	email := normalizeEmail(user.Email)
+	if err := s.checkLockout(email, client.IP); err != nil {
+		return nil, nil, err
+	}
+
+	// the account is looked up whatever the casing typed, like its lockout
+	userRole, err := s.UserRepository.GetWithRoleByEmail(user.Email)
 
 	if err != nil || userRole.ID.String() == "" {
+		// unknown emails count as failures too so probing accounts gets locked out the same way
+		if err = s.recordLoginFailure(email, client, ""); err != nil {
+			return nil, nil, err
+		}
+
 		err = fiber.NewError(fiber.StatusUnauthorized, "email or password does not match")
-		return nil, err
+		return nil, nil, err
+	}
+
+	isAuthenticated, rehash, err := s.Passwords.Verify(user.Password, userRole.HashPassword)
+	// users registered through an identity provider have no password until they reset one
+	if err != nil && userRole.HashPassword != "" {
+		log.Printf("unreadable password hash for user %s: %s", userRole.ID, err)
 	}
 
-	isAuthenticated := CheckPasswordHash(user.Password, userRole.HashPassword)
 	if !isAuthenticated {
+		if err = s.recordLoginFailure(email, client, userRole.ID.String()); err != nil {
+			return nil, nil, err
+		}
+
 		err = fiber.NewError(fiber.StatusUnauthorized, "email or password does not match")
+		return nil, nil, err
+	}
+
+	if rehash {
+		s.rehashPassword(userRole.ID.String(), user.Password)
+	}
+
+	if userRole.VerifiedAt == nil {
+		return nil, nil, fiber.NewError(fiber.StatusForbidden, "email is not verified")
+	}
+
+	if userRole.MFAEnabledAt != nil || userRole.Role.RequireMFA {
+		challenge, err := s.mfaChallenge(userRole)
+		return nil, challenge, err
+	}
+
+	// the failures of the account are only forgotten once every factor succeeded, LoginMFA forgets them after the second one
+	if err = s.LockoutRepository.Reset(lockoutRepository.Account, email); err != nil {
+		return nil, nil, err
+	}
+
+	authDataUser, err := s.openSession(userRole, client)
+	return authDataUser, nil, err
+}
+
+// AccessTokenByRefreshToken implements the Access Token By Refresh Token use case,
+// every call rotates the refresh token and a reused refresh token revokes its whole session
+func (s *Service) AccessTokenByRefreshToken(refreshToken string) (*userDomain.SecurityAuthenticatedUser, error) {
+	claims, err := jwt.GetClaimsAndVerifyToken(refreshToken, jwt.Refresh)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := s.SessionRepository.GetByID(claims.SessionID)
+	if err != nil || session.UserID != claims.UserID {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "refresh token revoked")
+	}
+
+	userMap := map[string]interface{}{"id": claims.UserID}
+	userRole, err := s.UserRepository.GetWithRoleByMap(userMap)
+	if err != nil || userRole.ID.String() == "" {
+		err = fiber.NewError(fiber.StatusNotFound, "user not found")
 		return nil, err
 	}
 
-	accessTokenClaims, err := jwt.GenerateJWTToken(userRole.ID.String(), "access", userRole.Role.Name)
+	// a password or role change since the login invalidated the session
+	if claims.Version != userRole.TokenVersion {
+		_ = s.revoke(session.UserID, session.ID)
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "refresh token revoked")
+	}
+
+	newAccessToken, newRefreshToken, err := generateTokenPair(userRole, session.ID)
 	if err != nil {
 		return nil, err
 	}
-	refreshTokenClaims, err := jwt.GenerateJWTToken(userRole.ID.String(), "refresh", userRole.Role.Name)
+
+	err = s.TokenRepository.RotateFamily(session.ID, claims.Id, newRefreshToken.TokenID, newRefreshToken.ExpirationTime)
 	if err != nil {
+		_ = s.SessionRepository.Delete(session.UserID, session.ID)
+		return nil, err
+	}
+
+	session.LastSeenAt = time.Now()
+	session.ExpiresAt = newRefreshToken.ExpirationTime
+	if err = s.SessionRepository.Save(session); err != nil {
 		return nil, err
 	}
 
 	return userDomain.SecAuthUserRoleMapper(userRole, &userDomain.Auth{
-		AccessToken:               accessTokenClaims.Token,
-		RefreshToken:              refreshTokenClaims.Token,
-		ExpirationAccessDateTime:  accessTokenClaims.ExpirationTime,
-		ExpirationRefreshDateTime: refreshTokenClaims.ExpirationTime,
-	}), err
+		SessionID:                 session.ID,
+		AccessToken:               newAccessToken.Token,
+		ExpirationAccessDateTime:  newAccessToken.ExpirationTime,
+		RefreshToken:              newRefreshToken.Token,
+		ExpirationRefreshDateTime: newRefreshToken.ExpirationTime,
+	}), nil
 }
 
-// AccessTokenByRefreshToken implements the Access Token By Refresh Token use case
-func (s *Service) AccessTokenByRefreshToken(refreshToken string) (*userDomain.SecurityAuthenticatedUser, error) {
-	claimsMap, err := jwt.GetClaimsAndVerifyToken(refreshToken, "refresh")
+// Logout revokes the session of the given claims
+func (s *Service) Logout(claims *secureDomain.Claims) error {
+	return s.RevokeSession(claims.UserID, claims.SessionID)
+}
+
+// LogoutAll revokes every session of the user and every token already issued
+func (s *Service) LogoutAll(userID string) error {
+	sessions, err := s.SessionRepository.UserGetAll(userID)
+	if err != nil {
+		return err
+	}
+
+	for _, session := range *sessions {
+		if err = s.revoke(userID, session.ID); err != nil {
+			return err
+		}
+	}
+
+	return s.TokenVersions.Bump(userID)
+}
+
+// GetSessions returns the sessions of the user flagging the one of the given claims
+func (s *Service) GetSessions(claims *secureDomain.Claims) (*[]secureDomain.Session, error) {
+	sessions, err := s.SessionRepository.UserGetAll(claims.UserID)
 	if err != nil {
 		return nil, err
 	}
 
-	userMap := map[string]interface{}{"id": claimsMap["user_id"]}
-	userRole, err := s.UserRepository.GetWithRoleByMap(userMap)
-	if err != nil || userRole.ID.String() == "" {
-		err = fiber.NewError(fiber.StatusNotFound, "user not found")
+	for i := range *sessions {
+		(*sessions)[i].Current = (*sessions)[i].ID == claims.SessionID
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession revokes one session of the user
+func (s *Service) RevokeSession(userID string, sessionID string) error {
+	session, err := s.SessionRepository.GetByID(sessionID)
+	if err != nil {
+		return err
+	}
+
+	if session.UserID != userID {
+		return fiber.NewError(fiber.StatusNotFound, "session not found")
+	}
+
+	return s.revoke(userID, sessionID)
+}
+
+// openSession issues a new token pair for the user and registers it as a new session
+func (s *Service) openSession(userRole *userDomain.UserRole, client secureDomain.ClientInfo) (*userDomain.SecurityAuthenticatedUser, error) {
+	sessionID := uuid.New().String()
+	accessToken, refreshToken, err := generateTokenPair(userRole, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.TokenRepository.SaveFamily(sessionID, refreshToken.TokenID, refreshToken.ExpirationTime)
+	if err != nil {
 		return nil, err
 	}
 
-	accessTokenClaims, err := jwt.GenerateJWTToken(userRole.ID.String(), "access", userRole.Role.Name)
+	nowTime := time.Now()
+	err = s.SessionRepository.Save(&secureDomain.Session{
+		ID:         sessionID,
+		UserID:     userRole.ID.String(),
+		IP:         client.IP,
+		UserAgent:  client.UserAgent,
+		CreatedAt:  nowTime,
+		LastSeenAt: nowTime,
+		ExpiresAt:  refreshToken.ExpirationTime,
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	var expTime = int64(claimsMap["exp"].(float64))
+	services.Audit(s.Audit, nil, auditDomain.Event{
+		Action:     auditDomain.ActionLogin,
+		ActorID:    userRole.ID.String(),
+		TargetType: auditDomain.TargetUser,
+		TargetID:   userRole.ID.String(),
+		IP:         client.IP,
+		UserAgent:  client.UserAgent,
+		Changes:    auditDomain.Changes{"session_id": {After: sessionID}},
+	})
 
 	return userDomain.SecAuthUserRoleMapper(userRole, &userDomain.Auth{
-		AccessToken:               accessTokenClaims.Token,
-		ExpirationAccessDateTime:  accessTokenClaims.ExpirationTime,
-		RefreshToken:              refreshToken,
-		ExpirationRefreshDateTime: time.Unix(expTime, 0),
+		SessionID:                 sessionID,
+		AccessToken:               accessToken.Token,
+		RefreshToken:              refreshToken.Token,
+		ExpirationAccessDateTime:  accessToken.ExpirationTime,
+		ExpirationRefreshDateTime: refreshToken.ExpirationTime,
 	}), nil
 }
+
+// rehashPassword replaces an outdated hash with one of the current hasher, the plaintext is only known
+// right after a successful login so that is when old hashes get upgraded. A failure keeps the old hash
+func (s *Service) rehashPassword(userID string, password string) {
+	hash, err := s.Passwords.Hash(password)
+	if err == nil {
+		err = s.UserRepository.UpdateByMap(userID, map[string]interface{}{"hash_password": hash})
+	}
+
+	if err != nil {
+		log.Printf("failed upgrading the password hash of user %s: %s", userID, err)
+	}
+}
+
+// revoke drops the refresh token family and the session data
+func (s *Service) revoke(userID string, sessionID string) error {
+	if err := s.TokenRepository.RevokeFamily(sessionID); err != nil {
+		return err
+	}
+
+	return s.SessionRepository.Delete(userID, sessionID)
+}
+
+// generateTokenPair generates an access and refresh token belonging to the same session
+func generateTokenPair(userRole *userDomain.UserRole, sessionID string) (accessToken *secureDomain.AppToken, refreshToken *secureDomain.AppToken, err error) {
+	accessToken, err = jwt.GenerateJWTToken(jwt.Access, &secureDomain.Claims{
+		UserID:      userRole.ID.String(),
+		Role:        userRole.Role.Name,
+		SessionID:   sessionID,
+		Permissions: userRole.Role.PermissionNames(),
+		Version:     userRole.TokenVersion,
+	})
+	if err != nil {
+		return
+	}
+
+	refreshToken, err = jwt.GenerateJWTToken(jwt.Refresh, &secureDomain.Claims{
+		UserID:    userRole.ID.String(),
+		Role:      userRole.Role.Name,
+		SessionID: sessionID,
+		Version:   userRole.TokenVersion,
+	})
+	return
+}
@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"time"
+
+	"hexagonal-fiber/application/security/jwt"
+	"hexagonal-fiber/application/services"
+
+	apiKeyDomain "hexagonal-fiber/domain/apikey"
+	auditDomain "hexagonal-fiber/domain/audit"
+	secureDomain "hexagonal-fiber/domain/security"
+	userDomain "hexagonal-fiber/domain/user"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+)
+
+// Impersonate issues the actor a short-lived access token of the user, without refresh token.
+// The token carries the permissions of the user and the actor id, so every request made with it is attributed to the actor
+func (s *Service) Impersonate(actor *secureDomain.Claims, userID string) (*userDomain.Impersonation, error) {
+	if actor.IsImpersonated() || actor.Type == apiKeyDomain.ClaimsType {
+		return nil, fiber.NewError(fiber.StatusForbidden, "impersonation needs the own session of an admin")
+	}
+
+	if userID == actor.UserID {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "you cannot impersonate yourself")
+	}
+
+	userRole, err := s.UserRepository.GetWithRole(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// impersonating must not grant the actor permissions it does not own
+	for _, permission := range userRole.Role.PermissionNames() {
+		if !actor.Can(permission) {
+			return nil, fiber.NewError(fiber.StatusForbidden, "you cannot impersonate a user owning permissions you do not have")
+		}
+	}
+
+	lifetime, err := impersonationTime()
+	if err != nil {
+		return nil, err
+	}
+
+	sessionID := uuid.New().String()
+	accessToken, err := jwt.GenerateJWTTokenFor(jwt.Access, &secureDomain.Claims{
+		UserID:      userRole.ID.String(),
+		Role:        userRole.Role.Name,
+		SessionID:   sessionID,
+		Permissions: userRole.Role.PermissionNames(),
+		Version:     userRole.TokenVersion,
+		ActorID:     actor.UserID,
+	}, lifetime)
+	if err != nil {
+		return nil, err
+	}
+
+	// the family lets the impersonation be stopped like a session, it never sees a refresh token
+	err = s.TokenRepository.SaveFamily(sessionID, accessToken.TokenID, accessToken.ExpirationTime)
+	if err != nil {
+		return nil, err
+	}
+
+	services.Audit(s.Audit, actor, auditDomain.Event{
+		Action:     auditDomain.ActionImpersonationStart,
+		TargetType: auditDomain.TargetUser,
+		TargetID:   userRole.ID.String(),
+		Changes: auditDomain.Changes{
+			"session_id": {After: sessionID},
+			"expires_at": {After: accessToken.ExpirationTime},
+		},
+	})
+
+	return &userDomain.Impersonation{
+		UserID:                   userRole.ID.String(),
+		ActorID:                  actor.UserID,
+		SessionID:                sessionID,
+		JWTAccessToken:           accessToken.Token,
+		ExpirationAccessDateTime: accessToken.ExpirationTime,
+	}, nil
+}
+
+// StopImpersonation revokes the impersonation token of the claims before it expires
+func (s *Service) StopImpersonation(claims *secureDomain.Claims) error {
+	if !claims.IsImpersonated() {
+		return fiber.NewError(fiber.StatusBadRequest, "not impersonating")
+	}
+
+	if err := s.TokenRepository.RevokeFamily(claims.SessionID); err != nil {
+		return err
+	}
+
+	services.Audit(s.Audit, claims, auditDomain.Event{
+		Action:     auditDomain.ActionImpersonationStop,
+		TargetType: auditDomain.TargetUser,
+		TargetID:   claims.UserID,
+		Changes:    auditDomain.Changes{"session_id": {Before: claims.SessionID}},
+	})
+
+	return nil
+}
+
+// impersonationTime returns how long an impersonation token lives
+func impersonationTime() (time.Duration, error) {
+	viper.SetConfigFile("config.json")
+	if err := viper.ReadInConfig(); err != nil {
+		return 0, err
+	}
+
+	minutes := viper.GetInt("Secure.ImpersonationTimeMinute")
+	if minutes <= 0 {
+		return 0, fiber.NewError(fiber.StatusInternalServerError, "impersonation time is not configured")
+	}
+
+	return time.Duration(minutes) * time.Minute, nil
+}
@@ -0,0 +1,178 @@
+package auth
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"hexagonal-fiber/application/services"
+
+	auditDomain "hexagonal-fiber/domain/audit"
+	secureDomain "hexagonal-fiber/domain/security"
+	userDomain "hexagonal-fiber/domain/user"
+	lockoutRepository "hexagonal-fiber/infrastructure/repository/redis/lockout"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/spf13/viper"
+)
+
+// lockoutPolicy is the configuration of the login lockouts
+type lockoutPolicy struct {
+	accountThreshold int64
+	ipThreshold      int64
+	window           time.Duration
+	baseLock         time.Duration
+	maxLock          time.Duration
+}
+
+// Unlock lifts the lockout of an account, and of an IP when given, on behalf of an admin
+func (s *Service) Unlock(request userDomain.UnlockRequest, actorID string) error {
+	email := normalizeEmail(request.Email)
+	if err := s.LockoutRepository.Unlock(lockoutRepository.Account, email); err != nil {
+		return err
+	}
+
+	if request.IP != "" {
+		if err := s.LockoutRepository.Unlock(lockoutRepository.IP, request.IP); err != nil {
+			return err
+		}
+	}
+
+	s.publish(secureDomain.Event{
+		Type:    secureDomain.EventAccountUnlocked,
+		ActorID: actorID,
+		Subject: email,
+		IP:      request.IP,
+	})
+
+	return nil
+}
+
+// checkLockout refuses the login while the account or the IP is locked out
+func (s *Service) checkLockout(email string, ip string) error {
+	subjects := map[string]string{lockoutRepository.Account: email, lockoutRepository.IP: ip}
+
+	for kind, subject := range subjects {
+		lockedFor, err := s.LockoutRepository.LockedFor(kind, subject)
+		if err != nil {
+			return err
+		}
+
+		if lockedFor > 0 {
+			message := fmt.Sprintf("too many failed logins, retry in %d seconds", int(math.Ceil(lockedFor.Seconds())))
+			return fiber.NewError(fiber.StatusTooManyRequests, message)
+		}
+	}
+
+	return nil
+}
+
+// recordLoginFailure counts a failed login against the account and the IP, locking them out
+// once their threshold is reached with a lockout doubling on every further failure
+func (s *Service) recordLoginFailure(email string, client secureDomain.ClientInfo, userID string) error {
+	targetID := userID
+	if targetID == "" {
+		targetID = email
+	}
+
+	services.Audit(s.Audit, nil, auditDomain.Event{
+		Action:     auditDomain.ActionLoginFailed,
+		TargetType: auditDomain.TargetUser,
+		TargetID:   targetID,
+		IP:         client.IP,
+		UserAgent:  client.UserAgent,
+	})
+
+	policy, err := readLockoutPolicy()
+	if err != nil {
+		return err
+	}
+
+	accountFailures, err := s.LockoutRepository.Fail(lockoutRepository.Account, email, policy.window)
+	if err != nil {
+		return err
+	}
+
+	if accountFailures >= policy.accountThreshold {
+		lockFor := policy.backoff(accountFailures - policy.accountThreshold)
+		if err = s.LockoutRepository.Lock(lockoutRepository.Account, email, lockFor); err != nil {
+			return err
+		}
+
+		s.publish(secureDomain.Event{
+			Type:    secureDomain.EventAccountLocked,
+			UserID:  userID,
+			Subject: email,
+			IP:      client.IP,
+			Detail:  map[string]interface{}{"failures": accountFailures, "lock_seconds": lockFor.Seconds()},
+		})
+	}
+
+	ipFailures, err := s.LockoutRepository.Fail(lockoutRepository.IP, client.IP, policy.window)
+	if err != nil {
+		return err
+	}
+
+	if ipFailures >= policy.ipThreshold {
+		lockFor := policy.backoff(ipFailures - policy.ipThreshold)
+		if err = s.LockoutRepository.Lock(lockoutRepository.IP, client.IP, lockFor); err != nil {
+			return err
+		}
+
+		s.publish(secureDomain.Event{
+			Type:   secureDomain.EventIPLocked,
+			IP:     client.IP,
+			Detail: map[string]interface{}{"failures": ipFailures, "lock_seconds": lockFor.Seconds()},
+		})
+	}
+
+	return nil
+}
+
+// publish sends a security event, a failing publisher never fails the request
+func (s *Service) publish(event secureDomain.Event) {
+	if s.Events == nil {
+		return
+	}
+
+	event.OccurredAt = time.Now()
+	if err := s.Events.Publish(event); err != nil {
+		log.Printf("failed publishing security event %s: %s", event.Type, err)
+	}
+}
+
+// backoff returns the lockout after the given number of failures past the threshold
+func (p *lockoutPolicy) backoff(extraFailures int64) time.Duration {
+	lockFor := p.baseLock
+	for i := int64(0); i < extraFailures && lockFor < p.maxLock; i++ {
+		lockFor *= 2
+	}
+
+	if lockFor > p.maxLock {
+		return p.maxLock
+	}
+
+	return lockFor
+}
+
+func readLockoutPolicy() (*lockoutPolicy, error) {
+	viper.SetConfigFile("config.json")
+	if err := viper.ReadInConfig(); err != nil {
+		return nil, err
+	}
+
+	return &lockoutPolicy{
+		accountThreshold: viper.GetInt64("Secure.LoginMaxAccountFailures"),
+		ipThreshold:      viper.GetInt64("Secure.LoginMaxIPFailures"),
+		window:           time.Duration(viper.GetInt("Secure.LoginFailureWindowMinute")) * time.Minute,
+		baseLock:         time.Duration(viper.GetInt("Secure.LoginLockoutBaseSecond")) * time.Second,
+		maxLock:          time.Duration(viper.GetInt("Secure.LoginLockoutMaxMinute")) * time.Minute,
+	}, nil
+}
+
+// normalizeEmail gives the counters of an account or a mailbox a single key whatever the casing typed
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -1,9 +0,0 @@
-package auth
-
-import "golang.org/x/crypto/bcrypt"
-
-// CheckPasswordHash compares a bcrypt hashed password with its possible plaintext equivalent.
-func CheckPasswordHash(password, hash string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-	return err == nil
-}
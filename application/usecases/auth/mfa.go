@@ -0,0 +1,322 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"strings"
+	"time"
+
+	"hexagonal-fiber/application/security/jwt"
+	"hexagonal-fiber/application/security/totp"
+	"hexagonal-fiber/application/services"
+
+	auditDomain "hexagonal-fiber/domain/audit"
+	secureDomain "hexagonal-fiber/domain/security"
+	userDomain "hexagonal-fiber/domain/user"
+
+	lockoutRepository "hexagonal-fiber/infrastructure/repository/redis/lockout"
+	mssgConst "hexagonal-fiber/utils/constant/message"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/spf13/viper"
+)
+
+// recoveryCodesCount is the number of recovery codes generated on every enrollment
+const recoveryCodesCount = 10
+
+// LoginMFA implements the second step of a two factor login, exchanging a mfa token and a code for a session.
+// A user whose role requires two factor and that is not enrolled yet finishes the enrollment here.
+// Wrong codes are counted against the account like wrong passwords so fresh mfa tokens do not give fresh guesses
+func (s *Service) LoginMFA(request userDomain.LoginMFARequest, client secureDomain.ClientInfo) (*userDomain.SecurityAuthenticatedUser, error) {
+	claims, userRole, err := s.mfaTokenUser(request.MFAToken)
+	if err != nil {
+		return nil, err
+	}
+
+	email := normalizeEmail(userRole.Email)
+	if err = s.checkLockout(email, client.IP); err != nil {
+		return nil, err
+	}
+
+	var recoveryCodes []string
+	if userRole.MFAEnabledAt != nil {
+		err = s.checkMFACode(userRole.ID.String(), userRole.TOTPSecret, request.Code)
+	} else {
+		recoveryCodes, err = s.enableMFA(&userRole.User, request.Code)
+	}
+
+	if err != nil {
+		if fiberErr, ok := err.(*fiber.Error); ok && fiberErr.Code == fiber.StatusUnauthorized {
+			if failErr := s.recordLoginFailure(email, client, userRole.ID.String()); failErr != nil {
+				return nil, failErr
+			}
+		}
+		return nil, err
+	}
+
+	// a mfa token can only open one session, of concurrent requests with the same token only the first claims it
+	claimed, err := s.LimiterRepository.Claim("mfa:used:"+claims.Id, time.Until(time.Unix(claims.ExpiresAt, 0)))
+	if err != nil {
+		return nil, err
+	}
+
+	if !claimed {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "invalid token")
+	}
+
+	if err = s.LockoutRepository.Reset(lockoutRepository.Account, email); err != nil {
+		return nil, err
+	}
+
+	authDataUser, err := s.openSession(userRole, client)
+	if err != nil {
+		return nil, err
+	}
+
+	authDataUser.RecoveryCodes = recoveryCodes
+	return authDataUser, nil
+}
+
+// LoginMFAEnroll starts the two factor enrollment of a user whose role requires it, using the mfa token of the login
+func (s *Service) LoginMFAEnroll(mfaToken string) (*userDomain.MFAEnrollment, error) {
+	_, userRole, err := s.mfaTokenUser(mfaToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.startEnrollment(&userRole.User)
+}
+
+// EnrollMFA starts the two factor enrollment of an authenticated user
+func (s *Service) EnrollMFA(userID string) (*userDomain.MFAEnrollment, error) {
+	user, err := s.UserRepository.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.startEnrollment(user)
+}
+
+// ConfirmMFA finishes the two factor enrollment of an authenticated user and returns its recovery codes
+func (s *Service) ConfirmMFA(userID string, code string) ([]string, error) {
+	user, err := s.UserRepository.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.enableMFA(user, code)
+}
+
+// DisableMFA turns off two factor for the user after checking one last code
+func (s *Service) DisableMFA(userID string, code string) error {
+	userRole, err := s.UserRepository.GetWithRole(userID)
+	if err != nil {
+		return err
+	}
+
+	if userRole.MFAEnabledAt == nil {
+		return fiber.NewError(fiber.StatusBadRequest, "two factor is not enabled")
+	}
+
+	if userRole.Role.RequireMFA {
+		return fiber.NewError(fiber.StatusForbidden, "two factor is required for your role")
+	}
+
+	if err = s.checkMFACode(userID, userRole.TOTPSecret, code); err != nil {
+		return err
+	}
+
+	err = s.UserRepository.UpdateByMap(userID, map[string]interface{}{"totp_secret": "", "mfa_enabled_at": nil})
+	if err != nil {
+		return err
+	}
+
+	return s.MFARepository.DeleteRecoveryCodes(userID)
+}
+
+// RequireRoleMFA sets whether the users of a role must log in with two factor
+func (s *Service) RequireRoleMFA(actor *secureDomain.Claims, roleID string, require bool) (*userDomain.Role, error) {
+	before, err := s.RoleRepository.GetByID(roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := s.RoleRepository.UpdateByMap(roleID, map[string]interface{}{"require_mfa": require})
+	if err != nil {
+		return nil, err
+	}
+
+	services.Audit(s.Audit, actor, auditDomain.Event{
+		Action:     auditDomain.ActionRoleUpdate,
+		TargetType: auditDomain.TargetRole,
+		TargetID:   roleID,
+		Changes:    auditDomain.Changes{"require_mfa": {Before: before.RequireMFA, After: role.RequireMFA}},
+	})
+
+	return role, nil
+}
+
+// mfaChallenge returns the pending second step of the login of the user
+func (s *Service) mfaChallenge(userRole *userDomain.UserRole) (*userDomain.MFAChallenge, error) {
+	mfaToken, err := jwt.GenerateJWTToken(jwt.MFA, &secureDomain.Claims{
+		UserID:  userRole.ID.String(),
+		Role:    userRole.Role.Name,
+		Version: userRole.TokenVersion,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &userDomain.MFAChallenge{
+		MFAToken:           mfaToken.Token,
+		ExpirationDateTime: mfaToken.ExpirationTime,
+		EnrollmentRequired: userRole.MFAEnabledAt == nil,
+	}, nil
+}
+
+// mfaTokenUser verifies a mfa token, limiting the attempts made with it, and returns the user it belongs to
+func (s *Service) mfaTokenUser(mfaToken string) (*secureDomain.Claims, *userDomain.UserRole, error) {
+	claims, err := jwt.GetClaimsAndVerifyToken(mfaToken, jwt.MFA)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// a password reset or a logout from everywhere since the first step invalidated the mfa token too
+	version, err := s.TokenVersions.Current(claims.UserID)
+	if err != nil || version != claims.Version {
+		return nil, nil, fiber.NewError(fiber.StatusUnauthorized, "mfa token revoked")
+	}
+
+	viper.SetConfigFile("config.json")
+	if err = viper.ReadInConfig(); err != nil {
+		return nil, nil, err
+	}
+
+	attempts, err := s.LimiterRepository.Hit("mfa:attempt:"+claims.Id, time.Until(time.Unix(claims.ExpiresAt, 0)))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if attempts > viper.GetInt64("Secure.MFAMaxAttempts") {
+		return nil, nil, fiber.NewError(fiber.StatusTooManyRequests, mssgConst.StatusTooManyRequests)
+	}
+
+	userRole, err := s.UserRepository.GetWithRole(claims.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return claims, userRole, nil
+}
+
+// startEnrollment stores a new pending secret for the user
+func (s *Service) startEnrollment(user *userDomain.User) (*userDomain.MFAEnrollment, error) {
+	if user.MFAEnabledAt != nil {
+		return nil, fiber.NewError(fiber.StatusConflict, "two factor is already enabled")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.UserRepository.UpdateByMap(user.ID.String(), map[string]interface{}{"totp_secret": secret}); err != nil {
+		return nil, err
+	}
+
+	viper.SetConfigFile("config.json")
+	if err = viper.ReadInConfig(); err != nil {
+		return nil, err
+	}
+
+	uri := totp.URI(viper.GetString("Secure.MFAIssuer"), user.Email, secret)
+	return &userDomain.MFAEnrollment{
+		Secret:    secret,
+		OTPAuth:   uri,
+		QRPayload: uri,
+	}, nil
+}
+
+// enableMFA checks a code against the pending secret of the user, enables two factor and returns new recovery codes
+func (s *Service) enableMFA(user *userDomain.User, code string) ([]string, error) {
+	if user.MFAEnabledAt != nil {
+		return nil, fiber.NewError(fiber.StatusConflict, "two factor is already enabled")
+	}
+
+	if user.TOTPSecret == "" {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "two factor enrollment is not started")
+	}
+
+	step, ok := totp.Match(user.TOTPSecret, code, time.Now())
+	if !ok {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "invalid two factor code")
+	}
+
+	if err := s.acceptTOTPStep(user.ID.String(), step); err != nil {
+		return nil, err
+	}
+
+	recoveryCodes, hashCodes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.MFARepository.ReplaceRecoveryCodes(user.ID.String(), hashCodes); err != nil {
+		return nil, err
+	}
+
+	err = s.UserRepository.UpdateByMap(user.ID.String(), map[string]interface{}{"mfa_enabled_at": time.Now()})
+	if err != nil {
+		return nil, err
+	}
+
+	return recoveryCodes, nil
+}
+
+// checkMFACode accepts either a valid totp code not used yet or an unused recovery code
+func (s *Service) checkMFACode(userID string, secret string, code string) error {
+	if step, ok := totp.Match(secret, code, time.Now()); ok {
+		return s.acceptTOTPStep(userID, step)
+	}
+
+	return s.MFARepository.UseRecoveryCode(userID, secureDomain.HashToken(normalizeRecoveryCode(code)))
+}
+
+// acceptTOTPStep records the time step of an accepted totp code, a code is valid during the whole skew window
+// so one seen already, or one older than the last accepted, is refused
+func (s *Service) acceptTOTPStep(userID string, step int64) error {
+	accepted, err := s.UserRepository.AcceptTOTPStep(userID, step)
+	if err != nil {
+		return err
+	}
+
+	if !accepted {
+		return fiber.NewError(fiber.StatusUnauthorized, "invalid two factor code")
+	}
+
+	return nil
+}
+
+// generateRecoveryCodes returns readable recovery codes along with the hashes to store
+func generateRecoveryCodes() (codes []string, hashCodes []string, err error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+
+	for i := 0; i < recoveryCodesCount; i++ {
+		b := make([]byte, 6)
+		if _, err = rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+
+		code := strings.ToLower(encoding.EncodeToString(b))
+		codes = append(codes, code[:5]+"-"+code[5:])
+		hashCodes = append(hashCodes, secureDomain.HashToken(code))
+	}
+
+	return
+}
+
+// normalizeRecoveryCode drops the separators and casing a user may type a recovery code with
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
@@ -0,0 +1,227 @@
+package auth
+
+import (
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"hexagonal-fiber/application/security/oidc"
+	"hexagonal-fiber/application/services"
+
+	auditDomain "hexagonal-fiber/domain/audit"
+	secureDomain "hexagonal-fiber/domain/security"
+	userDomain "hexagonal-fiber/domain/user"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// oidcStateTTL is how long a user has to log in at the provider
+const oidcStateTTL = 10 * time.Minute
+
+// userNameChars are the characters kept from the name given by a provider
+var userNameChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// OIDCProviders returns the names of the identity providers users can log in with
+func (s *Service) OIDCProviders() ([]string, error) {
+	providers, err := oidc.Providers()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(providers))
+	for i, provider := range providers {
+		names[i] = provider.Name
+	}
+
+	return names, nil
+}
+
+// OIDCLogin starts a login at the identity provider and returns the url the user is sent to.
+// The state, PKCE verifier and nonce of the login are kept until the provider calls back
+func (s *Service) OIDCLogin(providerName string) (string, error) {
+	provider, err := oidc.Provider(providerName)
+	if err != nil {
+		return "", err
+	}
+
+	var state, verifier, nonce string
+	for _, value := range []*string{&state, &verifier, &nonce} {
+		if *value, err = secureDomain.GenerateToken(32); err != nil {
+			return "", err
+		}
+	}
+
+	authURL, err := s.OIDC.AuthCodeURL(provider, state, nonce, verifier)
+	if err != nil {
+		return "", providerError(provider.Name, err)
+	}
+
+	pending := secureDomain.OIDCState{Provider: provider.Name, CodeVerifier: verifier, Nonce: nonce}
+	if err = s.OIDCRepository.Save(secureDomain.HashToken(state), pending, oidcStateTTL); err != nil {
+		return "", err
+	}
+
+	return authURL, nil
+}
+
+// OIDCCallback finishes a login at the identity provider. The identity logs in as the user it is linked to,
+// it is linked to the user owning its email when the provider verified that email, otherwise a new user is created
+func (s *Service) OIDCCallback(providerName string, state string, code string, client secureDomain.ClientInfo) (*userDomain.SecurityAuthenticatedUser, *userDomain.MFAChallenge, error) {
+	pending, err := s.OIDCRepository.Consume(secureDomain.HashToken(state))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if pending.Provider != providerName {
+		return nil, nil, fiber.NewError(fiber.StatusUnauthorized, "invalid or expired login state")
+	}
+
+	provider, err := oidc.Provider(providerName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	identity, err := s.OIDC.Authenticate(provider, code, pending.CodeVerifier, pending.Nonce)
+	if err != nil {
+		return nil, nil, providerError(provider.Name, err)
+	}
+
+	userRole, err := s.identityUser(identity, client)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if userRole.VerifiedAt == nil {
+		return nil, nil, fiber.NewError(fiber.StatusForbidden, "email is not verified")
+	}
+
+	if userRole.MFAEnabledAt != nil || userRole.Role.RequireMFA {
+		challenge, err := s.mfaChallenge(userRole)
+		return nil, challenge, err
+	}
+
+	authDataUser, err := s.openSession(userRole, client)
+	return authDataUser, nil, err
+}
+
+// identityUser returns the user of the identity, linking or creating it on the first login with the provider
+func (s *Service) identityUser(identity *secureDomain.OIDCIdentity, client secureDomain.ClientInfo) (*userDomain.UserRole, error) {
+	linked, err := s.IdentityRepository.GetBySubject(identity.Provider, identity.Subject)
+	if err == nil {
+		return s.UserRepository.GetWithRole(linked.UserID)
+	}
+
+	if fiberErr, ok := err.(*fiber.Error); !ok || fiberErr.Code != fiber.StatusNotFound {
+		return nil, err
+	}
+
+	if identity.Email == "" {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "identity provider did not share an email")
+	}
+
+	newIdentity := &userDomain.Identity{Provider: identity.Provider, Subject: identity.Subject, Email: identity.Email}
+
+	existing, err := s.UserRepository.GetWithRoleByMap(map[string]interface{}{"email": identity.Email})
+	if err == nil {
+		// an unverified email at the provider proves nothing, linking it would hand the account over
+		if !identity.EmailVerified {
+			return nil, fiber.NewError(fiber.StatusConflict, "an account already uses this email")
+		}
+
+		newIdentity.UserID = existing.ID.String()
+		if _, err = s.IdentityRepository.Create(newIdentity); err != nil {
+			return nil, err
+		}
+
+		services.Audit(s.Audit, nil, auditDomain.Event{
+			Action:     auditDomain.ActionIdentityLink,
+			ActorID:    existing.ID.String(),
+			TargetType: auditDomain.TargetUser,
+			TargetID:   existing.ID.String(),
+			IP:         client.IP,
+			UserAgent:  client.UserAgent,
+			Changes:    auditDomain.Changes{"provider": {After: identity.Provider}, "subject": {After: identity.Subject}},
+		})
+
+		return existing, nil
+	}
+
+	if fiberErr, ok := err.(*fiber.Error); !ok || fiberErr.Code != fiber.StatusNotFound {
+		return nil, err
+	}
+
+	role, err := s.registrationRole("")
+	if err != nil {
+		return nil, err
+	}
+
+	userName, err := s.availableUserName(identity)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &userDomain.User{UserName: userName, Email: identity.Email, RoleID: role.ID.String()}
+	if identity.EmailVerified {
+		verifiedAt := time.Now()
+		user.VerifiedAt = &verifiedAt
+	}
+
+	createdUser, err := s.IdentityRepository.CreateWithUser(user, newIdentity)
+	if err != nil {
+		return nil, err
+	}
+
+	// a provider not vouching for the email gets the usual verification mail
+	if createdUser.VerifiedAt == nil {
+		if err = s.sendVerification(createdUser); err != nil {
+			log.Printf("failed sending verification mail: %s", err)
+		}
+	}
+
+	return s.UserRepository.GetWithRole(createdUser.ID.String())
+}
+
+// availableUserName derives a user name from the identity, suffixed when it is already taken
+func (s *Service) availableUserName(identity *secureDomain.OIDCIdentity) (string, error) {
+	base := identity.PreferredUsername
+	if base == "" {
+		base = strings.SplitN(identity.Email, "@", 2)[0]
+	}
+
+	base = userNameChars.ReplaceAllString(base, "")
+	if base == "" {
+		base = "user"
+	}
+
+	userName := base
+	for attempt := 0; attempt < 5; attempt++ {
+		taken, err := s.UserRepository.GetOneByMap(map[string]interface{}{"user_name": userName})
+		if err != nil {
+			return "", err
+		}
+
+		if taken.ID == uuid.Nil {
+			return userName, nil
+		}
+
+		userName = base + "-" + uuid.New().String()[:8]
+	}
+
+	return "", fiber.NewError(fiber.StatusConflict, "could not find a free user name")
+}
+
+// providerError hides the failures of a provider behind a bad gateway, a rejected ID token is unauthorized
+func providerError(providerName string, err error) error {
+	if _, ok := err.(*fiber.Error); ok {
+		return err
+	}
+
+	if err == oidc.ErrInvalidIDToken {
+		return fiber.NewError(fiber.StatusUnauthorized, "invalid id token")
+	}
+
+	log.Printf("identity provider %s failed: %s", providerName, err)
+	return fiber.NewError(fiber.StatusBadGateway, "identity provider unavailable")
+}
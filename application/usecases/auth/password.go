@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"fmt"
+	"time"
+
+	mailDomain "hexagonal-fiber/domain/mail"
+	secureDomain "hexagonal-fiber/domain/security"
+	userDomain "hexagonal-fiber/domain/user"
+
+	mssgConst "hexagonal-fiber/utils/constant/message"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+)
+
+// ForgotPassword mails a single use reset token to the user, limited per email address
+func (s *Service) ForgotPassword(email string) error {
+	viper.SetConfigFile("config.json")
+	if err := viper.ReadInConfig(); err != nil {
+		return err
+	}
+
+	window := time.Duration(viper.GetInt("Mail.ResendWindowMinute")) * time.Minute
+	// one mailbox gets one limit whatever the casing typed
+	count, err := s.LimiterRepository.Hit("reset:forgot:"+normalizeEmail(email), window)
+	if err != nil {
+		return err
+	}
+
+	if count > viper.GetInt64("Mail.ResendLimit") {
+		return fiber.NewError(fiber.StatusTooManyRequests, mssgConst.StatusTooManyRequests)
+	}
+
+	user, err := s.UserRepository.GetByEmail(email)
+	if err != nil {
+		return err
+	}
+
+	// unknown emails are answered the same way to avoid leaking accounts
+	if user.ID == uuid.Nil {
+		return nil
+	}
+
+	resetToken, err := secureDomain.GenerateToken(32)
+	if err != nil {
+		return err
+	}
+
+	ttl := time.Duration(viper.GetInt("Secure.ResetTokenTimeMinute")) * time.Minute
+	if err = s.ResetRepository.Save(user.ID.String(), secureDomain.HashToken(resetToken), ttl); err != nil {
+		return err
+	}
+
+	return s.Mailer.Send(mailDomain.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nuse the token below to reset your password within %d minutes, it can only be used once.\n\n%s\n\nIf you did not ask for a reset you can ignore this email.\n",
+			user.UserName, int(ttl.Minutes()), resetToken),
+	})
+}
+
+// ResetPassword consumes a reset token, sets the new password and revokes every session and token of the user
+func (s *Service) ResetPassword(request userDomain.ResetPasswordRequest) error {
+	userID, err := s.ResetRepository.Consume(secureDomain.HashToken(request.Token))
+	if err != nil {
+		return err
+	}
+
+	hash, err := s.Passwords.Hash(request.Password)
+	if err != nil {
+		return err
+	}
+
+	if _, err = s.UserRepository.Update(userID, &userDomain.User{HashPassword: hash}); err != nil {
+		return err
+	}
+
+	return s.LogoutAll(userID)
+}
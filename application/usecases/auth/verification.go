@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"time"
+
+	"hexagonal-fiber/application/security/jwt"
+	"hexagonal-fiber/application/services"
+
+	userDomain "hexagonal-fiber/domain/user"
+
+	mssgConst "hexagonal-fiber/utils/constant/message"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+)
+
+// VerifyEmail marks the user of a verification token as verified
+func (s *Service) VerifyEmail(token string) (*userDomain.User, error) {
+	claims, err := jwt.GetClaimsAndVerifyToken(token, jwt.Verify)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.UserRepository.GetByID(claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// the link is bound to the email it was sent to
+	if user.Email != claims.Subject {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "invalid token")
+	}
+
+	if user.VerifiedAt != nil {
+		return user, nil
+	}
+
+	nowTime := time.Now()
+	return s.UserRepository.Update(user.ID.String(), &userDomain.User{VerifiedAt: &nowTime})
+}
+
+// ResendVerification sends a new verification email, limited per email address
+func (s *Service) ResendVerification(email string) error {
+	viper.SetConfigFile("config.json")
+	if err := viper.ReadInConfig(); err != nil {
+		return err
+	}
+
+	window := time.Duration(viper.GetInt("Mail.ResendWindowMinute")) * time.Minute
+	// one mailbox gets one limit whatever the casing typed
+	count, err := s.LimiterRepository.Hit("verify:resend:"+normalizeEmail(email), window)
+	if err != nil {
+		return err
+	}
+
+	if count > viper.GetInt64("Mail.ResendLimit") {
+		return fiber.NewError(fiber.StatusTooManyRequests, mssgConst.StatusTooManyRequests)
+	}
+
+	user, err := s.UserRepository.GetByEmail(email)
+	if err != nil {
+		return err
+	}
+
+	// unknown or already verified emails are answered the same way to avoid leaking accounts
+	if user.ID == uuid.Nil || user.VerifiedAt != nil {
+		return nil
+	}
+
+	return s.sendVerification(user)
+}
+
+// sendVerification mails a signed and expiring verification link to the user
+func (s *Service) sendVerification(user *userDomain.User) error {
+	return services.SendVerification(s.Mailer, user)
+}
@@ -1,10 +1,16 @@
 package comment
 
 import (
+	"hexagonal-fiber/application/security/policy"
+	"hexagonal-fiber/application/services"
+	auditDomain "hexagonal-fiber/domain/audit"
 	commentDomain "hexagonal-fiber/domain/comment"
+	secureDomain "hexagonal-fiber/domain/security"
 
 	commentRepository "hexagonal-fiber/infrastructure/repository/postgres/comment"
 	photoRepository "hexagonal-fiber/infrastructure/repository/postgres/photo"
+
+	"github.com/gofiber/fiber/v2"
 )
 
 // Service is a struct that contains the repository implementation for comment use case
@@ -12,6 +18,7 @@ type Service struct {
 	CommentTesting    commentRepository.CommentTesting
 	CommentRepository commentRepository.Repository
 	PhotoRepository   photoRepository.Repository
+	Audit             auditDomain.Recorder
 }
 
 // GetAll is a function that returns all comments
@@ -52,14 +59,9 @@ func (s *Service) UserGetAll(userId string, page int, limit int) (*commentDomain
 	}, nil
 }
 
-// GetByID is a function that returns a comment by id
-func (s *Service) GetByID(id string) (*commentDomain.Comment, error) {
-	return s.CommentRepository.GetByID(id)
-}
-
-// UserGetByID is a function that returns a comment by id
-func (s *Service) UserGetByID(id string, userId string) (*commentDomain.Comment, error) {
-	return s.CommentRepository.UserGetByID(id, userId)
+// GetByID is a function that returns a comment by id the actor is allowed to read
+func (s *Service) GetByID(actor *secureDomain.Claims, id string) (*commentDomain.Comment, error) {
+	return s.authorized(actor, policy.Read, id)
 }
 
 // Create is a function that creates a comment
@@ -80,19 +82,102 @@ func (s *Service) GetByMap(commentMap map[string]interface{}) (*commentDomain.Co
 	return s.CommentRepository.GetOneByMap(commentMap)
 }
 
-// Delete is a function that deletes a comment by id
-func (s *Service) Delete(id string) (err error) {
-	return s.CommentRepository.Delete(id)
+// Delete is a function that deletes a comment by id when the actor owns it or may delete any comment
+func (s *Service) Delete(actor *secureDomain.Claims, id string) (err error) {
+	before, err := s.authorized(actor, policy.Delete, id)
+	if err != nil {
+		return
+	}
+
+	if err = s.CommentRepository.Delete(id); err != nil {
+		return
+	}
+
+	services.Audit(s.Audit, actor, auditDomain.Event{
+		Action:     auditDomain.ActionCommentDelete,
+		TargetType: auditDomain.TargetComment,
+		TargetID:   id,
+		Changes:    auditDomain.Diff(before, nil),
+	})
+
+	return
 }
 
-// Update is a function that updates a comment by id
-func (s *Service) Update(id string, updateComment commentDomain.UpdateComment) (*commentDomain.Comment, error) {
-	comment := updateComment.ToDomainMapper()
-	return s.CommentRepository.Update(id, &comment)
+// Trash is a function that returns the deleted comments of a user when the actor is that user or may restore any comment
+func (s *Service) Trash(actor *secureDomain.Claims, userID string, page int, limit int) (*commentDomain.PaginationComment, error) {
+	if err := policy.Comment.Authorize(actor, policy.Restore, userID); err != nil {
+		return nil, err
+	}
+
+	return s.CommentRepository.UserGetTrash(userID, page, limit)
 }
 
-// Update is a function that updates a comment by id
-func (s *Service) UserUpdate(id string, userId string, updateComment commentDomain.UpdateComment) (*commentDomain.Comment, error) {
+// Restore is a function that brings a deleted comment back when the actor owns it or may restore any comment
+func (s *Service) Restore(actor *secureDomain.Claims, id string) (*commentDomain.Comment, error) {
+	deleted, err := s.CommentRepository.GetDeletedByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = policy.Comment.Authorize(actor, policy.Restore, deleted.UserID); err != nil {
+		return nil, err
+	}
+
+	// a comment deleted along with its photo comes back with the photo only
+	if _, err = s.PhotoRepository.GetByID(deleted.PhotoID); err != nil {
+		if fiberErr, ok := err.(*fiber.Error); ok && fiberErr.Code == fiber.StatusNotFound {
+			return nil, fiber.NewError(fiber.StatusConflict, "the photo of the comment is deleted, restore the photo instead")
+		}
+		return nil, err
+	}
+
+	if err = s.CommentRepository.Restore(id); err != nil {
+		return nil, err
+	}
+
+	services.Audit(s.Audit, actor, auditDomain.Event{
+		Action:     auditDomain.ActionCommentRestore,
+		TargetType: auditDomain.TargetComment,
+		TargetID:   id,
+		Changes:    auditDomain.Changes{"deleted_at": {Before: deleted.DeletedAt.Time}},
+	})
+
+	return s.CommentRepository.GetByID(id)
+}
+
+// Update is a function that updates a comment by id when the actor owns it or may update any comment
+func (s *Service) Update(actor *secureDomain.Claims, id string, updateComment commentDomain.UpdateComment) (*commentDomain.Comment, error) {
+	before, err := s.authorized(actor, policy.Update, id)
+	if err != nil {
+		return nil, err
+	}
+
 	comment := updateComment.ToDomainMapper()
-	return s.CommentRepository.UserUpdate(id, userId, &comment)
+	updated, err := s.CommentRepository.Update(id, &comment)
+	if err != nil {
+		return nil, err
+	}
+
+	services.AuditEdit(s.Audit, actor, before.UserID, auditDomain.Event{
+		Action:     auditDomain.ActionCommentUpdate,
+		TargetType: auditDomain.TargetComment,
+		TargetID:   id,
+		Changes:    auditDomain.Diff(before, updated),
+	})
+
+	return updated, nil
+}
+
+// authorized fetches the comment first so a missing one answers not found, then checks the actor against its owner
+func (s *Service) authorized(actor *secureDomain.Claims, action string, id string) (*commentDomain.Comment, error) {
+	comment, err := s.CommentRepository.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = policy.Comment.Authorize(actor, action, comment.UserID); err != nil {
+		return nil, err
+	}
+
+	return comment, nil
 }
@@ -0,0 +1,166 @@
+// Package oauth provides the use case for the token introspection and revocation of other services
+package oauth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"hexagonal-fiber/application/security/jwt"
+	"hexagonal-fiber/application/services"
+
+	secureDomain "hexagonal-fiber/domain/security"
+	sessionRepository "hexagonal-fiber/infrastructure/repository/redis/session"
+	tokenRepository "hexagonal-fiber/infrastructure/repository/redis/token"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/spf13/viper"
+)
+
+// Service is a struct that contains the repository implementation for oauth use case
+type Service struct {
+	TokenRepository   tokenRepository.Repository
+	SessionRepository sessionRepository.Repository
+	TokenVersions     services.TokenVersions
+}
+
+// tokenTypes maps the oauth token type hints to the token types of the application
+var tokenTypes = map[string]string{
+	secureDomain.TokenTypeHintAccess:  jwt.Access,
+	secureDomain.TokenTypeHintRefresh: jwt.Refresh,
+}
+
+// AuthenticateClient checks the credentials of the calling service against the configured clients
+func (s *Service) AuthenticateClient(clientID string, clientSecret string) error {
+	viper.SetConfigFile("config.json")
+	if err := viper.ReadInConfig(); err != nil {
+		return err
+	}
+
+	var clients []secureDomain.OAuthClient
+	if err := viper.UnmarshalKey("Secure.OAuthClients", &clients); err != nil {
+		return err
+	}
+
+	secretHash := sha256.Sum256([]byte(clientSecret))
+	presented := hex.EncodeToString(secretHash[:])
+
+	for _, client := range clients {
+		if client.ClientID == "" || client.ClientID != clientID {
+			continue
+		}
+
+		expected := strings.ToLower(client.ClientSecretSHA256)
+		if subtle.ConstantTimeCompare([]byte(presented), []byte(expected)) == 1 {
+			return nil
+		}
+	}
+
+	return fiber.NewError(fiber.StatusUnauthorized, "invalid_client")
+}
+
+// Introspect returns the state of a token. Expired, malformed, rotated or revoked tokens are all
+// reported the same way, as inactive
+func (s *Service) Introspect(request secureDomain.TokenRequest) (*secureDomain.Introspection, error) {
+	hint, claims := parse(request)
+	if claims == nil {
+		return &secureDomain.Introspection{Active: false}, nil
+	}
+
+	active, err := s.isActive(hint, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	if !active {
+		return &secureDomain.Introspection{Active: false}, nil
+	}
+
+	return &secureDomain.Introspection{
+		Active:    true,
+		Scope:     strings.Join(claims.Permissions, " "),
+		TokenType: hint,
+		Subject:   claims.UserID,
+		Role:      claims.Role,
+		SessionID: claims.SessionID,
+		TokenID:   claims.Id,
+		ExpiresAt: claims.ExpiresAt,
+		IssuedAt:  claims.IssuedAt,
+	}, nil
+}
+
+// Revoke revokes a token. A refresh token takes its whole session down with it, as the access tokens
+// of the session come from the same grant, while an access token is denied alone.
+// Unknown and invalid tokens are not an error, RFC 7009 section 2.2
+func (s *Service) Revoke(request secureDomain.TokenRequest) error {
+	hint, claims := parse(request)
+	if claims == nil {
+		return nil
+	}
+
+	if hint == secureDomain.TokenTypeHintRefresh {
+		if err := s.TokenRepository.RevokeFamily(claims.SessionID); err != nil {
+			return err
+		}
+
+		return s.SessionRepository.Delete(claims.UserID, claims.SessionID)
+	}
+
+	return s.TokenRepository.RevokeToken(claims.Id, time.Unix(claims.ExpiresAt, 0))
+}
+
+// isActive checks the server side revocation state of a verified token
+func (s *Service) isActive(hint string, claims *secureDomain.Claims) (bool, error) {
+	currentTokenID, err := s.TokenRepository.GetFamily(claims.SessionID)
+	if err != nil {
+		return false, err
+	}
+
+	if currentTokenID == "" {
+		return false, nil
+	}
+
+	version, err := s.TokenVersions.Current(claims.UserID)
+	if fiberErr, ok := err.(*fiber.Error); ok && fiberErr.Code == fiber.StatusNotFound {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	if version != claims.Version {
+		return false, nil
+	}
+
+	// only the latest refresh token of a session is usable, older ones were rotated away
+	if hint == secureDomain.TokenTypeHintRefresh {
+		return currentTokenID == claims.Id, nil
+	}
+
+	revoked, err := s.TokenRepository.IsTokenRevoked(claims.Id)
+	if err != nil {
+		return false, err
+	}
+
+	return !revoked, nil
+}
+
+// parse verifies the token as the hinted type first then as the other ones, RFC 7662 section 2.1
+func parse(request secureDomain.TokenRequest) (string, *secureDomain.Claims) {
+	hints := []string{secureDomain.TokenTypeHintAccess, secureDomain.TokenTypeHintRefresh}
+	if request.TokenTypeHint == secureDomain.TokenTypeHintRefresh {
+		hints = []string{secureDomain.TokenTypeHintRefresh, secureDomain.TokenTypeHintAccess}
+	}
+
+	for _, hint := range hints {
+		claims, err := jwt.GetClaimsAndVerifyToken(request.Token, tokenTypes[hint])
+		if err == nil {
+			return hint, claims
+		}
+	}
+
+	return "", nil
+}
@@ -0,0 +1,209 @@
+package photo
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"hexagonal-fiber/application/imaging"
+	photoDomain "hexagonal-fiber/domain/photo"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const (
+	duplicateOff    = "off"
+	duplicateWarn   = "warn"
+	duplicateReject = "reject"
+)
+
+type duplicateConfig struct {
+	// Mode is what happens to an upload near identical to a photo of the same user: off, warn or reject
+	Mode string
+	// MaxDistance is how many bits the perceptual hashes of near identical photos differ by at most
+	MaxDistance int
+}
+
+// Hashed is a struct that contains the outcome of hashing the photos uploaded before the perceptual hashes
+type Hashed struct {
+	Photos int
+	Failed int
+}
+
+// nearDuplicates returns the photos of the user an upload is a near duplicate of, a warning in the warn mode and a conflict in the reject mode
+func (s *Service) nearDuplicates(userID string, hash int64, cfg *config) ([]string, error) {
+	if cfg.Duplicates.Mode == duplicateOff {
+		return nil, nil
+	}
+
+	ids, err := s.PhotoRepository.UserNearDuplicates(userID, hash, cfg.Duplicates.MaxDistance)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ids) > 0 && cfg.Duplicates.Mode == duplicateReject {
+		return nil, fiber.NewError(fiber.StatusConflict, fmt.Sprintf("photo is a near duplicate of photo %s", ids[0]))
+	}
+
+	return ids, nil
+}
+
+// Duplicates is a function that returns the groups of near identical photos across every user as they were grouped
+// by the photos hash command, the groups posted by the most users come first then the largest ones
+func (s *Service) Duplicates(page int, limit int) (*photoDomain.PaginationDuplicate, error) {
+	total, err := s.PhotoRepository.CountDuplicateGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	offset := (page - 1) * limit
+	duplicates, err := s.PhotoRepository.GetDuplicates(offset+1, offset+limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, duplicate := range duplicates {
+		ids = append(ids, duplicate.PhotoID)
+	}
+
+	photos := map[string]photoDomain.Photo{}
+	if len(ids) > 0 {
+		found, err := s.PhotoRepository.GetByIDs(ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, photo := range found {
+			photos[photo.ID.String()] = photo
+		}
+	}
+
+	// the photos deleted since the grouping are left out of their group
+	data := make([]photoDomain.DuplicateCluster, 0, limit)
+	for i, duplicate := range duplicates {
+		if i == 0 || duplicate.Rank != duplicates[i-1].Rank {
+			data = append(data, photoDomain.DuplicateCluster{Users: duplicate.Users})
+		}
+		if photo, ok := photos[duplicate.PhotoID]; ok {
+			group := &data[len(data)-1]
+			group.Photos = append(group.Photos, photo)
+		}
+	}
+
+	numPages := (total + int64(limit) - 1) / int64(limit)
+	var nextCursor, prevCursor uint
+	if page < int(numPages) {
+		nextCursor = uint(page + 1)
+	}
+	if page > 1 {
+		prevCursor = uint(page - 1)
+	}
+
+	return &photoDomain.PaginationDuplicate{
+		Data:       &data,
+		Total:      total,
+		Limit:      int64(limit),
+		Current:    int64(page),
+		NextCursor: nextCursor,
+		PrevCursor: prevCursor,
+		NumPages:   numPages,
+	}, nil
+}
+
+// GroupDuplicates is a function that groups the near identical photos across every user and stores the groups ranked
+// for the duplicates report, the groups posted by the most users first then the largest ones. It returns how many groups were found
+func (s *Service) GroupDuplicates() (int, error) {
+	cfg, err := readConfig()
+	if err != nil {
+		return 0, err
+	}
+
+	hashed, err := s.PhotoRepository.GetHashed()
+	if err != nil {
+		return 0, err
+	}
+
+	hashes := make([]uint64, len(hashed))
+	for i, photo := range hashed {
+		hashes[i] = uint64(photo.PerceptualHash)
+	}
+
+	type cluster struct {
+		ids   []string
+		users int
+	}
+
+	clusters := make([]cluster, 0)
+	for _, indexes := range imaging.Clusters(hashes, cfg.Duplicates.MaxDistance) {
+		users := map[string]bool{}
+		ids := make([]string, len(indexes))
+		for i, index := range indexes {
+			ids[i] = hashed[index].ID
+			users[hashed[index].UserID] = true
+		}
+		clusters = append(clusters, cluster{ids: ids, users: len(users)})
+	}
+
+	sort.SliceStable(clusters, func(i, j int) bool {
+		if clusters[i].users != clusters[j].users {
+			return clusters[i].users > clusters[j].users
+		}
+		return len(clusters[i].ids) > len(clusters[j].ids)
+	})
+
+	var duplicates []photoDomain.Duplicate
+	for rank, c := range clusters {
+		for _, id := range c.ids {
+			duplicates = append(duplicates, photoDomain.Duplicate{PhotoID: id, Rank: rank + 1, Users: c.users})
+		}
+	}
+
+	if err = s.PhotoRepository.ReplaceDuplicates(duplicates); err != nil {
+		return 0, err
+	}
+
+	return len(clusters), nil
+}
+
+// HashMissing is a function that computes the perceptual hash of the uploaded photos which have none,
+// a photo failing is logged and counted so the others still get theirs
+func (s *Service) HashMissing() (*Hashed, error) {
+	cfg, err := readConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	hashed := &Hashed{}
+	afterID := uuid.Nil.String()
+
+	for {
+		photos, err := s.PhotoRepository.GetUnhashed(afterID, regenerateBatch)
+		if err != nil {
+			return hashed, err
+		}
+
+		for i := range photos {
+			if err = s.hash(&photos[i], cfg); err != nil {
+				log.Printf("failed hashing photo %s: %s", photos[i].ID, err)
+				hashed.Failed++
+				continue
+			}
+			hashed.Photos++
+		}
+
+		if len(photos) < regenerateBatch {
+			return hashed, nil
+		}
+		afterID = photos[len(photos)-1].ID.String()
+	}
+}
+
+func (s *Service) hash(photo *photoDomain.Photo, cfg *config) error {
+	src, err := s.decodeStored(photo, cfg)
+	if err != nil {
+		return err
+	}
+
+	return s.PhotoRepository.SetPerceptualHash(photo.ID.String(), int64(imaging.DHash(src)))
+}
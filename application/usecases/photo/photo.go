@@ -1,15 +1,68 @@
 package photo
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	// image decoders of the content types a photo can be uploaded as, the jpeg one comes with the encoder of the variants
+	_ "image/gif"
+	_ "image/png"
+
+	"hexagonal-fiber/application/imaging"
+	"hexagonal-fiber/application/security/policy"
+	"hexagonal-fiber/application/services"
+	auditDomain "hexagonal-fiber/domain/audit"
 	photoDomain "hexagonal-fiber/domain/photo"
+	secureDomain "hexagonal-fiber/domain/security"
+	storageDomain "hexagonal-fiber/domain/storage"
 
 	photoRepository "hexagonal-fiber/infrastructure/repository/postgres/photo"
+	userRepository "hexagonal-fiber/infrastructure/repository/postgres/user"
+	mssgConst "hexagonal-fiber/utils/constant/message"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
 )
 
 // Service is a struct that contains the repository implementation for photo use case
 type Service struct {
 	PhotoTesting    photoRepository.PhotoTesting
 	PhotoRepository photoRepository.Repository
+	UserRepository  userRepository.Repository
+	Storage         storageDomain.Storage
+	Audit           auditDomain.Recorder
+}
+
+const (
+	// regenerateBatch is how many photos are loaded at once while regenerating the variants
+	regenerateBatch = 100
+	// orientedQuality is the JPEG quality an upload turned upright is encoded again with
+	orientedQuality = 92
+)
+
+type config struct {
+	ServerURL          string
+	MaxUploadMB        int
+	MaxMegapixels      int
+	DownloadTimeMinute int
+	Variants           variantConfig
+	Duplicates         duplicateConfig
+}
+
+type variantConfig struct {
+	Quality int
+	Sizes   []photoDomain.VariantSize
 }
 
 // GetAll is a function that returns all photos
@@ -55,14 +108,9 @@ func (s *Service) GetWithComments(id string, page int, limit int) (*photoDomain.
 	return s.PhotoRepository.GetWithComments(id, page, limit)
 }
 
-// GetByID is a function that returns a photo with comments by id
-func (s *Service) GetByID(id string) (*photoDomain.Photo, error) {
-	return s.PhotoRepository.GetByID(id)
-}
-
-// UserGetByID is a function that returns a photo by id
-func (s *Service) UserGetByID(id string, userId string) (*photoDomain.Photo, error) {
-	return s.PhotoRepository.UserGetByID(id, userId)
+// GetByID is a function that returns a Photo by id the actor is allowed to read
+func (s *Service) GetByID(actor *secureDomain.Claims, id string) (*photoDomain.Photo, error) {
+	return s.authorized(actor, policy.Read, id)
 }
 
 // Create is a function that creates a photo
@@ -73,24 +121,479 @@ func (s *Service) Create(photo *photoDomain.NewPhoto) (*photoDomain.Photo, error
 	return s.PhotoRepository.Create(photoModel)
 }
 
+// Upload is a function that stores the uploaded image of a new photo and creates the photo serving it,
+// the content type is sniffed from the bytes as the one declared by the client is not trusted
+func (s *Service) Upload(newPhoto *photoDomain.NewPhoto, file io.Reader, size int64) (*photoDomain.Photo, error) {
+	cfg, err := readConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	maxSize := int64(cfg.MaxUploadMB) << 20
+	tooLarge := fiber.NewError(fiber.StatusRequestEntityTooLarge, fmt.Sprintf("photo is larger than %d MB", cfg.MaxUploadMB))
+	if size > maxSize {
+		return nil, tooLarge
+	}
+
+	content, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, mssgConst.StatusBadRequest)
+	}
+	if int64(len(content)) > maxSize {
+		return nil, tooLarge
+	}
+
+	contentType := http.DetectContentType(content)
+	extension, ok := photoDomain.ContentTypes[contentType]
+	if !ok {
+		return nil, fiber.NewError(fiber.StatusUnsupportedMediaType, "photo must be a jpeg, png or gif image")
+	}
+
+	dimensions, _, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "photo is not a readable image")
+	}
+
+	// the decoded image takes four bytes a pixel whatever the size of the file
+	if dimensions.Width*dimensions.Height > cfg.MaxMegapixels*1000000 {
+		return nil, fiber.NewError(fiber.StatusRequestEntityTooLarge, fmt.Sprintf("photo is larger than %d megapixels", cfg.MaxMegapixels))
+	}
+
+	var exif *imaging.EXIF
+	if contentType == "image/jpeg" {
+		if content, exif, err = sanitizeJPEG(content); err != nil {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "photo is not a readable image")
+		}
+
+		if dimensions, _, err = image.DecodeConfig(bytes.NewReader(content)); err != nil {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "photo is not a readable image")
+		}
+	}
+
+	// the image is decoded once for its perceptual hash and its variants
+	src, _, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "photo is not a readable image")
+	}
+	hash := int64(imaging.DHash(src))
+
+	checksum := sha256.Sum256(content)
+
+	photo := newPhoto.ToDomainMapper()
+	photo.ID = uuid.New()
+	photo.ObjectKey = "photos/" + photo.UserID + "/" + photo.ID.String() + extension
+	photo.PhotoUrl = cfg.ServerURL + "/v1/photos/" + photo.ID.String() + "/download"
+	photo.ContentType = contentType
+	photo.Size = int64(len(content))
+	photo.Width = dimensions.Width
+	photo.Height = dimensions.Height
+	photo.Checksum = hex.EncodeToString(checksum[:])
+	photo.PerceptualHash = &hash
+	if err = s.applyEXIF(photo, exif); err != nil {
+		return nil, err
+	}
+
+	if photo.DuplicateOf, err = s.nearDuplicates(photo.UserID, hash, cfg); err != nil {
+		return nil, err
+	}
+
+	if err = s.Storage.Put(photo.ObjectKey, bytes.NewReader(content), photo.Size, contentType); err != nil {
+		log.Printf("failed storing photo %s: %s", photo.ObjectKey, err)
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	created, err := s.PhotoRepository.Create(photo)
+	if err != nil {
+		services.RemoveObjects(s.Storage, []string{photo.ObjectKey})
+		return nil, err
+	}
+
+	stored := *created
+	go s.generateUploaded(&stored, src, cfg)
+
+	return created, nil
+}
+
+// sanitizeJPEG reads the EXIF of an uploaded JPEG then strips every metadata from the file, an image which is not upright
+// is turned so and encoded again as nothing is left to tell how to turn it. An unreadable EXIF is ignored like a missing one
+func sanitizeJPEG(content []byte) ([]byte, *imaging.EXIF, error) {
+	exif, err := imaging.ReadEXIF(content)
+	if err != nil {
+		log.Printf("ignoring unreadable exif of an upload: %s", err)
+		exif = &imaging.EXIF{Orientation: 1}
+	}
+
+	stripped, err := imaging.StripJPEG(content)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if exif.Orientation == 1 {
+		return stripped, exif, nil
+	}
+
+	src, err := jpeg.Decode(bytes.NewReader(stripped))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var oriented bytes.Buffer
+	if err = jpeg.Encode(&oriented, imaging.Orient(src, exif.Orientation), &jpeg.Options{Quality: orientedQuality}); err != nil {
+		return nil, nil, err
+	}
+
+	return oriented.Bytes(), exif, nil
+}
+
+// applyEXIF records the EXIF read from the upload on the photo, the location only when the owner keeps it public
+func (s *Service) applyEXIF(photo *photoDomain.Photo, exif *imaging.EXIF) error {
+	if exif == nil {
+		return nil
+	}
+
+	photo.CameraMake = exif.Make
+	photo.CameraModel = exif.Model
+	photo.Lens = exif.Lens
+	photo.TakenAt = exif.TakenAt
+	photo.Orientation = exif.Orientation
+
+	if exif.Latitude == nil {
+		return nil
+	}
+
+	owner, err := s.UserRepository.GetByID(photo.UserID)
+	if err != nil {
+		return err
+	}
+
+	if owner.KeepLocationPublic {
+		photo.Latitude, photo.Longitude = exif.Latitude, exif.Longitude
+	}
+
+	return nil
+}
+
+// GenerateVariants is a function that generates the configured variants a photo is missing, or all of them when forced,
+// from its stored image and returns how many were generated
+func (s *Service) GenerateVariants(photo *photoDomain.Photo, force bool) (int, error) {
+	cfg, err := readConfig()
+	if err != nil {
+		return 0, err
+	}
+
+	sizes := missingVariants(photo, cfg.Variants.Sizes, force)
+	if len(sizes) == 0 {
+		return 0, nil
+	}
+
+	src, err := s.decodeStored(photo, cfg)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.generateVariants(photo, src, sizes, cfg)
+}
+
+// decodeStored decodes the stored image of a photo, unless it is too large to be decoded safely
+func (s *Service) decodeStored(photo *photoDomain.Photo, cfg *config) (image.Image, error) {
+	if photo.Width*photo.Height > cfg.MaxMegapixels*1000000 {
+		return nil, fiber.NewError(fiber.StatusRequestEntityTooLarge, fmt.Sprintf("photo is larger than %d megapixels", cfg.MaxMegapixels))
+	}
+
+	object, err := s.Storage.Get(photo.ObjectKey)
+	if err != nil {
+		return nil, err
+	}
+	defer object.Body.Close()
+
+	src, _, err := image.Decode(object.Body)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusUnprocessableEntity, "photo is not a readable image")
+	}
+
+	return src, nil
+}
+
+// Regenerated is a struct that contains the outcome of a variant regeneration
+type Regenerated struct {
+	Photos   int
+	Variants int
+	Failed   int
+}
+
+// RegenerateVariants is a function that walks every uploaded photo to generate the variants it is missing, or all of them when forced,
+// a photo failing is logged and counted so the others still get theirs
+func (s *Service) RegenerateVariants(force bool) (*Regenerated, error) {
+	regenerated := &Regenerated{}
+	afterID := uuid.Nil.String()
+
+	for {
+		photos, err := s.PhotoRepository.GetUploaded(afterID, regenerateBatch)
+		if err != nil {
+			return regenerated, err
+		}
+
+		for i := range photos {
+			generated, err := s.GenerateVariants(&photos[i], force)
+			if err != nil {
+				log.Printf("failed generating the variants of photo %s: %s", photos[i].ID, err)
+				regenerated.Failed++
+				continue
+			}
+
+			if generated > 0 {
+				regenerated.Photos++
+				regenerated.Variants += generated
+			}
+		}
+
+		if len(photos) < regenerateBatch {
+			return regenerated, nil
+		}
+		afterID = photos[len(photos)-1].ID.String()
+	}
+}
+
+// generateUploaded generates the variants of a photo just uploaded from the image still in memory, failures are only logged
+// as the photo is served in full size until the toolbox regenerates them
+func (s *Service) generateUploaded(photo *photoDomain.Photo, src image.Image, cfg *config) {
+	if _, err := s.generateVariants(photo, src, cfg.Variants.Sizes, cfg); err != nil {
+		log.Printf("failed generating the variants of photo %s: %s", photo.ID, err)
+	}
+}
+
+// generateVariants downscales the image to each size, the variants are always JPEG so transparency is flattened on white
+func (s *Service) generateVariants(photo *photoDomain.Photo, src image.Image, sizes []photoDomain.VariantSize, cfg *config) (generated int, err error) {
+	for _, size := range sizes {
+		thumbnail := imaging.Thumbnail(src, size.Width, size.Height, size.Crop)
+
+		var encoded bytes.Buffer
+		if err = jpeg.Encode(&encoded, imaging.Flatten(thumbnail, color.White), &jpeg.Options{Quality: cfg.Variants.Quality}); err != nil {
+			return
+		}
+
+		checksum := sha256.Sum256(encoded.Bytes())
+		variant := &photoDomain.Variant{
+			PhotoID:   photo.ID.String(),
+			Name:      size.Name,
+			ObjectKey: "photos/" + photo.UserID + "/" + photo.ID.String() + "_" + size.Name + ".jpg",
+			URL:       cfg.ServerURL + "/v1/photos/" + photo.ID.String() + "/download?variant=" + url.QueryEscape(size.Name),
+			Width:     thumbnail.Bounds().Dx(),
+			Height:    thumbnail.Bounds().Dy(),
+			Size:      int64(encoded.Len()),
+			Checksum:  hex.EncodeToString(checksum[:]),
+		}
+
+		if err = s.Storage.Put(variant.ObjectKey, bytes.NewReader(encoded.Bytes()), variant.Size, "image/jpeg"); err != nil {
+			return
+		}
+
+		if err = s.PhotoRepository.SaveVariant(variant); err != nil {
+			return
+		}
+		generated++
+	}
+
+	return
+}
+
+// missingVariants lists the configured sizes the photo has no variant of yet, every size when forced
+func missingVariants(photo *photoDomain.Photo, sizes []photoDomain.VariantSize, force bool) []photoDomain.VariantSize {
+	if force {
+		return sizes
+	}
+
+	generated := map[string]bool{}
+	for _, variant := range photo.Variants {
+		generated[variant.Name] = true
+	}
+
+	var missing []photoDomain.VariantSize
+	for _, size := range sizes {
+		if !generated[size.Name] {
+			missing = append(missing, size)
+		}
+	}
+
+	return missing
+}
+
+// Download is a function that returns where the image of a photo the actor may read is served from,
+// the storage address when it has one, the stored object otherwise and the outside url of a linked photo.
+// A variant not generated yet is served by the original image
+func (s *Service) Download(actor *secureDomain.Claims, id string, variant string) (*photoDomain.Download, error) {
+	photo, err := s.authorized(actor, policy.Read, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if photo.ObjectKey == "" {
+		// links stored before their url was validated are not redirected to
+		if !photoDomain.IsLinkURL(photo.PhotoUrl) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "photo has no image to download")
+		}
+		return &photoDomain.Download{URL: photo.PhotoUrl}, nil
+	}
+
+	key, checksum := photo.ObjectKey, photo.Checksum
+	for _, generated := range photo.Variants {
+		if variant != "" && generated.Name == variant {
+			key, checksum = generated.ObjectKey, generated.Checksum
+		}
+	}
+
+	cfg, err := readConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	address, err := s.Storage.URL(key, time.Duration(cfg.DownloadTimeMinute)*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	if address != "" {
+		return &photoDomain.Download{URL: address}, nil
+	}
+
+	object, err := s.Storage.Get(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &photoDomain.Download{Object: object, Checksum: checksum}, nil
+}
+
 // GetByMap is a function that returns a photo by map
 func (s *Service) GetByMap(photoMap map[string]interface{}) (*photoDomain.Photo, error) {
 	return s.PhotoRepository.GetOneByMap(photoMap)
 }
 
-// Delete is a function that deletes a photo by id
-func (s *Service) Delete(id string) (err error) {
-	return s.PhotoRepository.Delete(id)
+// Delete is a function that deletes a Photo by id when the actor owns it or may delete any Photo
+func (s *Service) Delete(actor *secureDomain.Claims, id string) (err error) {
+	before, err := s.authorized(actor, policy.Delete, id)
+	if err != nil {
+		return
+	}
+
+	if err = s.PhotoRepository.Delete(id); err != nil {
+		return
+	}
+
+	services.Audit(s.Audit, actor, auditDomain.Event{
+		Action:     auditDomain.ActionPhotoDelete,
+		TargetType: auditDomain.TargetPhoto,
+		TargetID:   id,
+		Changes:    auditDomain.Diff(before, nil),
+	})
+
+	return
 }
 
-// Update is a function that updates a photo by id
-func (s *Service) Update(id string, updatePhoto photoDomain.UpdatePhoto) (*photoDomain.Photo, error) {
-	photo := updatePhoto.ToDomainMapper()
-	return s.PhotoRepository.Update(id, &photo)
+// Trash is a function that returns the deleted photos of a user when the actor is that user or may restore any Photo
+func (s *Service) Trash(actor *secureDomain.Claims, userID string, page int, limit int) (*photoDomain.PaginationPhoto, error) {
+	if err := policy.Photo.Authorize(actor, policy.Restore, userID); err != nil {
+		return nil, err
+	}
+
+	return s.PhotoRepository.UserGetTrash(userID, page, limit)
 }
 
-// Update is a function that updates a photo by id
-func (s *Service) UserUpdate(id string, userId string, updatePhoto photoDomain.UpdatePhoto) (*photoDomain.Photo, error) {
+// Restore is a function that brings a deleted Photo back when the actor owns it or may restore any Photo
+func (s *Service) Restore(actor *secureDomain.Claims, id string) (*photoDomain.Photo, error) {
+	deleted, err := s.PhotoRepository.GetDeletedByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = policy.Photo.Authorize(actor, policy.Restore, deleted.UserID); err != nil {
+		return nil, err
+	}
+
+	if err = s.PhotoRepository.Restore(deleted); err != nil {
+		return nil, err
+	}
+
+	services.Audit(s.Audit, actor, auditDomain.Event{
+		Action:     auditDomain.ActionPhotoRestore,
+		TargetType: auditDomain.TargetPhoto,
+		TargetID:   id,
+		Changes:    auditDomain.Changes{"deleted_at": {Before: deleted.DeletedAt.Time}},
+	})
+
+	return s.PhotoRepository.GetByID(id)
+}
+
+// Update is a function that updates a Photo by id when the actor owns it or may update any Photo
+func (s *Service) Update(actor *secureDomain.Claims, id string, updatePhoto photoDomain.UpdatePhoto) (*photoDomain.Photo, error) {
+	before, err := s.authorized(actor, policy.Update, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// the url of an uploaded photo is where the api serves its stored image, only linked photos may point elsewhere
+	if updatePhoto.PhotoUrl != nil && before.ObjectKey != "" {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "photo url of an uploaded photo cannot be changed")
+	}
+
 	photo := updatePhoto.ToDomainMapper()
-	return s.PhotoRepository.UserUpdate(id, userId, &photo)
+	updated, err := s.PhotoRepository.Update(id, &photo)
+	if err != nil {
+		return nil, err
+	}
+
+	services.AuditEdit(s.Audit, actor, before.UserID, auditDomain.Event{
+		Action:     auditDomain.ActionPhotoUpdate,
+		TargetType: auditDomain.TargetPhoto,
+		TargetID:   id,
+		Changes:    auditDomain.Diff(before, updated),
+	})
+
+	return updated, nil
+}
+
+// authorized fetches the Photo first so a missing one answers not found, then checks the actor against its owner
+func (s *Service) authorized(actor *secureDomain.Claims, action string, id string) (*photoDomain.Photo, error) {
+	photo, err := s.PhotoRepository.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = policy.Photo.Authorize(actor, action, photo.UserID); err != nil {
+		return nil, err
+	}
+
+	return photo, nil
+}
+
+func readConfig() (*config, error) {
+	viper.SetConfigFile("config.json")
+	if err := viper.ReadInConfig(); err != nil {
+		return nil, err
+	}
+
+	cfg := &config{ServerURL: viper.GetString("ServerURL")}
+	if err := viper.UnmarshalKey("Storage", cfg); err != nil {
+		return nil, err
+	}
+
+	if err := viper.UnmarshalKey("Variants", &cfg.Variants); err != nil {
+		return nil, err
+	}
+
+	if err := viper.UnmarshalKey("Duplicates", &cfg.Duplicates); err != nil {
+		return nil, err
+	}
+
+	switch cfg.Duplicates.Mode {
+	case duplicateOff, duplicateWarn, duplicateReject:
+	default:
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "photo duplicate mode must be off, warn or reject")
+	}
+
+	if cfg.MaxUploadMB <= 0 || cfg.MaxMegapixels <= 0 || cfg.DownloadTimeMinute <= 0 || cfg.Variants.Quality <= 0 || cfg.Variants.Quality > 100 {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "photo storage is not configured")
+	}
+
+	return cfg, nil
 }
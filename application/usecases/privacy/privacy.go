@@ -0,0 +1,428 @@
+// Package privacy provides the use case for the export and the erasure of the personal data of the users
+package privacy
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"hexagonal-fiber/application/security/policy"
+	"hexagonal-fiber/application/services"
+
+	auditDomain "hexagonal-fiber/domain/audit"
+	privacyDomain "hexagonal-fiber/domain/privacy"
+	secureDomain "hexagonal-fiber/domain/security"
+	storageDomain "hexagonal-fiber/domain/storage"
+	apiKeyRepository "hexagonal-fiber/infrastructure/repository/postgres/apikey"
+	commentRepository "hexagonal-fiber/infrastructure/repository/postgres/comment"
+	identityRepository "hexagonal-fiber/infrastructure/repository/postgres/identity"
+	photoRepository "hexagonal-fiber/infrastructure/repository/postgres/photo"
+	sosmedRepository "hexagonal-fiber/infrastructure/repository/postgres/sosmed"
+	userRepository "hexagonal-fiber/infrastructure/repository/postgres/user"
+	exportRepository "hexagonal-fiber/infrastructure/repository/redis/export"
+	sessionRepository "hexagonal-fiber/infrastructure/repository/redis/session"
+	tokenRepository "hexagonal-fiber/infrastructure/repository/redis/token"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+)
+
+// pageSize is the number of records read at once while assembling an archive
+const pageSize = 100
+
+// Service is a struct that contains the repository implementation for privacy use case
+type Service struct {
+	UserRepository        userRepository.Repository
+	PhotoRepository       photoRepository.Repository
+	CommentRepository     commentRepository.Repository
+	SocialMediaRepository sosmedRepository.Repository
+	IdentityRepository    identityRepository.Repository
+	APIKeyRepository      apiKeyRepository.Repository
+	SessionRepository     sessionRepository.Repository
+	TokenRepository       tokenRepository.Repository
+	ExportRepository      exportRepository.Repository
+	Storage               storageDomain.Storage
+	TokenVersions         services.TokenVersions
+	Audit                 auditDomain.Recorder
+}
+
+// config is the privacy section of the config
+type config struct {
+	ExportDirectory string
+	ExportTimeHour  int
+	// ExportBuildMinute is how long an archive may take to build, a pending export older than that is taken as failed
+	ExportBuildMinute int
+	ErasureGraceDay   int
+}
+
+// RequestExport starts assembling the archive of the personal data of the actor in the background
+func (s *Service) RequestExport(actor *secureDomain.Claims) (*privacyDomain.Export, error) {
+	if err := personally(actor, "export personal data"); err != nil {
+		return nil, err
+	}
+
+	cfg, err := readConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	previous, err := s.ExportRepository.GetByUser(actor.UserID)
+	if err == nil && previous.Status == privacyDomain.ExportPending && !previous.Stalled(time.Now(), cfg.buildTimeout()) {
+		return nil, fiber.NewError(fiber.StatusConflict, "an export is already in progress")
+	}
+
+	export := &privacyDomain.Export{
+		ID:          uuid.New().String(),
+		UserID:      actor.UserID,
+		Status:      privacyDomain.ExportPending,
+		RequestedAt: time.Now(),
+	}
+	if err = s.ExportRepository.Save(export, time.Duration(cfg.ExportTimeHour)*time.Hour); err != nil {
+		return nil, err
+	}
+
+	// a new archive replaces the previous one
+	if previous != nil && previous.Path != "" {
+		removeArchive(previous.Path)
+	}
+
+	go s.buildExport(*export, cfg)
+
+	return export, nil
+}
+
+// GetExport returns the state of the last export of the actor, an export whose build was lost is reported failed
+func (s *Service) GetExport(actor *secureDomain.Claims) (*privacyDomain.Export, error) {
+	cfg, err := readConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	export, err := s.ExportRepository.GetByUser(actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if export.Stalled(time.Now(), cfg.buildTimeout()) {
+		export.Status = privacyDomain.ExportFailed
+	}
+
+	return export, nil
+}
+
+// ExportArchive returns the export of the actor along with the path of its archive when it is ready to download
+func (s *Service) ExportArchive(actor *secureDomain.Claims) (*privacyDomain.Export, error) {
+	if err := personally(actor, "download personal data"); err != nil {
+		return nil, err
+	}
+
+	export, err := s.ExportRepository.GetByUser(actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if export.Status != privacyDomain.ExportReady || export.ExpiresAt == nil || time.Now().After(*export.ExpiresAt) {
+		return nil, fiber.NewError(fiber.StatusNotFound, "export is not ready")
+	}
+
+	return export, nil
+}
+
+// RequestErasure schedules the erasure of the account of the actor after the grace period
+func (s *Service) RequestErasure(actor *secureDomain.Claims) (*privacyDomain.Erasure, error) {
+	if err := personally(actor, "erase an account"); err != nil {
+		return nil, err
+	}
+
+	cfg, err := readConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	scheduledAt := time.Now().AddDate(0, 0, cfg.ErasureGraceDay)
+	if err = s.UserRepository.UpdateByMap(actor.UserID, map[string]interface{}{"erasure_scheduled_at": scheduledAt}); err != nil {
+		return nil, err
+	}
+
+	services.Audit(s.Audit, actor, auditDomain.Event{
+		Action:     auditDomain.ActionUserErasureRequest,
+		TargetType: auditDomain.TargetUser,
+		TargetID:   actor.UserID,
+		Changes:    auditDomain.Changes{"erasure_scheduled_at": {After: scheduledAt}},
+	})
+
+	return &privacyDomain.Erasure{UserID: actor.UserID, ScheduledAt: scheduledAt}, nil
+}
+
+// CancelErasure cancels the erasure of the account of the actor during the grace period
+func (s *Service) CancelErasure(actor *secureDomain.Claims) error {
+	if err := personally(actor, "cancel an erasure"); err != nil {
+		return err
+	}
+
+	user, err := s.UserRepository.GetByID(actor.UserID)
+	if err != nil {
+		return err
+	}
+
+	if user.ErasureScheduledAt == nil {
+		return fiber.NewError(fiber.StatusNotFound, "no erasure is scheduled")
+	}
+
+	if err = s.UserRepository.UpdateByMap(actor.UserID, map[string]interface{}{"erasure_scheduled_at": nil}); err != nil {
+		return err
+	}
+
+	services.Audit(s.Audit, actor, auditDomain.Event{
+		Action:     auditDomain.ActionUserErasureCancel,
+		TargetType: auditDomain.TargetUser,
+		TargetID:   actor.UserID,
+		Changes:    auditDomain.Changes{"erasure_scheduled_at": {Before: user.ErasureScheduledAt}},
+	})
+
+	return nil
+}
+
+// EraseDue erases every account whose grace period is over and returns how many were erased
+func (s *Service) EraseDue() (int, error) {
+	ids, err := s.UserRepository.GetDueErasures(time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	erased := 0
+	for _, id := range ids {
+		if err = s.Erase(id); err != nil {
+			log.Printf("failed erasing user %s: %s", id, err)
+			continue
+		}
+		erased++
+	}
+
+	return erased, nil
+}
+
+// Erase permanently deletes the user and every record it owns along with the uploaded images, then drops its sessions and its export
+func (s *Service) Erase(userID string) error {
+	// the tokens of the user must stop working even while the cached version outlives the row
+	if err := s.TokenVersions.Bump(userID); err != nil {
+		return err
+	}
+
+	keys, err := s.PhotoRepository.UserObjectKeys(userID)
+	if err != nil {
+		return err
+	}
+
+	if err = s.UserRepository.Erase(userID); err != nil {
+		return err
+	}
+	services.RemoveObjects(s.Storage, keys)
+
+	sessions, err := s.SessionRepository.UserGetAll(userID)
+	if err == nil {
+		for _, session := range *sessions {
+			_ = s.TokenRepository.RevokeFamily(session.ID)
+			_ = s.SessionRepository.Delete(userID, session.ID)
+		}
+	}
+
+	if export, err := s.ExportRepository.GetByUser(userID); err == nil {
+		removeArchive(export.Path)
+		_ = s.ExportRepository.Delete(userID)
+	}
+
+	services.Audit(s.Audit, nil, auditDomain.Event{
+		Action:     auditDomain.ActionUserErase,
+		TargetType: auditDomain.TargetUser,
+		TargetID:   userID,
+	})
+
+	return nil
+}
+
+// PurgeExports removes the archives older than their download window, returning how many were removed
+func (s *Service) PurgeExports() (int, error) {
+	cfg, err := readConfig()
+	if err != nil {
+		return 0, err
+	}
+
+	entries, err := os.ReadDir(cfg.ExportDirectory)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	expiry := time.Now().Add(-time.Duration(cfg.ExportTimeHour) * time.Hour)
+	purged := 0
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || entry.IsDir() || info.ModTime().After(expiry) {
+			continue
+		}
+
+		if os.Remove(filepath.Join(cfg.ExportDirectory, entry.Name())) == nil {
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+// buildExport assembles the archive of the export and records whether it succeeded
+func (s *Service) buildExport(export privacyDomain.Export, cfg *config) {
+	ttl := time.Duration(cfg.ExportTimeHour) * time.Hour
+
+	path, err := s.writeArchive(export, cfg.ExportDirectory)
+	completedAt := time.Now()
+	export.CompletedAt = &completedAt
+
+	if err != nil {
+		log.Printf("failed exporting the data of user %s: %s", export.UserID, err)
+		export.Status = privacyDomain.ExportFailed
+	} else {
+		expiresAt := completedAt.Add(ttl)
+		export.Status = privacyDomain.ExportReady
+		export.ExpiresAt = &expiresAt
+		export.Path = path
+	}
+
+	// a build outliving its timeout may have been replaced by a new request meanwhile, the new one is kept
+	if current, err := s.ExportRepository.GetByUser(export.UserID); err == nil && current.ID != export.ID {
+		if export.Path != "" {
+			removeArchive(export.Path)
+		}
+		return
+	}
+
+	if err = s.ExportRepository.Save(&export, ttl); err != nil {
+		log.Printf("failed saving the export of user %s: %s", export.UserID, err)
+	}
+}
+
+// writeArchive writes the zip of the personal data of the user, readable by the application only
+func (s *Service) writeArchive(export privacyDomain.Export, directory string) (string, error) {
+	archive, err := s.collect(export.UserID)
+	if err != nil {
+		return "", err
+	}
+
+	if err = os.MkdirAll(directory, 0o700); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(directory, export.ID+".zip")
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return "", err
+	}
+
+	if err = archive.WriteZip(file); err != nil {
+		file.Close()
+		removeArchive(path)
+		return "", err
+	}
+
+	if err = file.Close(); err != nil {
+		removeArchive(path)
+		return "", err
+	}
+
+	return path, nil
+}
+
+// collect reads every record of the user that goes into the archive
+func (s *Service) collect(userID string) (*privacyDomain.Archive, error) {
+	user, err := s.UserRepository.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	archive := &privacyDomain.Archive{Profile: user.DomainToResponseMapper(), ExportedAt: time.Now()}
+
+	for page, pages := 1, int64(1); int64(page) <= pages; page++ {
+		photos, err := s.PhotoRepository.UserGetAll(userID, page, pageSize)
+		if err != nil {
+			return nil, err
+		}
+		archive.Photos, pages = append(archive.Photos, *photos.Data...), photos.NumPages
+	}
+
+	for page, pages := 1, int64(1); int64(page) <= pages; page++ {
+		comments, err := s.CommentRepository.UserGetAll(userID, page, pageSize)
+		if err != nil {
+			return nil, err
+		}
+		archive.Comments, pages = append(archive.Comments, *comments.Data...), comments.NumPages
+	}
+
+	for page, pages := 1, int64(1); int64(page) <= pages; page++ {
+		sosmeds, err := s.SocialMediaRepository.UserGetAll(userID, page, pageSize)
+		if err != nil {
+			return nil, err
+		}
+		archive.SocialMedia, pages = append(archive.SocialMedia, *sosmeds.Data...), sosmeds.NumPages
+	}
+
+	sessions, err := s.SessionRepository.UserGetAll(userID)
+	if err != nil {
+		return nil, err
+	}
+	archive.Sessions = *sessions
+
+	identities, err := s.IdentityRepository.UserGetAll(userID)
+	if err != nil {
+		return nil, err
+	}
+	archive.Identities = *identities
+
+	apiKeys, err := s.APIKeyRepository.UserGetAll(userID)
+	if err != nil {
+		return nil, err
+	}
+	archive.APIKeys = *apiKeys
+
+	return archive, nil
+}
+
+// personally refuses the personal data requests made on behalf of the user by an admin or an api key
+func personally(actor *secureDomain.Claims, action string) error {
+	if err := policy.NotAPIKey(actor, action); err != nil {
+		return err
+	}
+
+	return policy.NotImpersonating(actor, action)
+}
+
+func readConfig() (*config, error) {
+	viper.SetConfigFile("config.json")
+	if err := viper.ReadInConfig(); err != nil {
+		return nil, err
+	}
+
+	cfg := &config{}
+	if err := viper.UnmarshalKey("Privacy", cfg); err != nil {
+		return nil, err
+	}
+
+	if cfg.ExportDirectory == "" || cfg.ExportTimeHour <= 0 || cfg.ExportBuildMinute <= 0 || cfg.ErasureGraceDay < 0 {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "privacy is not configured")
+	}
+
+	return cfg, nil
+}
+
+func (cfg *config) buildTimeout() time.Duration {
+	return time.Duration(cfg.ExportBuildMinute) * time.Minute
+}
+
+func removeArchive(path string) {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		log.Printf("failed removing export archive %s: %s", path, err)
+	}
+}
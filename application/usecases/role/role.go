@@ -0,0 +1,190 @@
+// Package role provides the use case for role
+package role
+
+import (
+	"hexagonal-fiber/application/services"
+
+	auditDomain "hexagonal-fiber/domain/audit"
+	secureDomain "hexagonal-fiber/domain/security"
+	userDomain "hexagonal-fiber/domain/user"
+	roleRepository "hexagonal-fiber/infrastructure/repository/postgres/role"
+	userRepository "hexagonal-fiber/infrastructure/repository/postgres/user"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/spf13/viper"
+)
+
+// Service is a struct that contains the repository implementation for role use case
+type Service struct {
+	RoleRepository roleRepository.Repository
+	UserRepository userRepository.Repository
+	Audit          auditDomain.Recorder
+	TokenVersions  services.TokenVersions
+}
+
+// GetAll is a function that returns all roles
+func (s *Service) GetAll() (*[]userDomain.Role, error) {
+	return s.RoleRepository.GetAll()
+}
+
+// GetPermissions is a function that returns every permission a role can own
+func (s *Service) GetPermissions() (*[]userDomain.Permission, error) {
+	return s.RoleRepository.GetPermissions()
+}
+
+// GetByID is a function that returns a role by id
+func (s *Service) GetByID(id string) (*userDomain.Role, error) {
+	return s.RoleRepository.GetByID(id)
+}
+
+// Create is a function that creates a new role
+func (s *Service) Create(actor *secureDomain.Claims, newRole userDomain.NewRole) (*userDomain.Role, error) {
+	role := &userDomain.Role{
+		Name:       newRole.Name,
+		RequireMFA: newRole.RequireMFA,
+	}
+
+	role, err := s.RoleRepository.Create(role, newRole.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	services.Audit(s.Audit, actor, auditDomain.Event{
+		Action:     auditDomain.ActionRoleCreate,
+		TargetType: auditDomain.TargetRole,
+		TargetID:   role.ID.String(),
+		Changes:    auditDomain.Diff(nil, roleSnapshot(role)),
+	})
+
+	return role, nil
+}
+
+// Update is a function that updates a role by id
+func (s *Service) Update(actor *secureDomain.Claims, id string, updateRole userDomain.UpdateRole) (*userDomain.Role, error) {
+	before, err := s.RoleRepository.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	roleMap := map[string]interface{}{}
+
+	if updateRole.Name != nil {
+		if err := s.checkNotDefault(id); err != nil {
+			return nil, err
+		}
+
+		roleMap["name"] = *updateRole.Name
+	}
+
+	if updateRole.RequireMFA != nil {
+		roleMap["require_mfa"] = *updateRole.RequireMFA
+	}
+
+	role, err := s.RoleRepository.Update(id, roleMap, updateRole.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	// tokens carry the role name and its permissions
+	if updateRole.Name != nil || updateRole.Permissions != nil {
+		if err = s.TokenVersions.BumpRole(id); err != nil {
+			return nil, err
+		}
+	}
+
+	services.Audit(s.Audit, actor, auditDomain.Event{
+		Action:     auditDomain.ActionRoleUpdate,
+		TargetType: auditDomain.TargetRole,
+		TargetID:   id,
+		Changes:    auditDomain.Diff(roleSnapshot(before), roleSnapshot(role)),
+	})
+
+	return role, nil
+}
+
+// Delete is a function that deletes a role no user is assigned to
+func (s *Service) Delete(actor *secureDomain.Claims, id string) error {
+	if err := s.checkNotDefault(id); err != nil {
+		return err
+	}
+
+	before, err := s.RoleRepository.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err = s.RoleRepository.Delete(id); err != nil {
+		return err
+	}
+
+	services.Audit(s.Audit, actor, auditDomain.Event{
+		Action:     auditDomain.ActionRoleDelete,
+		TargetType: auditDomain.TargetRole,
+		TargetID:   id,
+		Changes:    auditDomain.Diff(roleSnapshot(before), nil),
+	})
+
+	return nil
+}
+
+// AssignToUser is a function that gives a role to a user
+func (s *Service) AssignToUser(actor *secureDomain.Claims, roleID string, userID string) (*userDomain.ResponseUserRole, error) {
+	if _, err := s.RoleRepository.GetByID(roleID); err != nil {
+		return nil, err
+	}
+
+	before, err := s.UserRepository.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.UserRepository.UpdateByMap(userID, map[string]interface{}{"role_id": roleID}); err != nil {
+		return nil, err
+	}
+
+	if err = s.TokenVersions.Bump(userID); err != nil {
+		return nil, err
+	}
+
+	userRole, err := s.UserRepository.GetWithRole(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	services.Audit(s.Audit, actor, auditDomain.Event{
+		Action:     auditDomain.ActionRoleAssign,
+		TargetType: auditDomain.TargetUser,
+		TargetID:   userID,
+		Changes:    auditDomain.Changes{"role_id": {Before: before.RoleID, After: roleID}},
+	})
+
+	return userRole.UserToResponseMapper(), nil
+}
+
+// roleSnapshot is the audited view of a role, its permissions by name
+func roleSnapshot(role *userDomain.Role) map[string]interface{} {
+	return map[string]interface{}{
+		"name":        role.Name,
+		"require_mfa": role.RequireMFA,
+		"permissions": role.PermissionNames(),
+	}
+}
+
+// checkNotDefault refuses to rename or delete the role given to new users
+func (s *Service) checkNotDefault(id string) error {
+	viper.SetConfigFile("config.json")
+	if err := viper.ReadInConfig(); err != nil {
+		return err
+	}
+
+	role, err := s.RoleRepository.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	if role.Name == viper.GetString("Secure.DefaultRole") {
+		return fiber.NewError(fiber.StatusConflict, "the default role cannot be renamed or deleted")
+	}
+
+	return nil
+}
@@ -1,6 +1,10 @@
 package sosmed
 
 import (
+	"hexagonal-fiber/application/security/policy"
+	"hexagonal-fiber/application/services"
+	auditDomain "hexagonal-fiber/domain/audit"
+	secureDomain "hexagonal-fiber/domain/security"
 	sosmedDomain "hexagonal-fiber/domain/sosmed"
 
 	sosmedRepository "hexagonal-fiber/infrastructure/repository/postgres/sosmed"
@@ -10,6 +14,7 @@ import (
 type Service struct {
 	SocialMediaTesting    sosmedRepository.SocialMediaTesting
 	SocialMediaRepository sosmedRepository.Repository
+	Audit                 auditDomain.Recorder
 }
 
 // GetAll is a function that returns all sosmeds
@@ -50,14 +55,9 @@ func (s *Service) UserGetAll(userId string, page int, limit int) (*sosmedDomain.
 	}, nil
 }
 
-// GetByID is a function that returns a sosmed by id
-func (s *Service) GetByID(id string) (*sosmedDomain.SocialMedia, error) {
-	return s.SocialMediaRepository.GetByID(id)
-}
-
-// UserGetByID is a function that returns a sosmed by id
-func (s *Service) UserGetByID(id string, userId string) (*sosmedDomain.SocialMedia, error) {
-	return s.SocialMediaRepository.UserGetByID(id, userId)
+// GetByID is a function that returns a sosmed by id the actor is allowed to read
+func (s *Service) GetByID(actor *secureDomain.Claims, id string) (*sosmedDomain.SocialMedia, error) {
+	return s.authorized(actor, policy.Read, id)
 }
 
 // Create is a function that creates a sosmed
@@ -73,19 +73,94 @@ func (s *Service) GetByMap(sosmedMap map[string]interface{}) (*sosmedDomain.Soci
 	return s.SocialMediaRepository.GetOneByMap(sosmedMap)
 }
 
-// Delete is a function that deletes a sosmed by id
-func (s *Service) Delete(id string) (err error) {
-	return s.SocialMediaRepository.Delete(id)
+// Delete is a function that deletes a sosmed by id when the actor owns it or may delete any sosmed
+func (s *Service) Delete(actor *secureDomain.Claims, id string) (err error) {
+	before, err := s.authorized(actor, policy.Delete, id)
+	if err != nil {
+		return
+	}
+
+	if err = s.SocialMediaRepository.Delete(id); err != nil {
+		return
+	}
+
+	services.Audit(s.Audit, actor, auditDomain.Event{
+		Action:     auditDomain.ActionSocialMediaDelete,
+		TargetType: auditDomain.TargetSocialMedia,
+		TargetID:   id,
+		Changes:    auditDomain.Diff(before, nil),
+	})
+
+	return
 }
 
-// Update is a function that updates a sosmed by id
-func (s *Service) Update(id string, updateSocialMedia sosmedDomain.UpdateSocialMedia) (*sosmedDomain.SocialMedia, error) {
-	sosmed := updateSocialMedia.ToDomainMapper()
-	return s.SocialMediaRepository.Update(id, &sosmed)
+// Trash is a function that returns the deleted social media of a user when the actor is that user or may restore any social media
+func (s *Service) Trash(actor *secureDomain.Claims, userID string, page int, limit int) (*sosmedDomain.PaginationSocialMedia, error) {
+	if err := policy.SocialMedia.Authorize(actor, policy.Restore, userID); err != nil {
+		return nil, err
+	}
+
+	return s.SocialMediaRepository.UserGetTrash(userID, page, limit)
 }
 
-// Update is a function that updates a sosmed by id
-func (s *Service) UserUpdate(id string, userId string, updateSocialMedia sosmedDomain.UpdateSocialMedia) (*sosmedDomain.SocialMedia, error) {
+// Restore is a function that brings a deleted social media back when the actor owns it or may restore any social media
+func (s *Service) Restore(actor *secureDomain.Claims, id string) (*sosmedDomain.SocialMedia, error) {
+	deleted, err := s.SocialMediaRepository.GetDeletedByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = policy.SocialMedia.Authorize(actor, policy.Restore, deleted.UserID); err != nil {
+		return nil, err
+	}
+
+	if err = s.SocialMediaRepository.Restore(id); err != nil {
+		return nil, err
+	}
+
+	services.Audit(s.Audit, actor, auditDomain.Event{
+		Action:     auditDomain.ActionSocialMediaRestore,
+		TargetType: auditDomain.TargetSocialMedia,
+		TargetID:   id,
+		Changes:    auditDomain.Changes{"deleted_at": {Before: deleted.DeletedAt.Time}},
+	})
+
+	return s.SocialMediaRepository.GetByID(id)
+}
+
+// Update is a function that updates a sosmed by id when the actor owns it or may update any sosmed
+func (s *Service) Update(actor *secureDomain.Claims, id string, updateSocialMedia sosmedDomain.UpdateSocialMedia) (*sosmedDomain.SocialMedia, error) {
+	before, err := s.authorized(actor, policy.Update, id)
+	if err != nil {
+		return nil, err
+	}
+
 	sosmed := updateSocialMedia.ToDomainMapper()
-	return s.SocialMediaRepository.UserUpdate(id, userId, &sosmed)
+	updated, err := s.SocialMediaRepository.Update(id, &sosmed)
+	if err != nil {
+		return nil, err
+	}
+
+	services.AuditEdit(s.Audit, actor, before.UserID, auditDomain.Event{
+		Action:     auditDomain.ActionSocialMediaUpdate,
+		TargetType: auditDomain.TargetSocialMedia,
+		TargetID:   id,
+		Changes:    auditDomain.Diff(before, updated),
+	})
+
+	return updated, nil
+}
+
+// authorized fetches the sosmed first so a missing one answers not found, then checks the actor against its owner
+func (s *Service) authorized(actor *secureDomain.Claims, action string, id string) (*sosmedDomain.SocialMedia, error) {
+	sosmed, err := s.SocialMediaRepository.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = policy.SocialMedia.Authorize(actor, action, sosmed.UserID); err != nil {
+		return nil, err
+	}
+
+	return sosmed, nil
 }
@@ -0,0 +1,108 @@
+// Package trash provides the use case for the deleted resources waiting to be purged
+package trash
+
+import (
+	"log"
+	"time"
+
+	"hexagonal-fiber/application/services"
+	auditDomain "hexagonal-fiber/domain/audit"
+	storageDomain "hexagonal-fiber/domain/storage"
+	commentRepository "hexagonal-fiber/infrastructure/repository/postgres/comment"
+	photoRepository "hexagonal-fiber/infrastructure/repository/postgres/photo"
+	sosmedRepository "hexagonal-fiber/infrastructure/repository/postgres/sosmed"
+	userRepository "hexagonal-fiber/infrastructure/repository/postgres/user"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/spf13/viper"
+)
+
+// Service is a struct that contains the repository implementation for trash use case
+type Service struct {
+	UserRepository        userRepository.Repository
+	PhotoRepository       photoRepository.Repository
+	CommentRepository     commentRepository.Repository
+	SocialMediaRepository sosmedRepository.Repository
+	Storage               storageDomain.Storage
+	Audit                 auditDomain.Recorder
+}
+
+// Purged is a struct that contains how many records of each kind a purge removed for good
+type Purged struct {
+	Users       int
+	Photos      int64
+	Comments    int64
+	SocialMedia int64
+}
+
+// Purge permanently deletes what stayed in the trash longer than the retention window
+func (s *Service) Purge(now time.Time) (*Purged, error) {
+	retentionDay, err := readRetention()
+	if err != nil {
+		return nil, err
+	}
+
+	before := now.AddDate(0, 0, -retentionDay)
+	purged := &Purged{}
+
+	// the users go first, erasing one takes everything it owns along
+	ids, err := s.UserRepository.GetDeletedBefore(before)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range ids {
+		keys, err := s.PhotoRepository.UserObjectKeys(id)
+		if err != nil {
+			return purged, err
+		}
+
+		if err = s.UserRepository.Erase(id); err != nil {
+			log.Printf("failed purging user %s: %s", id, err)
+			continue
+		}
+		services.RemoveObjects(s.Storage, keys)
+
+		services.Audit(s.Audit, nil, auditDomain.Event{
+			Action:     auditDomain.ActionUserErase,
+			TargetType: auditDomain.TargetUser,
+			TargetID:   id,
+		})
+		purged.Users++
+	}
+
+	// the images are removed once their photos are gone, a failed purge keeps both
+	keys, err := s.PhotoRepository.DeletedObjectKeys(before)
+	if err != nil {
+		return purged, err
+	}
+
+	if purged.Photos, err = s.PhotoRepository.Purge(before); err != nil {
+		return purged, err
+	}
+	services.RemoveObjects(s.Storage, keys)
+
+	if purged.Comments, err = s.CommentRepository.Purge(before); err != nil {
+		return purged, err
+	}
+
+	if purged.SocialMedia, err = s.SocialMediaRepository.Purge(before); err != nil {
+		return purged, err
+	}
+
+	return purged, nil
+}
+
+func readRetention() (int, error) {
+	viper.SetConfigFile("config.json")
+	if err := viper.ReadInConfig(); err != nil {
+		return 0, err
+	}
+
+	retentionDay := viper.GetInt("Trash.RetentionDay")
+	if retentionDay <= 0 {
+		return 0, fiber.NewError(fiber.StatusInternalServerError, "trash retention is not configured")
+	}
+
+	return retentionDay, nil
+}
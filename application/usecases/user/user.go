@@ -2,6 +2,11 @@
 package user
 
 import (
+	"hexagonal-fiber/application/security/policy"
+	"hexagonal-fiber/application/services"
+	auditDomain "hexagonal-fiber/domain/audit"
+	mailDomain "hexagonal-fiber/domain/mail"
+	secureDomain "hexagonal-fiber/domain/security"
 	userDomain "hexagonal-fiber/domain/user"
 	roleRepository "hexagonal-fiber/infrastructure/repository/postgres/role"
 	userRepository "hexagonal-fiber/infrastructure/repository/postgres/user"
@@ -11,6 +16,10 @@ import (
 type Service struct {
 	UserRepository userRepository.Repository
 	RoleRepository roleRepository.Repository
+	Audit          auditDomain.Recorder
+	Passwords      secureDomain.PasswordHasher
+	TokenVersions  services.TokenVersions
+	Mailer         mailDomain.Mailer
 }
 
 // GetAll is a function that returns all users
@@ -18,13 +27,17 @@ func (s *Service) GetAll() (*[]userDomain.User, error) {
 	return s.UserRepository.GetAll()
 }
 
-// GetWithRole is a function that returns a user with role by id
-func (s *Service) GetWithRole(id string) (responUserRole *userDomain.ResponseUserRole, err error) {
+// GetWithRole is a function that returns a user with role by id when the actor is that user or may read any user
+func (s *Service) GetWithRole(actor *secureDomain.Claims, id string) (responUserRole *userDomain.ResponseUserRole, err error) {
 	userRole, err := s.UserRepository.GetWithRole(id)
 	if err != nil {
 		return nil, err
 	}
 
+	if err = policy.User.Authorize(actor, policy.Read, userRole.ID.String()); err != nil {
+		return nil, err
+	}
+
 	responUserRole = userRole.UserToResponseMapper()
 	return
 }
@@ -45,13 +58,165 @@ func (s *Service) GetOneByMap(userMap map[string]interface{}) (*userDomain.User,
 	return s.UserRepository.GetOneByMap(userMap)
 }
 
-// Delete is a function that deletes a user by id
-func (s *Service) Delete(id string) error {
-	return s.UserRepository.Delete(id)
+// Delete is a function that deletes a user by id when the actor is that user or may delete any user
+func (s *Service) Delete(actor *secureDomain.Claims, id string) error {
+	if err := policy.NotImpersonating(actor, "delete an account"); err != nil {
+		return err
+	}
+
+	if err := policy.NotAPIKey(actor, "delete an account"); err != nil {
+		return err
+	}
+
+	before, err := s.authorized(actor, policy.Delete, id)
+	if err != nil {
+		return err
+	}
+
+	// the tokens of the user must stop working even while the cached version outlives the row
+	if err = s.TokenVersions.Bump(id); err != nil {
+		return err
+	}
+
+	if err = s.UserRepository.Delete(id); err != nil {
+		return err
+	}
+
+	services.Audit(s.Audit, actor, auditDomain.Event{
+		Action:     auditDomain.ActionUserDelete,
+		TargetType: auditDomain.TargetUser,
+		TargetID:   id,
+		Changes:    auditDomain.Diff(before, nil),
+	})
+
+	return nil
+}
+
+// Trash is a function that returns the deleted users when the actor may restore any user
+func (s *Service) Trash(actor *secureDomain.Claims, page int, limit int) (*userDomain.PaginationResponseUser, error) {
+	if err := policy.User.Authorize(actor, policy.Restore, ""); err != nil {
+		return nil, err
+	}
+
+	trash, err := s.UserRepository.GetTrash(page, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return trash.ToResponseMapper(), nil
 }
 
-// Update is a function that updates a user by id
-func (s *Service) Update(id string, updateUser userDomain.UpdateUser) (*userDomain.User, error) {
+// Restore is a function that brings a deleted user back along with the content deleted with it when the actor may restore any user
+func (s *Service) Restore(actor *secureDomain.Claims, id string) (*userDomain.ResponseUser, error) {
+	deleted, err := s.UserRepository.GetDeletedByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = policy.User.Authorize(actor, policy.Restore, deleted.ID.String()); err != nil {
+		return nil, err
+	}
+
+	if err = s.UserRepository.Restore(deleted); err != nil {
+		return nil, err
+	}
+
+	services.Audit(s.Audit, actor, auditDomain.Event{
+		Action:     auditDomain.ActionUserRestore,
+		TargetType: auditDomain.TargetUser,
+		TargetID:   id,
+		Changes:    auditDomain.Changes{"deleted_at": {Before: deleted.DeletedAt.Time}},
+	})
+
+	return s.GetByID(id)
+}
+
+// Update is a function that updates a user by id when the actor is that user or may update any user,
+// a changed email has to be verified again
+func (s *Service) Update(actor *secureDomain.Claims, id string, updateUser userDomain.UpdateUser) (*userDomain.User, error) {
+	if updateUser.Password != nil {
+		if err := policy.NotImpersonating(actor, "change a password"); err != nil {
+			return nil, err
+		}
+
+		if err := policy.NotAPIKey(actor, "change a password"); err != nil {
+			return nil, err
+		}
+	}
+
+	// the email receives the password resets, changing it is as much a takeover as changing the password
+	if updateUser.Email != nil {
+		if err := policy.NotImpersonating(actor, "change an email"); err != nil {
+			return nil, err
+		}
+
+		if err := policy.NotAPIKey(actor, "change an email"); err != nil {
+			return nil, err
+		}
+	}
+
+	before, err := s.authorized(actor, policy.Update, id)
+	if err != nil {
+		return nil, err
+	}
+
 	user := updateUser.ToDomainMapper()
-	return s.UserRepository.Update(id, &user)
+	if updateUser.Password != nil {
+		if user.HashPassword, err = s.Passwords.Hash(*updateUser.Password); err != nil {
+			return nil, err
+		}
+	}
+
+	// a boolean turned off is a zero value the update of the other fields skips
+	if updateUser.KeepLocationPublic != nil {
+		if err = s.UserRepository.SetKeepLocationPublic(id, *updateUser.KeepLocationPublic); err != nil {
+			return nil, err
+		}
+	}
+
+	updated, err := s.UserRepository.Update(id, &user)
+	if err != nil {
+		return nil, err
+	}
+
+	if updateUser.Password != nil {
+		if err = s.TokenVersions.Bump(id); err != nil {
+			return nil, err
+		}
+	}
+
+	// a new email is unverified until the link mailed to it is opened
+	if updateUser.Email != nil && *updateUser.Email != before.Email {
+		if err = s.UserRepository.UpdateByMap(id, map[string]interface{}{"verified_at": nil}); err != nil {
+			return nil, err
+		}
+		updated.VerifiedAt = nil
+
+		if err = services.SendVerification(s.Mailer, updated); err != nil {
+			return nil, err
+		}
+	}
+
+	services.AuditEdit(s.Audit, actor, id, auditDomain.Event{
+		Action:     auditDomain.ActionUserUpdate,
+		TargetType: auditDomain.TargetUser,
+		TargetID:   id,
+		Changes:    auditDomain.Diff(before, updated),
+	})
+
+	return updated, nil
+}
+
+// authorized fetches the user first so a missing one answers not found, then checks the actor against it
+func (s *Service) authorized(actor *secureDomain.Claims, action string, id string) (*userDomain.User, error) {
+	user, err := s.UserRepository.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = policy.User.Authorize(actor, action, user.ID.String()); err != nil {
+		return nil, err
+	}
+
+	return user, nil
 }
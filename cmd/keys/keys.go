@@ -0,0 +1,95 @@
+package keys
+
+import (
+	"fmt"
+	"os"
+
+	"hexagonal-fiber/application/security/keyring"
+	secureDomain "hexagonal-fiber/domain/security"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	KeyID     string
+	Algorithm string
+)
+
+// KeysCmd represents the keys command
+var KeysCmd = &cobra.Command{
+	Use:   "keys",
+	Short: "Manage the JWT signing key ring",
+	Long: `The keys command manages the signing key ring without downtime:
+        generate a next key, which is published in the JWKS right away,
+        rotate once verifiers had time to fetch it, and retire the old
+        key once the tokens it signed have expired.`,
+}
+
+// generateCmd represents the keys generate command
+var generateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate the next signing key",
+	Run: func(cmd *cobra.Command, args []string) {
+		entry, err := keyring.GenerateKey(keyRingPath(), Algorithm)
+		if err != nil {
+			panic(fmt.Errorf("fatal error in generating key: %s", err))
+		}
+
+		fmt.Printf("generated next %s key %s\n", entry.Algorithm, entry.ID)
+		os.Exit(0)
+	},
+}
+
+// rotateCmd represents the keys rotate command
+var rotateCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "Sign with the next key and keep the active key as retiring",
+	Run: func(cmd *cobra.Command, args []string) {
+		entry, err := keyring.RotateKey(keyRingPath(), Algorithm)
+		if err != nil {
+			panic(fmt.Errorf("fatal error in rotating key: %s", err))
+		}
+
+		fmt.Printf("key %s is now active\n", entry.ID)
+		os.Exit(0)
+	},
+}
+
+// retireCmd represents the keys retire command
+var retireCmd = &cobra.Command{
+	Use:   "retire",
+	Short: "Remove a retiring key from the key ring",
+	Run: func(cmd *cobra.Command, args []string) {
+		if KeyID == "" {
+			cmd.Help()
+			return
+		}
+
+		if err := keyring.RetireKey(keyRingPath(), KeyID); err != nil {
+			panic(fmt.Errorf("fatal error in retiring key: %s", err))
+		}
+
+		fmt.Printf("key %s retired\n", KeyID)
+		os.Exit(0)
+	},
+}
+
+func keyRingPath() string {
+	viper.SetConfigFile("config.json")
+	if err := viper.ReadInConfig(); err != nil {
+		panic(fmt.Errorf("fatal error in config file: %s", err))
+	}
+
+	return viper.GetString("Secure.KeyRingPath")
+}
+
+func init() {
+	// algorithm flag
+	KeysCmd.PersistentFlags().StringVarP(&Algorithm, "alg", "a", secureDomain.AlgorithmRS256, "algorithm of the key: RS256, ES256 or EdDSA")
+
+	// retiring flag
+	retireCmd.PersistentFlags().StringVarP(&KeyID, "kid", "k", "", "id of the retiring key")
+
+	KeysCmd.AddCommand(generateCmd, rotateCmd, retireCmd)
+}
@@ -0,0 +1,81 @@
+package photos
+
+import (
+	"fmt"
+	"os"
+
+	databsDomain "hexagonal-fiber/domain/database"
+	"hexagonal-fiber/infrastructure/restapi/adapter"
+
+	"github.com/spf13/cobra"
+)
+
+var photosDB databsDomain.Database
+
+var Force bool
+
+// SetPhotosDB sets the databases used by the photos commands
+func SetPhotosDB(db databsDomain.Database) {
+	photosDB = db
+}
+
+// PhotosCmd represents the photos command
+var PhotosCmd = &cobra.Command{
+	Use:   "photos",
+	Short: "Maintain the uploaded photos",
+	Long: `The photos command maintains the images of the uploaded
+        photos, run it after changing the configured variants
+        or when generating them failed after an upload, and to
+        hash the photos uploaded before duplicates were detected
+        and group the near identical ones for the duplicates report.`,
+}
+
+// regenerateVariantsCmd represents the photos regenerate-variants command
+var regenerateVariantsCmd = &cobra.Command{
+	Use:   "regenerate-variants",
+	Short: "Generate the variants the uploaded photos are missing",
+	Run: func(cmd *cobra.Command, args []string) {
+		service := adapter.PhotoService(photosDB)
+
+		regenerated, err := service.RegenerateVariants(Force)
+		if err != nil {
+			panic(fmt.Errorf("fatal error in regenerating variants: %s", err))
+		}
+
+		fmt.Printf("generated %d variants of %d photos, %d photos failed\n",
+			regenerated.Variants, regenerated.Photos, regenerated.Failed)
+		os.Exit(0)
+	},
+}
+
+// hashCmd represents the photos hash command
+var hashCmd = &cobra.Command{
+	Use:   "hash",
+	Short: "Compute the perceptual hash of the uploaded photos having none then group the near identical photos",
+	Long: `The hash command hashes the photos missing their perceptual
+        hash then groups the near identical photos of every user,
+        the duplicates report shows the groups of its last run so
+        schedule it to keep the report current.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		service := adapter.PhotoService(photosDB)
+
+		hashed, err := service.HashMissing()
+		if err != nil {
+			panic(fmt.Errorf("fatal error in hashing photos: %s", err))
+		}
+
+		groups, err := service.GroupDuplicates()
+		if err != nil {
+			panic(fmt.Errorf("fatal error in grouping duplicates: %s", err))
+		}
+
+		fmt.Printf("hashed %d photos, %d photos failed, found %d groups of near identical photos\n",
+			hashed.Photos, hashed.Failed, groups)
+		os.Exit(0)
+	},
+}
+
+func init() {
+	regenerateVariantsCmd.PersistentFlags().BoolVarP(&Force, "force", "f", false, "generate every variant again, the existing ones included")
+	PhotosCmd.AddCommand(regenerateVariantsCmd, hashCmd)
+}
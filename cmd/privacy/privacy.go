@@ -0,0 +1,65 @@
+package privacy
+
+import (
+	"fmt"
+	"os"
+
+	databsDomain "hexagonal-fiber/domain/database"
+	"hexagonal-fiber/infrastructure/restapi/adapter"
+
+	"github.com/spf13/cobra"
+)
+
+var privacyDB databsDomain.Database
+
+// SetPrivacyDB sets the databases used by the privacy commands
+func SetPrivacyDB(db databsDomain.Database) {
+	privacyDB = db
+}
+
+// PrivacyCmd represents the privacy command
+var PrivacyCmd = &cobra.Command{
+	Use:   "privacy",
+	Short: "Process the personal data requests",
+	Long: `The privacy command runs the background work of the personal data
+        requests, schedule it to erase the accounts whose grace period
+        is over and to remove the expired export archives.`,
+}
+
+// eraseCmd represents the privacy erase command
+var eraseCmd = &cobra.Command{
+	Use:   "erase",
+	Short: "Erase the accounts whose grace period is over",
+	Run: func(cmd *cobra.Command, args []string) {
+		service := adapter.PrivacyService(privacyDB)
+
+		erased, err := service.EraseDue()
+		if err != nil {
+			panic(fmt.Errorf("fatal error in erasing accounts: %s", err))
+		}
+
+		fmt.Printf("erased %d accounts\n", erased)
+		os.Exit(0)
+	},
+}
+
+// purgeExportsCmd represents the privacy purge-exports command
+var purgeExportsCmd = &cobra.Command{
+	Use:   "purge-exports",
+	Short: "Remove the export archives past their download window",
+	Run: func(cmd *cobra.Command, args []string) {
+		service := adapter.PrivacyService(privacyDB)
+
+		purged, err := service.PurgeExports()
+		if err != nil {
+			panic(fmt.Errorf("fatal error in purging exports: %s", err))
+		}
+
+		fmt.Printf("removed %d export archives\n", purged)
+		os.Exit(0)
+	},
+}
+
+func init() {
+	PrivacyCmd.AddCommand(eraseCmd, purgeExportsCmd)
+}
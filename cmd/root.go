@@ -1,7 +1,11 @@
 package cmd
 
 import (
+	"hexagonal-fiber/cmd/keys"
 	"hexagonal-fiber/cmd/migrate"
+	"hexagonal-fiber/cmd/photos"
+	"hexagonal-fiber/cmd/privacy"
+	"hexagonal-fiber/cmd/trash"
 	databsDomain "hexagonal-fiber/domain/database"
 	"os"
 
@@ -30,6 +34,21 @@ func Execute(db databsDomain.Database) {
 	// postgres migrating flag
 	rootCmd.AddCommand(migrate.PostgresCmd)
 
+	// signing key ring
+	rootCmd.AddCommand(keys.KeysCmd)
+
+	// personal data requests
+	privacy.SetPrivacyDB(db)
+	rootCmd.AddCommand(privacy.PrivacyCmd)
+
+	// deleted resources
+	trash.SetTrashDB(db)
+	rootCmd.AddCommand(trash.TrashCmd)
+
+	// photo variants
+	photos.SetPhotosDB(db)
+	rootCmd.AddCommand(photos.PhotosCmd)
+
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
 	}
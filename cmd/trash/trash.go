@@ -0,0 +1,51 @@
+package trash
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	databsDomain "hexagonal-fiber/domain/database"
+	"hexagonal-fiber/infrastructure/restapi/adapter"
+
+	"github.com/spf13/cobra"
+)
+
+var trashDB databsDomain.Database
+
+// SetTrashDB sets the databases used by the trash commands
+func SetTrashDB(db databsDomain.Database) {
+	trashDB = db
+}
+
+// TrashCmd represents the trash command
+var TrashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "Manage the deleted resources",
+	Long: `The trash command manages the users, photos, comments and
+        social media kept after their deletion so they can be
+        restored, schedule it to purge them once the retention
+        window is over.`,
+}
+
+// purgeCmd represents the trash purge command
+var purgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Permanently delete what stayed in the trash past the retention window",
+	Run: func(cmd *cobra.Command, args []string) {
+		service := adapter.TrashService(trashDB)
+
+		purged, err := service.Purge(time.Now())
+		if err != nil {
+			panic(fmt.Errorf("fatal error in purging trash: %s", err))
+		}
+
+		fmt.Printf("purged %d users, %d photos, %d comments and %d social media\n",
+			purged.Users, purged.Photos, purged.Comments, purged.SocialMedia)
+		os.Exit(0)
+	},
+}
+
+func init() {
+	TrashCmd.AddCommand(purgeCmd)
+}
@@ -0,0 +1,127 @@
+// Package album contains the business logic for the album entity
+package album
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// visibilities of an album
+const (
+	Private = "private"
+	Public  = "public"
+)
+
+// orderings of the photos of an album
+const (
+	// Manual orders the photos by the position they were added or reordered to
+	Manual = "manual"
+	Newest = "newest"
+	Oldest = "oldest"
+	// Taken orders the photos by the time they were taken, the photos without one last
+	Taken = "taken"
+)
+
+// Album is a struct that contains the album information, an album groups photos without owning them
+type Album struct {
+	ID           uuid.UUID `json:"id" example:"cef47ee2-7211-452a-a087-79ce4b8ec3a3" gorm:"type:uuid;default:uuid_generate_v4();primarykey"`
+	Title        string    `json:"title" example:"Holidays"`
+	Description  string    `json:"description" example:"Summer in Bali"`
+	CoverPhotoID *string   `json:"cover_photo_id,omitempty" example:"cef47ee2-7211-452a-a087-79ce4b8ec3a3"`
+	Ordering     string    `json:"ordering" example:"manual" gorm:"not null;default:manual"`
+	Visibility   string    `json:"visibility" example:"private" gorm:"not null;default:private;index"`
+	UserID       string    `json:"user_id" gorm:"index"`
+	// PhotoCount is how many photos the album shows, it is counted when the album is read
+	PhotoCount int64          `json:"photo_count" example:"12" gorm:"->;-:migration"`
+	CreatedAt  time.Time      `json:"created_at,omitempty" example:"2021-02-24 20:19:39" gorm:"autoCreateTime:mili"`
+	UpdatedAt  time.Time      `json:"updated_at,omitempty" example:"2021-02-24 20:19:39" gorm:"autoUpdateTime:mili"`
+	DeletedAt  gorm.DeletedAt `json:"deleted_at,omitempty" swaggertype:"string" example:"null" gorm:"index"`
+}
+
+// TableName overrides the table name used by Album to `albums`
+func (*Album) TableName() string {
+	return "albums"
+}
+
+// AlbumPhoto is a struct that contains the link of a photo to an album along with its position in it
+type AlbumPhoto struct {
+	AlbumID   string    `gorm:"primarykey"`
+	PhotoID   string    `gorm:"primarykey;index"`
+	Position  int       `gorm:"not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime:mili"`
+}
+
+// TableName overrides the table name used by AlbumPhoto to `album_photos`
+func (*AlbumPhoto) TableName() string {
+	return "album_photos"
+}
+
+// PaginationAlbum is a struct that contains the pagination result for album
+type PaginationAlbum struct {
+	Data       *[]Album
+	Total      int64
+	Limit      int64
+	Current    int64
+	NextCursor uint
+	PrevCursor uint
+	NumPages   int64
+}
+
+// UniquePhotoIDs returns the photo ids without their repetitions, in the order they are first given
+func UniquePhotoIDs(photoIds []string) []string {
+	seen := map[string]bool{}
+	kept := make([]string, 0, len(photoIds))
+	for _, photoId := range photoIds {
+		if !seen[photoId] {
+			seen[photoId] = true
+			kept = append(kept, photoId)
+		}
+	}
+
+	return kept
+}
+
+// IsOrderOf tells whether the ordered ids list every one of the photo ids once and nothing else
+func IsOrderOf(ordered []string, photoIds []string) bool {
+	if len(ordered) != len(photoIds) || len(UniquePhotoIDs(ordered)) != len(ordered) {
+		return false
+	}
+
+	for _, photoId := range ordered {
+		if !containsPhotoID(photoIds, photoId) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsValidCover tells whether the photo may be the cover of an album showing the photo ids, an empty cover removes it
+func IsValidCover(cover string, photoIds []string) bool {
+	return cover == "" || containsPhotoID(photoIds, cover)
+}
+
+// CoverAfterAdding returns the cover an album gets once the photos were added to it and whether it changed,
+// the first photo added to an album without a cover becomes its cover
+func (a *Album) CoverAfterAdding(added []string) (cover string, changed bool) {
+	if a.CoverPhotoID != nil {
+		return *a.CoverPhotoID, false
+	}
+	if len(added) == 0 {
+		return "", false
+	}
+
+	return added[0], true
+}
+
+func containsPhotoID(photoIds []string, photoId string) bool {
+	for _, candidate := range photoIds {
+		if candidate == photoId {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,24 @@
+package album
+
+// NewAlbum is a struct that contains the request body for the new album
+type NewAlbum struct {
+	Title       string `json:"title" example:"Holidays" validate:"required"`
+	Description string `json:"description" example:"Summer in Bali" validate:"-"`
+	Ordering    string `json:"ordering,omitempty" example:"manual" validate:"-"`
+	Visibility  string `json:"visibility,omitempty" example:"private" validate:"-"`
+	UserID      string `json:"user_id" validate:"-"`
+}
+
+// UpdateAlbum is a struct that contains the request body for the update album, an empty cover removes it
+type UpdateAlbum struct {
+	Title        *string `json:"title,omitempty" example:"Holidays" validate:"-"`
+	Description  *string `json:"description,omitempty" example:"Summer in Bali" validate:"-"`
+	CoverPhotoID *string `json:"cover_photo_id,omitempty" example:"cef47ee2-7211-452a-a087-79ce4b8ec3a3" validate:"-"`
+	Ordering     *string `json:"ordering,omitempty" example:"newest" validate:"-"`
+	Visibility   *string `json:"visibility,omitempty" example:"public" validate:"-"`
+}
+
+// AlbumPhotosRequest is a struct that contains the request body for adding photos to an album, removing them or reordering them
+type AlbumPhotosRequest struct {
+	PhotoIDs []string `json:"photo_ids" example:"cef47ee2-7211-452a-a087-79ce4b8ec3a3" validate:"required"`
+}
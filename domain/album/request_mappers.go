@@ -0,0 +1,52 @@
+package album
+
+func (n *NewAlbum) ToDomainMapper() *Album {
+	album := &Album{
+		Title:       n.Title,
+		Description: n.Description,
+		Ordering:    n.Ordering,
+		Visibility:  n.Visibility,
+		UserID:      n.UserID,
+	}
+
+	if album.Ordering == "" {
+		album.Ordering = Manual
+	}
+
+	if album.Visibility == "" {
+		album.Visibility = Private
+	}
+
+	return album
+}
+
+// ToMap lists the columns to update, an empty description or cover is an update too
+func (n *UpdateAlbum) ToMap() map[string]interface{} {
+	columns := map[string]interface{}{}
+
+	if n.Title != nil {
+		columns["title"] = *n.Title
+	}
+
+	if n.Description != nil {
+		columns["description"] = *n.Description
+	}
+
+	if n.CoverPhotoID != nil {
+		if *n.CoverPhotoID == "" {
+			columns["cover_photo_id"] = nil
+		} else {
+			columns["cover_photo_id"] = *n.CoverPhotoID
+		}
+	}
+
+	if n.Ordering != nil {
+		columns["ordering"] = *n.Ordering
+	}
+
+	if n.Visibility != nil {
+		columns["visibility"] = *n.Visibility
+	}
+
+	return columns
+}
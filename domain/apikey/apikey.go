@@ -0,0 +1,48 @@
+// Package apikey contains the api keys of the machine to machine clients
+package apikey
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ClaimsType is the type of the claims built from an api key, next to the token types of the jwt
+const ClaimsType = "api_key"
+
+// APIKey is a struct that contains an api key, only the sha256 of its secret is stored.
+// A key acts as its owner, a person or a service account, within the scopes it was given
+type APIKey struct {
+	ID         uuid.UUID  `json:"id" example:"cef47ee2-7211-452a-a087-79ce4b8ec3a3" gorm:"type:uuid;default:uuid_generate_v4();primarykey"`
+	Name       string     `json:"name" example:"nightly export"`
+	Prefix     string     `json:"prefix" example:"hfk_3kq9x2mz" gorm:"uniqueIndex;not null"`
+	SecretHash string     `json:"-" gorm:"not null"`
+	UserID     string     `json:"user_id" example:"cef47ee2-7211-452a-a087-79ce4b8ec3a3" gorm:"index;not null"`
+	Service    string     `json:"service,omitempty" example:"photo-exporter"`
+	Scopes     []string   `json:"scopes" example:"photo:update:any" gorm:"type:jsonb;serializer:json"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" example:"2021-02-24 20:19:39"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" example:"2021-02-24 20:19:39"`
+	CreatedBy  string     `json:"created_by" example:"cef47ee2-7211-452a-a087-79ce4b8ec3a3"`
+	CreatedAt  time.Time  `json:"created_at,omitempty" example:"2021-02-24 20:19:39" gorm:"autoCreateTime:mili"`
+}
+
+// TableName overrides the table name used by APIKey to `api_keys`
+func (*APIKey) TableName() string {
+	return "api_keys"
+}
+
+// NewAPIKey is a struct that contains the request body for the new api key.
+// Admins may create keys for another user, a service account typically, and name the service using it
+type NewAPIKey struct {
+	Name      string     `json:"name" example:"nightly export" validate:"required,max=100"`
+	UserID    string     `json:"user_id,omitempty" example:"cef47ee2-7211-452a-a087-79ce4b8ec3a3" validate:"omitempty,uuid"`
+	Service   string     `json:"service,omitempty" example:"photo-exporter" validate:"max=100"`
+	Scopes    []string   `json:"scopes" example:"photo:update:any"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" example:"2031-02-24T20:19:39Z"`
+}
+
+// CreatedAPIKey is a struct that contains a new api key along with its secret, which is never shown again
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key" example:"hfk_3kq9x2mz_Zm9vYmFyYmF6cXV4cXV1eHF1dXhxdXV4cXV1eA"`
+}
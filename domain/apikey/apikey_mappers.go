@@ -0,0 +1,67 @@
+package apikey
+
+import (
+	"strings"
+	"time"
+
+	secureDomain "hexagonal-fiber/domain/security"
+)
+
+// keyPrefix starts every api key so leaked keys are easy to recognise by secret scanners
+const keyPrefix = "hfk_"
+
+// GenerateKey returns a new api key, "hfk_<id>_<secret>", with the prefix it is looked up by and the hash of its secret
+func GenerateKey() (key string, prefix string, secretHash string, err error) {
+	id, err := secureDomain.GenerateToken(6)
+	if err != nil {
+		return
+	}
+
+	secret, err := secureDomain.GenerateToken(32)
+	if err != nil {
+		return
+	}
+
+	// the id is base64 url, an underscore in it would break the parsing of the key
+	prefix = keyPrefix + strings.ReplaceAll(strings.ReplaceAll(id, "_", "x"), "-", "z")
+	return prefix + "_" + secret, prefix, secureDomain.HashToken(secret), nil
+}
+
+// ParseKey splits an api key into its prefix and its secret
+func ParseKey(key string) (prefix string, secret string, ok bool) {
+	if !strings.HasPrefix(key, keyPrefix) {
+		return "", "", false
+	}
+
+	separator := strings.Index(key[len(keyPrefix):], "_")
+	if separator <= 0 {
+		return "", "", false
+	}
+
+	separator += len(keyPrefix)
+	prefix, secret = key[:separator], key[separator+1:]
+
+	return prefix, secret, secret != ""
+}
+
+// IsExpired reports whether the key expired at the given time
+func (k *APIKey) IsExpired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
+
+// EffectivePermissions returns the scopes of the key its owner still holds, so a key never outranks its owner
+func (k *APIKey) EffectivePermissions(ownerPermissions []string) []string {
+	owned := make(map[string]bool, len(ownerPermissions))
+	for _, permission := range ownerPermissions {
+		owned[permission] = true
+	}
+
+	permissions := []string{}
+	for _, scope := range k.Scopes {
+		if owned[scope] {
+			permissions = append(permissions, scope)
+		}
+	}
+
+	return permissions
+}
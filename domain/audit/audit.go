@@ -0,0 +1,115 @@
+// Package audit contains the record of the security relevant actions
+package audit
+
+import "time"
+
+// audit actions
+const (
+	ActionLogin        = "auth.login"
+	ActionLoginFailed  = "auth.login_failed"
+	ActionIdentityLink = "auth.identity_link"
+
+	ActionRoleCreate = "role.create"
+	ActionRoleUpdate = "role.update"
+	ActionRoleDelete = "role.delete"
+	ActionRoleAssign = "role.assign"
+
+	ActionUserUpdate  = "user.update"
+	ActionUserDelete  = "user.delete"
+	ActionUserRestore = "user.restore"
+
+	ActionUserErasureRequest = "user.erasure_request"
+	ActionUserErasureCancel  = "user.erasure_cancel"
+	ActionUserErase          = "user.erase"
+
+	ActionPhotoUpdate  = "photo.update"
+	ActionPhotoDelete  = "photo.delete"
+	ActionPhotoRestore = "photo.restore"
+
+	ActionCommentUpdate  = "comment.update"
+	ActionCommentDelete  = "comment.delete"
+	ActionCommentRestore = "comment.restore"
+
+	ActionSocialMediaUpdate  = "sosmed.update"
+	ActionSocialMediaDelete  = "sosmed.delete"
+	ActionSocialMediaRestore = "sosmed.restore"
+
+	ActionAlbumUpdate = "album.update"
+	ActionAlbumDelete = "album.delete"
+
+	ActionAPIKeyCreate = "apikey.create"
+	ActionAPIKeyRevoke = "apikey.revoke"
+
+	ActionImpersonationStart = "impersonation.start"
+	ActionImpersonationStop  = "impersonation.stop"
+	ActionImpersonationWrite = "impersonation.write"
+
+	// ActionSecurityPrefix prefixes the type of the security events recorded in the audit log
+	ActionSecurityPrefix = "security."
+)
+
+// audit targets
+const (
+	TargetUser        = "user"
+	TargetRole        = "role"
+	TargetPhoto       = "photo"
+	TargetComment     = "comment"
+	TargetSocialMedia = "sosmed"
+	TargetAlbum       = "album"
+	TargetAPIKey      = "apikey"
+)
+
+// Event is a struct that contains an entry of the audit log, entries are never updated nor deleted
+type Event struct {
+	ID      uint64 `json:"id" example:"1" gorm:"primarykey"`
+	Action  string `json:"action" example:"photo.delete" gorm:"index;not null"`
+	ActorID string `json:"actor_id,omitempty" example:"cef47ee2-7211-452a-a087-79ce4b8ec3a3" gorm:"index"`
+	// ImpersonatedID is the user the actor was impersonating when acting
+	ImpersonatedID string    `json:"impersonated_id,omitempty" example:"cef47ee2-7211-452a-a087-79ce4b8ec3a3" gorm:"index"`
+	TargetType     string    `json:"target_type,omitempty" example:"photo" gorm:"index:idx_audit_events_target"`
+	TargetID       string    `json:"target_id,omitempty" example:"cef47ee2-7211-452a-a087-79ce4b8ec3a3" gorm:"index:idx_audit_events_target"`
+	Changes        Changes   `json:"changes,omitempty" gorm:"type:jsonb;serializer:json"`
+	IP             string    `json:"ip,omitempty" example:"127.0.0.1"`
+	UserAgent      string    `json:"user_agent,omitempty" example:"Mozilla/5.0"`
+	OccurredAt     time.Time `json:"occurred_at" example:"2021-02-24 20:19:39" gorm:"index;not null"`
+}
+
+// TableName overrides the table name used by Event to `audit_events`
+func (*Event) TableName() string {
+	return "audit_events"
+}
+
+// Change is the value of a field before and after an action, a created field has no before and a deleted one no after
+type Change struct {
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// Changes is a struct that contains the changed fields of the target by name
+type Changes map[string]Change
+
+// Filter is a struct that contains the criteria of an audit log query, empty criteria match everything
+type Filter struct {
+	ActorID    string
+	Action     string
+	TargetType string
+	TargetID   string
+	From       *time.Time
+	To         *time.Time
+}
+
+// PaginationEvent is a struct that contains the pagination result for audit events
+type PaginationEvent struct {
+	Data       *[]Event
+	Total      int64
+	Limit      int64
+	Current    int64
+	NextCursor uint
+	PrevCursor uint
+	NumPages   int64
+}
+
+// Recorder is the port appending events to the audit log
+type Recorder interface {
+	Record(event Event) error
+}
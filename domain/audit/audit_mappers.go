@@ -0,0 +1,88 @@
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// redacted replaces the value of the sensitive fields in the audit log
+const redacted = "[redacted]"
+
+// ignoredFields are bookkeeping fields every change touches
+var ignoredFields = map[string]bool{
+	"created_at": true,
+	"updated_at": true,
+}
+
+// Diff returns the fields differing between two snapshots of a target. Snapshots are compared
+// through their JSON form, a nil before records a creation and a nil after records a deletion
+func Diff(before interface{}, after interface{}) Changes {
+	beforeFields := toFields(before)
+	afterFields := toFields(after)
+
+	changes := Changes{}
+	for name, beforeValue := range beforeFields {
+		afterValue, ok := afterFields[name]
+		if ok && reflect.DeepEqual(beforeValue, afterValue) {
+			continue
+		}
+
+		changes[name] = Change{Before: beforeValue, After: afterValue}
+	}
+
+	for name, afterValue := range afterFields {
+		if _, ok := beforeFields[name]; !ok {
+			changes[name] = Change{After: afterValue}
+		}
+	}
+
+	for name, change := range changes {
+		if ignoredFields[name] {
+			delete(changes, name)
+			continue
+		}
+
+		if isSensitive(name) {
+			changes[name] = redact(change)
+		}
+	}
+
+	return changes
+}
+
+func toFields(snapshot interface{}) map[string]interface{} {
+	fields := map[string]interface{}{}
+	if snapshot == nil || reflect.ValueOf(snapshot).Kind() == reflect.Ptr && reflect.ValueOf(snapshot).IsNil() {
+		return fields
+	}
+
+	snapshotJSON, err := json.Marshal(snapshot)
+	if err != nil {
+		return fields
+	}
+
+	if err = json.Unmarshal(snapshotJSON, &fields); err != nil {
+		return map[string]interface{}{}
+	}
+
+	return fields
+}
+
+// isSensitive reports whether a field holds a credential that must never reach the audit log
+func isSensitive(name string) bool {
+	name = strings.ToLower(name)
+	return strings.Contains(name, "password") || strings.Contains(name, "secret") || strings.Contains(name, "token")
+}
+
+func redact(change Change) Change {
+	if change.Before != nil {
+		change.Before = redacted
+	}
+
+	if change.After != nil {
+		change.After = redacted
+	}
+
+	return change
+}
@@ -4,17 +4,18 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // Comment is a struct that contains the comment information
 type Comment struct {
-	ID        uuid.UUID  `json:"id" example:"cef47ee2-7211-452a-a087-79ce4b8ec3a3" gorm:"type:uuid;default:uuid_generate_v4();primarykey"`
-	UserID    string     `json:"user_id" gorm:"index"`
-	PhotoID   string     `json:"photo_id" gorm:"index"`
-	Message   string     `json:"message" example:"caption"`
-	CreatedAt time.Time  `json:"created_at,omitempty" example:"2021-02-24 20:19:39" gorm:"autoCreateTime:mili"`
-	UpdatedAt time.Time  `json:"updated_at,omitempty" example:"2021-02-24 20:19:39" gorm:"autoUpdateTime:mili"`
-	DeletedAt *time.Time `json:"deleted_at,omitempty" example:"null"`
+	ID        uuid.UUID      `json:"id" example:"cef47ee2-7211-452a-a087-79ce4b8ec3a3" gorm:"type:uuid;default:uuid_generate_v4();primarykey"`
+	UserID    string         `json:"user_id" gorm:"index"`
+	PhotoID   string         `json:"photo_id" gorm:"index"`
+	Message   string         `json:"message" example:"caption"`
+	CreatedAt time.Time      `json:"created_at,omitempty" example:"2021-02-24 20:19:39" gorm:"autoCreateTime:mili"`
+	UpdatedAt time.Time      `json:"updated_at,omitempty" example:"2021-02-24 20:19:39" gorm:"autoUpdateTime:mili"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" swaggertype:"string" example:"null" gorm:"index"`
 }
 
 // TableName overrides the table name used by Comment to `comments`
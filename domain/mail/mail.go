@@ -0,0 +1,14 @@
+// Package mail contains the mail port of the application
+package mail
+
+// Message is a struct that contains a plain text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer is the port implemented by every mail delivery adapter
+type Mailer interface {
+	Send(message Message) error
+}
@@ -1,21 +1,100 @@
 package photo
 
 import (
+	"net/url"
 	"time"
 
+	storageDomain "hexagonal-fiber/domain/storage"
+
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // Photo is a struct that contains the photo information
 type Photo struct {
-	ID        uuid.UUID  `json:"id" example:"cef47ee2-7211-452a-a087-79ce4b8ec3a3" gorm:"type:uuid;default:uuid_generate_v4();primarykey"`
-	Title     string     `json:"title" example:"title"`
-	Caption   string     `json:"caption" example:"caption"`
-	PhotoUrl  string     `json:"photo_url" example:"www.photo.com"`
-	UserID    string     `json:"user_id" gorm:"index"`
-	CreatedAt time.Time  `json:"created_at,omitempty" example:"2021-02-24 20:19:39" gorm:"autoCreateTime:mili"`
-	UpdatedAt time.Time  `json:"updated_at,omitempty" example:"2021-02-24 20:19:39" gorm:"autoUpdateTime:mili"`
-	DeletedAt *time.Time `json:"deleted_at,omitempty" example:"null"`
+	ID       uuid.UUID `json:"id" example:"cef47ee2-7211-452a-a087-79ce4b8ec3a3" gorm:"type:uuid;default:uuid_generate_v4();primarykey"`
+	Title    string    `json:"title" example:"title"`
+	Caption  string    `json:"caption" example:"caption"`
+	PhotoUrl string    `json:"photo_url" example:"www.photo.com"`
+	UserID   string    `json:"user_id" gorm:"index"`
+	// ObjectKey is where the uploaded image is kept in the storage, photos linking an outside url have none
+	ObjectKey   string `json:"-"`
+	ContentType string `json:"content_type,omitempty" example:"image/jpeg"`
+	Size        int64  `json:"size,omitempty" example:"204800"`
+	Width       int    `json:"width,omitempty" example:"1920"`
+	Height      int    `json:"height,omitempty" example:"1080"`
+	// Checksum is the hex encoded SHA-256 of the uploaded image
+	Checksum string `json:"checksum,omitempty" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+	// CameraMake, CameraModel, Lens, TakenAt and Orientation are read from the EXIF of an uploaded JPEG before it is stripped
+	CameraMake  string     `json:"camera_make,omitempty" example:"FUJIFILM"`
+	CameraModel string     `json:"camera_model,omitempty" example:"X-T4"`
+	Lens        string     `json:"lens,omitempty" example:"XF23mmF1.4 R"`
+	TakenAt     *time.Time `json:"taken_at,omitempty" example:"2021-02-24 20:19:39"`
+	Orientation int        `json:"orientation,omitempty" example:"6"`
+	// PerceptualHash is the difference hash of the uploaded image, near identical images have hashes differing by a few bits
+	PerceptualHash *int64 `json:"-" gorm:"index"`
+	// DuplicateOf lists the photos of the owner an upload is a near duplicate of, it is only set on the upload response
+	DuplicateOf []string `json:"duplicate_of,omitempty" gorm:"-" example:"cef47ee2-7211-452a-a087-79ce4b8ec3a3"`
+	// Latitude and Longitude are only kept when the owner chose to keep the location of their photos public
+	Latitude  *float64       `json:"latitude,omitempty" example:"-6.175392"`
+	Longitude *float64       `json:"longitude,omitempty" example:"106.827153"`
+	CreatedAt time.Time      `json:"created_at,omitempty" example:"2021-02-24 20:19:39" gorm:"autoCreateTime:mili"`
+	UpdatedAt time.Time      `json:"updated_at,omitempty" example:"2021-02-24 20:19:39" gorm:"autoUpdateTime:mili"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" swaggertype:"string" example:"null" gorm:"index"`
+	// Variants are the downscaled copies of an uploaded image, generated after it is stored
+	Variants []Variant `json:"variants,omitempty" gorm:"foreignKey:PhotoID"`
+}
+
+// Variant is a struct that contains a downscaled copy of the image of a photo, like the thumbnail of the grid
+type Variant struct {
+	ID        uint      `json:"-" gorm:"primarykey"`
+	PhotoID   string    `json:"-" gorm:"uniqueIndex:idx_photo_variants_name"`
+	Name      string    `json:"name" example:"thumb" gorm:"uniqueIndex:idx_photo_variants_name"`
+	ObjectKey string    `json:"-"`
+	URL       string    `json:"url" example:"http://localhost:8080/v1/photos/cef47ee2-7211-452a-a087-79ce4b8ec3a3/download?variant=thumb"`
+	Width     int       `json:"width" example:"200"`
+	Height    int       `json:"height" example:"200"`
+	Size      int64     `json:"size" example:"10240"`
+	Checksum  string    `json:"-"`
+	CreatedAt time.Time `json:"created_at,omitempty" example:"2021-02-24 20:19:39" gorm:"autoCreateTime:mili"`
+}
+
+// TableName overrides the table name used by Variant to `photo_variants`
+func (*Variant) TableName() string {
+	return "photo_variants"
+}
+
+// VariantSize is a struct that contains the configured box a variant is downscaled to, filling it when cropped
+type VariantSize struct {
+	Name   string
+	Width  int
+	Height int
+	Crop   bool
+}
+
+// ContentTypes lists the image types a photo can be uploaded as along with the extension they are stored with
+var ContentTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+// IsLinkURL reports whether the url of a linked photo is an absolute http or https url,
+// the download redirects to it so anything else would make the api an open redirect
+func IsLinkURL(raw string) bool {
+	link, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+
+	return (link.Scheme == "http" || link.Scheme == "https") && link.Host != "" && link.User == nil
+}
+
+// Download is a struct that contains where the image of a photo is served from, an address to redirect to or the stored object
+type Download struct {
+	URL      string
+	Object   *storageDomain.Object
+	Checksum string
 }
 
 // TableName overrides the table name used by Photo to `photos`
@@ -23,6 +102,47 @@ func (*Photo) TableName() string {
 	return "photos"
 }
 
+// HashedPhoto is a struct that contains the perceptual hash of a photo, it is what the duplicates are searched among
+type HashedPhoto struct {
+	ID             string
+	UserID         string
+	PerceptualHash int64
+}
+
+// Duplicate is a struct that contains a photo of a group of near identical photos, the groups are computed by the
+// photos hash command and ranked so the report pages through them without grouping every hash again
+type Duplicate struct {
+	PhotoID string `gorm:"primaryKey"`
+	// Rank is the place of the group in the report starting at 1, the groups posted by the most users then the largest first
+	Rank int `gorm:"index;not null"`
+	// Users is how many users posted the photos of the group
+	Users     int       `gorm:"not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime:mili"`
+}
+
+// TableName overrides the table name used by Duplicate to `photo_duplicates`
+func (*Duplicate) TableName() string {
+	return "photo_duplicates"
+}
+
+// DuplicateCluster is a struct that contains a group of near identical photos
+type DuplicateCluster struct {
+	// Users is how many users posted the photos of the group, more than one is a repost
+	Users  int     `json:"users" example:"2"`
+	Photos []Photo `json:"photos"`
+}
+
+// PaginationDuplicate is a struct that contains the pagination result for the duplicate clusters
+type PaginationDuplicate struct {
+	Data       *[]DuplicateCluster
+	Total      int64
+	Limit      int64
+	Current    int64
+	NextCursor uint
+	PrevCursor uint
+	NumPages   int64
+}
+
 // PaginationPhoto is a struct that contains the pagination result for photo
 type PaginationPhoto struct {
 	Data       *[]Photo
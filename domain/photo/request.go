@@ -2,10 +2,10 @@ package photo
 
 // NewPhoto is a struct that contains the data for new photo
 type NewPhoto struct {
-	Title    string `json:"title" example:"title" validate:"required"`
-	Caption  string `json:"caption,omitempty" example:"caption" validate:"-"`
-	PhotoUrl string `json:"photo_url" example:"www.photo.com" validate:"required"`
-	UserID   string `json:"user_id" gorm:"index" validate:"-"`
+	Title    string `json:"title" form:"title" example:"title" validate:"required"`
+	Caption  string `json:"caption,omitempty" form:"caption" example:"caption" validate:"-"`
+	PhotoUrl string `json:"photo_url" form:"-" example:"www.photo.com" validate:"required"`
+	UserID   string `json:"user_id" form:"-" gorm:"index" validate:"-"`
 }
 
 // UpdatePhoto is a struct that contains the data for update photo
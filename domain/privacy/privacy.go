@@ -0,0 +1,55 @@
+// Package privacy contains the export and the erasure of the personal data of a user
+package privacy
+
+import (
+	"time"
+
+	apiKeyDomain "hexagonal-fiber/domain/apikey"
+	commentDomain "hexagonal-fiber/domain/comment"
+	photoDomain "hexagonal-fiber/domain/photo"
+	secureDomain "hexagonal-fiber/domain/security"
+	sosmedDomain "hexagonal-fiber/domain/sosmed"
+	userDomain "hexagonal-fiber/domain/user"
+)
+
+// export statuses
+const (
+	ExportPending = "pending"
+	ExportReady   = "ready"
+	ExportFailed  = "failed"
+)
+
+// Export is a struct that contains the state of the personal data archive of a user, it is built in the background
+type Export struct {
+	ID          string     `json:"id" example:"cef47ee2-7211-452a-a087-79ce4b8ec3a3"`
+	UserID      string     `json:"user_id" example:"cef47ee2-7211-452a-a087-79ce4b8ec3a3"`
+	Status      string     `json:"status" example:"ready"`
+	RequestedAt time.Time  `json:"requested_at" example:"2021-02-24 20:19:39"`
+	CompletedAt *time.Time `json:"completed_at,omitempty" example:"2021-02-24 20:19:39"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty" example:"2021-02-25 20:19:39"`
+	Path        string     `json:"-"`
+}
+
+// Stalled reports whether the export is still pending past the time building an archive may take,
+// its build was lost with the process running it and it will never complete
+func (e *Export) Stalled(now time.Time, timeout time.Duration) bool {
+	return e.Status == ExportPending && now.Sub(e.RequestedAt) > timeout
+}
+
+// Archive is the personal data of a user, every field is written as its own JSON file of the archive
+type Archive struct {
+	Profile     *userDomain.ResponseUser   `json:"profile"`
+	Photos      []photoDomain.Photo        `json:"photos"`
+	Comments    []commentDomain.Comment    `json:"comments"`
+	SocialMedia []sosmedDomain.SocialMedia `json:"social_media"`
+	Sessions    []secureDomain.Session     `json:"sessions"`
+	Identities  []userDomain.Identity      `json:"identities"`
+	APIKeys     []apiKeyDomain.APIKey      `json:"api_keys"`
+	ExportedAt  time.Time                  `json:"exported_at"`
+}
+
+// Erasure is a struct that contains when the account of a user gets erased
+type Erasure struct {
+	UserID      string    `json:"user_id" example:"cef47ee2-7211-452a-a087-79ce4b8ec3a3"`
+	ScheduledAt time.Time `json:"scheduled_at" example:"2021-03-10 20:19:39"`
+}
@@ -0,0 +1,39 @@
+package privacy
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+)
+
+// WriteZip writes the archive as a zip holding one indented JSON file per kind of data
+func (a *Archive) WriteZip(w io.Writer) error {
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", a.Profile},
+		{"photos.json", a.Photos},
+		{"comments.json", a.Comments},
+		{"social_media.json", a.SocialMedia},
+		{"sessions.json", a.Sessions},
+		{"identities.json", a.Identities},
+		{"api_keys.json", a.APIKeys},
+	}
+
+	archive := zip.NewWriter(w)
+	for _, file := range files {
+		entry, err := archive.CreateHeader(&zip.FileHeader{Name: file.name, Method: zip.Deflate, Modified: a.ExportedAt})
+		if err != nil {
+			return err
+		}
+
+		encoder := json.NewEncoder(entry)
+		encoder.SetIndent("", "  ")
+		if err = encoder.Encode(file.data); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
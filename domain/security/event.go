@@ -0,0 +1,31 @@
+package security
+
+import "time"
+
+// security event types
+const (
+	// EventAccountLocked is published when an account is locked out after failed logins
+	EventAccountLocked = "account_locked"
+
+	// EventIPLocked is published when an IP is locked out after failed logins
+	EventIPLocked = "ip_locked"
+
+	// EventAccountUnlocked is published when an admin lifts a lockout
+	EventAccountUnlocked = "account_unlocked"
+)
+
+// Event is a struct that contains a security relevant event
+type Event struct {
+	Type       string                 `json:"type"`
+	ActorID    string                 `json:"actor_id,omitempty"`
+	UserID     string                 `json:"user_id,omitempty"`
+	Subject    string                 `json:"subject,omitempty"`
+	IP         string                 `json:"ip,omitempty"`
+	Detail     map[string]interface{} `json:"detail,omitempty"`
+	OccurredAt time.Time              `json:"occurred_at"`
+}
+
+// EventPublisher is the port publishing security events
+type EventPublisher interface {
+	Publish(event Event) error
+}
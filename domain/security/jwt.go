@@ -10,13 +10,39 @@ import (
 type AppToken struct {
 	Token          string    `json:"token"`
 	TokenType      string    `json:"type"`
+	TokenID        string    `json:"-"`
 	ExpirationTime time.Time `json:"expitationTime"`
 }
 
 // Claims is a struct that contains the claims of the JWT
 type Claims struct {
-	UserID string `json:"user_id"`
-	Type   string `json:"type"`
-	Role   string `json:"role"`
+	UserID      string   `json:"user_id"`
+	Type        string   `json:"type"`
+	Role        string   `json:"role"`
+	SessionID   string   `json:"sid"`
+	Permissions []string `json:"perms,omitempty"`
+	Version     int      `json:"ver"`
+
+	// ActorID is the admin acting through an impersonation token, UserID then is the impersonated user
+	ActorID string `json:"act,omitempty"`
+
+	// Client is the client of the request the token was presented on, it is never part of the token
+	Client ClientInfo `json:"-"`
 	jwt.StandardClaims
 }
+
+// IsImpersonated reports whether the claims were issued to an admin impersonating the user
+func (c *Claims) IsImpersonated() bool {
+	return c.ActorID != ""
+}
+
+// Can reports whether the permissions of the claims include the given one
+func (c *Claims) Can(permission string) bool {
+	for _, owned := range c.Permissions {
+		if owned == permission {
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,8 +1,79 @@
 package security
 
-import "crypto/rsa"
+import (
+	"crypto"
+	"errors"
+	"time"
+)
+
+// signing algorithms of the tokens
+const (
+	// AlgorithmHS256 signs with the shared secrets of the config, its tokens carry no kid
+	AlgorithmHS256 = "HS256"
+
+	// AlgorithmRS256 signs with a RSA key of the key ring
+	AlgorithmRS256 = "RS256"
+
+	// AlgorithmES256 signs with a P-256 ECDSA key of the key ring
+	AlgorithmES256 = "ES256"
+
+	// AlgorithmEdDSA signs with an Ed25519 key of the key ring
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// key statuses of the key ring
+const (
+	// KeyActive is the status of the only key signing new tokens
+	KeyActive = "active"
 
-var (
-	PublicKey  *rsa.PublicKey
-	PrivateKey *rsa.PrivateKey
+	// KeyNext is the status of a key published ahead of its rotation so verifiers already know it
+	KeyNext = "next"
+
+	// KeyRetiring is the status of a previous active key kept until the tokens it signed expire
+	KeyRetiring = "retiring"
 )
+
+// Key is a signing key of the key ring
+type Key struct {
+	ID         string
+	Algorithm  string
+	Status     string
+	PrivateKey crypto.PrivateKey
+	PublicKey  crypto.PublicKey
+}
+
+// ErrUnknownKey is returned when no key of the ring matches a kid
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// KeyManifest is the file listing the keys of the key ring
+type KeyManifest struct {
+	Keys []KeyManifestEntry `json:"keys"`
+}
+
+// KeyManifestEntry is a key of the key manifest, its paths are relative to the manifest
+type KeyManifestEntry struct {
+	ID         string    `json:"kid"`
+	Algorithm  string    `json:"alg,omitempty"`
+	Status     string    `json:"status"`
+	PrivateKey string    `json:"privateKey"`
+	PublicKey  string    `json:"publicKey"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// JWK is the public part of a key as published in the JWKS document
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+}
+
+// JWKS is the public JSON web key set document
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
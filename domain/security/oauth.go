@@ -0,0 +1,34 @@
+package security
+
+// OAuth token type hints, RFC 7009 section 2.1
+const (
+	TokenTypeHintAccess  = "access_token"
+	TokenTypeHintRefresh = "refresh_token"
+)
+
+// OAuthClient is a struct that contains the credentials of a service allowed on the oauth endpoints,
+// only the SHA-256 of the secret is kept in the configuration
+type OAuthClient struct {
+	ClientID           string
+	ClientSecretSHA256 string
+}
+
+// TokenRequest is a struct that contains the form body of the introspection and revocation requests
+type TokenRequest struct {
+	Token         string `json:"token" form:"token" example:"SomeToken" validate:"required"`
+	TokenTypeHint string `json:"token_type_hint,omitempty" form:"token_type_hint" example:"access_token"`
+}
+
+// Introspection is a struct that contains the state of a token, RFC 7662 section 2.2.
+// Only active is set for an inactive token so nothing leaks about it
+type Introspection struct {
+	Active    bool   `json:"active" example:"true"`
+	Scope     string `json:"scope,omitempty" example:"photo:update:any"`
+	TokenType string `json:"token_type,omitempty" example:"access_token"`
+	Subject   string `json:"sub,omitempty" example:"cef47ee2-7211-452a-a087-79ce4b8ec3a3"`
+	Role      string `json:"role,omitempty" example:"user"`
+	SessionID string `json:"sid,omitempty" example:"cef47ee2-7211-452a-a087-79ce4b8ec3a3"`
+	TokenID   string `json:"jti,omitempty" example:"cef47ee2-7211-452a-a087-79ce4b8ec3a3"`
+	ExpiresAt int64  `json:"exp,omitempty" example:"1614198579"`
+	IssuedAt  int64  `json:"iat,omitempty" example:"1614197979"`
+}
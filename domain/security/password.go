@@ -0,0 +1,11 @@
+package security
+
+// PasswordHasher is the port hashing the passwords of the users
+type PasswordHasher interface {
+	// Hash returns the stored form of the password
+	Hash(password string) (string, error)
+
+	// Verify reports whether the password matches the stored hash, and whether the hash
+	// should be replaced by a fresh one because it uses an outdated algorithm or parameters
+	Verify(password string, hash string) (match bool, rehash bool, err error)
+}
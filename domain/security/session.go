@@ -0,0 +1,21 @@
+package security
+
+import "time"
+
+// ClientInfo is a struct that contains the information of the client doing the request
+type ClientInfo struct {
+	IP        string `json:"ip"`
+	UserAgent string `json:"user_agent"`
+}
+
+// Session is a struct that contains a logged in device of the user
+type Session struct {
+	ID         string    `json:"id" example:"cef47ee2-7211-452a-a087-79ce4b8ec3a3"`
+	UserID     string    `json:"user_id" example:"cef47ee2-7211-452a-a087-79ce4b8ec3a3"`
+	IP         string    `json:"ip" example:"127.0.0.1"`
+	UserAgent  string    `json:"user_agent" example:"Mozilla/5.0"`
+	Current    bool      `json:"current" example:"true"`
+	CreatedAt  time.Time `json:"created_at" example:"2021-02-24 20:19:39"`
+	LastSeenAt time.Time `json:"last_seen_at" example:"2021-02-24 20:19:39"`
+	ExpiresAt  time.Time `json:"expires_at" example:"2021-02-24 20:19:39"`
+}
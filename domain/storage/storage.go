@@ -0,0 +1,25 @@
+// Package storage contains the blob storage port of the application
+package storage
+
+import (
+	"io"
+	"time"
+)
+
+// Object is a struct that contains a stored blob, the caller closes its body
+type Object struct {
+	Key         string
+	ContentType string
+	Size        int64
+	Body        io.ReadCloser
+}
+
+// Storage is the port implemented by every blob storage adapter
+type Storage interface {
+	Put(key string, body io.Reader, size int64, contentType string) error
+	Get(key string) (*Object, error)
+	Delete(key string) error
+	// URL returns a time limited address the blob can be downloaded from directly,
+	// it is empty when the adapter has no such address and the blob is served through the api
+	URL(key string, expiry time.Duration) (string, error)
+}
@@ -8,6 +8,7 @@ import (
 
 // Auth contains the data of the authentication
 type Auth struct {
+	SessionID                 string
 	AccessToken               string
 	RefreshToken              string
 	ExpirationAccessDateTime  time.Time
@@ -25,6 +26,7 @@ type DataUserAuthenticated struct {
 
 // DataSecurityAuthenticated is a struct that contains the security data for the authenticated user
 type DataSecurityAuthenticated struct {
+	SessionID                 string    `json:"sessionId" example:"cef47ee2-7211-452a-a087-79ce4b8ec3a3"`
 	JWTAccessToken            string    `json:"jwtAccessToken" example:"SomeAccessToken"`
 	JWTRefreshToken           string    `json:"jwtRefreshToken" example:"SomeRefreshToken"`
 	ExpirationAccessDateTime  time.Time `json:"expirationAccessDateTime" example:"2023-02-02T21:03:53.196419-06:00"`
@@ -33,6 +35,16 @@ type DataSecurityAuthenticated struct {
 
 // SecurityAuthenticatedUser is a struct that contains the data for the authenticated user
 type SecurityAuthenticatedUser struct {
-	Data     DataUserAuthenticated     `json:"data"`
-	Security DataSecurityAuthenticated `json:"security"`
+	Data          DataUserAuthenticated     `json:"data"`
+	Security      DataSecurityAuthenticated `json:"security"`
+	RecoveryCodes []string                  `json:"recoveryCodes,omitempty"`
+}
+
+// Impersonation is a struct that contains the short-lived access token letting an admin act as a user
+type Impersonation struct {
+	UserID                   string    `json:"userId" example:"cef47ee2-7211-452a-a087-79ce4b8ec3a3"`
+	ActorID                  string    `json:"actorId" example:"0c1e7f3a-2f44-4a8e-b6b2-7d9f0e1d2c03"`
+	SessionID                string    `json:"sessionId" example:"cef47ee2-7211-452a-a087-79ce4b8ec3a3"`
+	JWTAccessToken           string    `json:"jwtAccessToken" example:"SomeAccessToken"`
+	ExpirationAccessDateTime time.Time `json:"expirationAccessDateTime" example:"2023-02-02T21:03:53.196419-06:00"`
 }
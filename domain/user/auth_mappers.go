@@ -9,6 +9,7 @@ func SecAuthUserMapper(user *User, authInfo *Auth) *SecurityAuthenticatedUser {
 			RoleID:   user.RoleID,
 		},
 		Security: DataSecurityAuthenticated{
+			SessionID:                 authInfo.SessionID,
 			JWTAccessToken:            authInfo.AccessToken,
 			JWTRefreshToken:           authInfo.RefreshToken,
 			ExpirationAccessDateTime:  authInfo.ExpirationAccessDateTime,
@@ -28,6 +29,7 @@ func SecAuthUserRoleMapper(userRole *UserRole, authInfo *Auth) *SecurityAuthenti
 			Role:     userRole.Role,
 		},
 		Security: DataSecurityAuthenticated{
+			SessionID:                 authInfo.SessionID,
 			JWTAccessToken:            authInfo.AccessToken,
 			JWTRefreshToken:           authInfo.RefreshToken,
 			ExpirationAccessDateTime:  authInfo.ExpirationAccessDateTime,
@@ -0,0 +1,22 @@
+package user
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Identity is a struct that contains an external identity linked to a user, a provider subject logs in as one user only
+type Identity struct {
+	ID        uuid.UUID `json:"id" example:"cef47ee2-7211-452a-a087-79ce4b8ec3a3" gorm:"type:uuid;default:uuid_generate_v4();primarykey"`
+	UserID    string    `json:"user_id" gorm:"index;not null"`
+	Provider  string    `json:"provider" example:"google" gorm:"uniqueIndex:idx_user_identities_subject;not null"`
+	Subject   string    `json:"subject" example:"110169484474386276334" gorm:"uniqueIndex:idx_user_identities_subject;not null"`
+	Email     string    `json:"email,omitempty" example:"user@mail.com"`
+	CreatedAt time.Time `json:"created_at,omitempty" example:"2021-02-24 20:19:39" gorm:"autoCreateTime:mili"`
+}
+
+// TableName overrides the table name used by Identity to `user_identities`
+func (*Identity) TableName() string {
+	return "user_identities"
+}
@@ -0,0 +1,40 @@
+package user
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RecoveryCode is a struct that contains a hashed single use two factor recovery code
+type RecoveryCode struct {
+	ID        uuid.UUID  `json:"id" example:"cef47ee2-7211-452a-a087-79ce4b8ec3a3" gorm:"type:uuid;default:uuid_generate_v4();primarykey"`
+	UserID    string     `json:"user_id" gorm:"index"`
+	HashCode  string     `json:"-" gorm:"index"`
+	UsedAt    *time.Time `json:"used_at,omitempty" example:"2021-02-24 20:19:39"`
+	CreatedAt time.Time  `json:"created_at,omitempty" example:"2021-02-24 20:19:39" gorm:"autoCreateTime:mili"`
+}
+
+// TableName overrides the table name used by RecoveryCode to `recovery_codes`
+func (*RecoveryCode) TableName() string {
+	return "recovery_codes"
+}
+
+// MFAChallenge is a struct that contains the pending second step of a two factor login
+type MFAChallenge struct {
+	MFAToken           string    `json:"mfaToken" example:"SomeMFAToken"`
+	ExpirationDateTime time.Time `json:"expirationDateTime" example:"2023-02-02T21:03:53.196419-06:00"`
+	EnrollmentRequired bool      `json:"enrollmentRequired" example:"false"`
+}
+
+// MFAEnrollment is a struct that contains the secret of a pending two factor enrollment
+type MFAEnrollment struct {
+	Secret    string `json:"secret" example:"JBSWY3DPEHPK3PXP"`
+	OTPAuth   string `json:"otpauthUri" example:"otpauth://totp/hexagonal-fiber:user@mail.com?secret=JBSWY3DPEHPK3PXP"`
+	QRPayload string `json:"qrPayload" example:"otpauth://totp/hexagonal-fiber:user@mail.com?secret=JBSWY3DPEHPK3PXP"`
+}
+
+// RecoveryCodesResponse is a struct that contains freshly generated recovery codes
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
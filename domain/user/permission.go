@@ -0,0 +1,29 @@
+package user
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Permission is a struct that contains an action a role is allowed to perform
+type Permission struct {
+	ID        uuid.UUID `json:"id" example:"cef47ee2-7211-452a-a087-79ce4b8ec3a3" gorm:"type:uuid;default:uuid_generate_v4();primarykey"`
+	Name      string    `json:"name" example:"photo:update:any" gorm:"unique"`
+	CreatedAt time.Time `json:"created_at,omitempty" example:"2021-02-24 20:19:39" gorm:"autoCreateTime:mili"`
+}
+
+// TableName overrides the table name used by Permission to `permissions`
+func (*Permission) TableName() string {
+	return "permissions"
+}
+
+// PermissionNames returns the names of the permissions of the role
+func (role *Role) PermissionNames() []string {
+	names := make([]string, 0, len(role.Permissions))
+	for _, permission := range role.Permissions {
+		names = append(names, permission.Name)
+	}
+
+	return names
+}
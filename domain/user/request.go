@@ -6,7 +6,7 @@ type NewUser struct {
 	Email    string `json:"email" example:"user@mail.com" gorm:"unique" validate:"required,email"`
 	Password string `json:"password" example:"Pass@Word123" validate:"required,password"`
 	Age      int    `json:"age" example:"1" validate:"required"`
-	RoleID   string `json:"role_id" gorm:"index" validate:"required"`
+	RoleID   string `json:"role_id,omitempty" gorm:"index" validate:"omitempty,uuid"`
 }
 
 // UpdateUser is a struct that contains the request body for the update user
@@ -15,7 +15,8 @@ type UpdateUser struct {
 	Email    *string `json:"email,omitempty" example:"mail@mail.com" gorm:"unique" validate:"-"`
 	Password *string `json:"password,omitempty" example:"Pass@Word123" validate:"-"`
 	Age      *int    `json:"age,omitempty" example:"1" validate:"-"`
-	RoleID   *string `json:"role_id,omitempty" gorm:"index" validate:"-"`
+	// KeepLocationPublic keeps the location of the photos uploaded from now on, turning it off removes it from the photos already uploaded
+	KeepLocationPublic *bool `json:"keep_location_public,omitempty" example:"false" validate:"-"`
 }
 
 // LoginRequest is a struct that contains the request body for the login user
@@ -28,3 +29,60 @@ type LoginRequest struct {
 type AccessTokenRequest struct {
 	RefreshToken string `json:"refreshToken" example:"badbunybabybebe" validate:"required"`
 }
+
+// ResendVerificationRequest is a struct that contains the request body for resending the verification email
+type ResendVerificationRequest struct {
+	Email string `json:"email" example:"user@mail.com" validate:"required,email"`
+}
+
+// ForgotPasswordRequest is a struct that contains the request body for asking a password reset
+type ForgotPasswordRequest struct {
+	Email string `json:"email" example:"user@mail.com" validate:"required,email"`
+}
+
+// ResetPasswordRequest is a struct that contains the request body for resetting the password
+type ResetPasswordRequest struct {
+	Token    string `json:"token" example:"SomeResetToken" validate:"required"`
+	Password string `json:"password" example:"Pass@Word123" validate:"required,password"`
+}
+
+// LoginMFARequest is a struct that contains the request body for the second step of a two factor login
+type LoginMFARequest struct {
+	MFAToken string `json:"mfaToken" example:"SomeMFAToken" validate:"required"`
+	Code     string `json:"code" example:"123456" validate:"required"`
+}
+
+// MFAEnrollRequest is a struct that contains the request body for starting a two factor login enrollment
+type MFAEnrollRequest struct {
+	MFAToken string `json:"mfaToken" example:"SomeMFAToken" validate:"required"`
+}
+
+// MFACodeRequest is a struct that contains a two factor code
+type MFACodeRequest struct {
+	Code string `json:"code" example:"123456" validate:"required"`
+}
+
+// RoleMFARequest is a struct that contains the request body for requiring two factor on a role
+type RoleMFARequest struct {
+	Require bool `json:"require" example:"true"`
+}
+
+// UnlockRequest is a struct that contains the request body for lifting a login lockout
+type UnlockRequest struct {
+	Email string `json:"email" example:"mail@mail.com" validate:"required,email"`
+	IP    string `json:"ip" example:"127.0.0.1" validate:"omitempty,ip"`
+}
+
+// NewRole is a struct that contains the request body for the new role
+type NewRole struct {
+	Name        string   `json:"name" example:"moderator" validate:"required,min=3"`
+	RequireMFA  bool     `json:"require_mfa" example:"false"`
+	Permissions []string `json:"permissions" example:"photo:update:any,comment:update:any"`
+}
+
+// UpdateRole is a struct that contains the request body for the update role
+type UpdateRole struct {
+	Name        *string   `json:"name,omitempty" example:"moderator" validate:"omitempty,min=3"`
+	RequireMFA  *bool     `json:"require_mfa,omitempty" example:"false"`
+	Permissions *[]string `json:"permissions,omitempty" example:"photo:update:any,comment:update:any"`
+}
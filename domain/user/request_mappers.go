@@ -1,7 +1,5 @@
 package user
 
-import "golang.org/x/crypto/bcrypt"
-
 func (n *NewUser) ToDomainMapper() *User {
 	return &User{
 		UserName: n.UserName,
@@ -11,6 +9,7 @@ func (n *NewUser) ToDomainMapper() *User {
 	}
 }
 
+// ToDomainMapper maps the update request to a user, the password is left to the use case which hashes it
 func (n UpdateUser) ToDomainMapper() User {
 	updateDomain := User{}
 
@@ -18,11 +17,6 @@ func (n UpdateUser) ToDomainMapper() User {
 		updateDomain.UserName = *n.UserName
 	}
 
-	if n.Password != nil {
-		hash, _ := bcrypt.GenerateFromPassword([]byte(*n.Password), bcrypt.DefaultCost)
-		updateDomain.HashPassword = string(hash)
-	}
-
 	if n.Email != nil {
 		updateDomain.Email = *n.Email
 	}
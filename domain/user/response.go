@@ -6,13 +6,27 @@ import (
 
 // ResponseUser is a struct that contains the response body for the user
 type ResponseUser struct {
-	ID        string     `json:"id" example:"cef47ee2-7211-452a-a087-79ce4b8ec3a3"`
-	UserName  string     `json:"user" example:"BossonH"`
-	Email     string     `json:"email" example:"user@mail.com" gorm:"unique" validate:"required,email"`
-	Age       int        `json:"age" example:"1" validate:"required"`
-	CreatedAt time.Time  `json:"createdAt,omitempty" example:"2021-02-24 20:19:39" gorm:"autoCreateTime:mili"`
-	UpdatedAt time.Time  `json:"updatedAt,omitempty" example:"2021-02-24 20:19:39" gorm:"autoUpdateTime:mili"`
-	DeletedAt *time.Time `json:"deleted_at,omitempty" example:"null"`
+	ID                 string     `json:"id" example:"cef47ee2-7211-452a-a087-79ce4b8ec3a3"`
+	UserName           string     `json:"user" example:"BossonH"`
+	Email              string     `json:"email" example:"user@mail.com" gorm:"unique" validate:"required,email"`
+	Age                int        `json:"age" example:"1" validate:"required"`
+	Verified           bool       `json:"verified" example:"true"`
+	MFAEnabled         bool       `json:"mfa_enabled" example:"false"`
+	KeepLocationPublic bool       `json:"keep_location_public" example:"false"`
+	CreatedAt          time.Time  `json:"createdAt,omitempty" example:"2021-02-24 20:19:39" gorm:"autoCreateTime:mili"`
+	UpdatedAt          time.Time  `json:"updatedAt,omitempty" example:"2021-02-24 20:19:39" gorm:"autoUpdateTime:mili"`
+	DeletedAt          *time.Time `json:"deleted_at,omitempty" example:"null"`
+}
+
+// PaginationResponseUser is a struct that contains the pagination result for the response body of the user
+type PaginationResponseUser struct {
+	Data       []ResponseUser
+	Total      int
+	Limit      int
+	Current    int
+	NextCursor uint
+	PrevCursor uint
+	NumPages   int
 }
 
 // ResponseUser is a struct that contains the response body for the user
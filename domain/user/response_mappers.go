@@ -10,15 +10,23 @@ func (userRole *UserRole) UserToResponseMapper() (createUserRoleResponse *Respon
 }
 
 func (user *User) DomainToResponseMapper() (createUserResponse *ResponseUser) {
-	return &ResponseUser{
-		ID:        user.ID.String(),
-		UserName:  user.UserName,
-		Email:     user.Email,
-		Age:       user.Age,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+	createUserResponse = &ResponseUser{
+		ID:                 user.ID.String(),
+		UserName:           user.UserName,
+		Email:              user.Email,
+		Age:                user.Age,
+		Verified:           user.VerifiedAt != nil,
+		MFAEnabled:         user.MFAEnabledAt != nil,
+		KeepLocationPublic: user.KeepLocationPublic,
+		CreatedAt:          user.CreatedAt,
+		UpdatedAt:          user.UpdatedAt,
 	}
 
+	if user.DeletedAt.Valid {
+		createUserResponse.DeletedAt = &user.DeletedAt.Time
+	}
+
+	return
 }
 
 func ArrayDomainToResponseMapper(users *[]User) *[]ResponseUser {
@@ -28,3 +36,15 @@ func ArrayDomainToResponseMapper(users *[]User) *[]ResponseUser {
 	}
 	return &usersResponse
 }
+
+func (pagination *PaginationUser) ToResponseMapper() *PaginationResponseUser {
+	return &PaginationResponseUser{
+		Data:       *ArrayDomainToResponseMapper(&pagination.Data),
+		Total:      pagination.Total,
+		Limit:      pagination.Limit,
+		Current:    pagination.Current,
+		NextCursor: pagination.NextCursor,
+		PrevCursor: pagination.PrevCursor,
+		NumPages:   pagination.NumPages,
+	}
+}
@@ -3,10 +3,12 @@ package user
 // ToRoleDomainMapper function to convert role of user role repo to role domain
 func (userRole *UserRole) ToRoleDomainMapper() *Role {
 	return &Role{
-		ID:        userRole.Role.ID,
-		Name:      userRole.Role.Name,
-		CreatedAt: userRole.CreatedAt,
-		UpdatedAt: userRole.UpdatedAt,
+		ID:          userRole.Role.ID,
+		Name:        userRole.Role.Name,
+		RequireMFA:  userRole.Role.RequireMFA,
+		Permissions: userRole.Role.Permissions,
+		CreatedAt:   userRole.CreatedAt,
+		UpdatedAt:   userRole.UpdatedAt,
 	}
 }
 
@@ -18,6 +20,9 @@ func (userRole *UserRole) ToDomainMapper() *User {
 		Email:        userRole.Email,
 		HashPassword: userRole.HashPassword,
 		RoleID:       userRole.RoleID,
+		VerifiedAt:   userRole.VerifiedAt,
+		TOTPSecret:   userRole.TOTPSecret,
+		MFAEnabledAt: userRole.MFAEnabledAt,
 		CreatedAt:    userRole.CreatedAt,
 		UpdatedAt:    userRole.UpdatedAt,
 	}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // User is a struct that contains the user information
@@ -15,9 +16,19 @@ type User struct {
 	HashPassword string     `json:"hash_password" example:"has@Password1"`
 	Age          int        `json:"age" example:"1" validate:"required"`
 	RoleID       string     `json:"role_id" gorm:"index"`
-	CreatedAt    time.Time  `json:"created_at,omitempty" example:"2021-02-24 20:19:39" gorm:"autoCreateTime:mili"`
-	UpdatedAt    time.Time  `json:"updated_at,omitempty" example:"2021-02-24 20:19:39" gorm:"autoUpdateTime:mili"`
-	DeletedAt    *time.Time `json:"deleted_at,omitempty" example:"null"`
+	VerifiedAt   *time.Time `json:"verified_at,omitempty" example:"2021-02-24 20:19:39"`
+	TOTPSecret   string     `json:"-" gorm:"column:totp_secret"`
+	MFAEnabledAt *time.Time `json:"mfa_enabled_at,omitempty" example:"2021-02-24 20:19:39" gorm:"column:mfa_enabled_at"`
+	TokenVersion int        `json:"-" gorm:"not null;default:0"`
+	// TOTPLastStep is the time step of the last two factor code accepted, a code is only accepted once
+	TOTPLastStep int64 `json:"-" gorm:"column:totp_last_step;not null;default:0"`
+	// KeepLocationPublic keeps the location read from the EXIF of the uploaded photos on them, it is dropped otherwise
+	KeepLocationPublic bool `json:"keep_location_public" gorm:"not null;default:false"`
+	// ErasureScheduledAt is when the account and everything it owns gets erased, unless the user cancels before
+	ErasureScheduledAt *time.Time     `json:"erasure_scheduled_at,omitempty" example:"2021-02-24 20:19:39" gorm:"index"`
+	CreatedAt          time.Time      `json:"created_at,omitempty" example:"2021-02-24 20:19:39" gorm:"autoCreateTime:mili"`
+	UpdatedAt          time.Time      `json:"updated_at,omitempty" example:"2021-02-24 20:19:39" gorm:"autoUpdateTime:mili"`
+	DeletedAt          gorm.DeletedAt `json:"deleted_at,omitempty" swaggertype:"string" example:"null" gorm:"index"`
 }
 
 // TableName overrides the table name used by User to `users`
@@ -0,0 +1,273 @@
+package album
+
+import (
+	albumDomain "hexagonal-fiber/domain/album"
+	photoDomain "hexagonal-fiber/domain/photo"
+
+	mssgConst "hexagonal-fiber/utils/constant/message"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repository is a struct that contains the database implementation for album entity
+type Repository struct {
+	DB *gorm.DB
+}
+
+// withPhotoCount selects the albums along with how many photos they show, the deleted photos are not counted
+func (r *Repository) withPhotoCount() *gorm.DB {
+	return r.DB.Model(&albumDomain.Album{}).Select("albums.*, (?) AS photo_count",
+		r.DB.Model(&albumDomain.AlbumPhoto{}).Select("COUNT(*)").
+			Joins("JOIN photos ON CAST(photos.id AS text) = album_photos.photo_id AND photos.deleted_at IS NULL").
+			Where("album_photos.album_id = CAST(albums.id AS text)"))
+}
+
+// GetPublic Fetch the public albums of every user, latest first
+func (r *Repository) GetPublic(page int, limit int) (*albumDomain.PaginationAlbum, error) {
+	return r.paginate(page, limit, "albums.visibility = ?", albumDomain.Public)
+}
+
+// UserGetAll Fetch the albums of a user whatever their visibility, latest first
+func (r *Repository) UserGetAll(userId string, page int, limit int) (*albumDomain.PaginationAlbum, error) {
+	return r.paginate(page, limit, "albums.user_id = ?", userId)
+}
+
+func (r *Repository) paginate(page int, limit int, query string, args ...interface{}) (*albumDomain.PaginationAlbum, error) {
+	var data []albumDomain.Album
+	var total int64
+
+	if err := r.DB.Model(&albumDomain.Album{}).Where(query, args...).Count(&total).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	offset := (page - 1) * limit
+	err := r.withPhotoCount().Where(query, args...).
+		Order("albums.created_at DESC, albums.id").Limit(limit).Offset(offset).Find(&data).Error
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	numPages := (total + int64(limit) - 1) / int64(limit)
+	var nextCursor, prevCursor uint
+	if page < int(numPages) {
+		nextCursor = uint(page + 1)
+	}
+	if page > 1 {
+		prevCursor = uint(page - 1)
+	}
+
+	return &albumDomain.PaginationAlbum{
+		Data:       &data,
+		Total:      total,
+		Limit:      int64(limit),
+		Current:    int64(page),
+		NextCursor: nextCursor,
+		PrevCursor: prevCursor,
+		NumPages:   numPages,
+	}, nil
+}
+
+// GetByID ... Fetch only one album by Id
+func (r *Repository) GetByID(id string) (*albumDomain.Album, error) {
+	var album albumDomain.Album
+	err := r.withPhotoCount().Where("albums.id = ?", id).First(&album).Error
+
+	if err != nil {
+		switch err.Error() {
+		case gorm.ErrRecordNotFound.Error():
+			return nil, fiber.NewError(fiber.StatusNotFound, "album not found")
+		default:
+			return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+		}
+	}
+
+	return &album, nil
+}
+
+// Create ... Insert New data
+func (r *Repository) Create(newAlbum *albumDomain.Album) (*albumDomain.Album, error) {
+	if err := r.DB.Create(newAlbum).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return newAlbum, nil
+}
+
+// Update ... Update the given columns of an album, zero values included
+func (r *Repository) Update(id string, columns map[string]interface{}) (*albumDomain.Album, error) {
+	if err := r.DB.Model(&albumDomain.Album{}).Where("id = ?", id).Updates(columns).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return r.GetByID(id)
+}
+
+// Delete ... Permanently delete an album and its links, the photos stay. An album only goes to the trash along with its owner
+func (r *Repository) Delete(id string) (err error) {
+	var deleted int64
+	err = r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("album_id = ?", id).Delete(&albumDomain.AlbumPhoto{}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Unscoped().Where("id = ?", id).Delete(&albumDomain.Album{})
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	if deleted == 0 {
+		return fiber.NewError(fiber.StatusNotFound, "album not found")
+	}
+
+	return
+}
+
+// GetPhotos Fetch the photos an album shows in its ordering, the deleted photos are left out
+func (r *Repository) GetPhotos(album *albumDomain.Album, page int, limit int) (*photoDomain.PaginationPhoto, error) {
+	var photos []photoDomain.Photo
+	var total int64
+
+	linked := func() *gorm.DB {
+		return r.DB.Model(&photoDomain.Photo{}).
+			Joins("JOIN album_photos ON album_photos.photo_id = CAST(photos.id AS text)").
+			Where("album_photos.album_id = ?", album.ID.String())
+	}
+
+	if err := linked().Count(&total).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	order := "album_photos.position, photos.id"
+	switch album.Ordering {
+	case albumDomain.Newest:
+		order = "photos.created_at DESC, photos.id"
+	case albumDomain.Oldest:
+		order = "photos.created_at, photos.id"
+	case albumDomain.Taken:
+		order = "photos.taken_at NULLS LAST, photos.id"
+	}
+
+	offset := (page - 1) * limit
+	if err := linked().Preload("Variants").Order(order).Limit(limit).Offset(offset).Find(&photos).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	numPages := (total + int64(limit) - 1) / int64(limit)
+	var nextCursor, prevCursor uint
+	if page < int(numPages) {
+		nextCursor = uint(page + 1)
+	}
+	if page > 1 {
+		prevCursor = uint(page - 1)
+	}
+
+	return &photoDomain.PaginationPhoto{
+		Data:       photoDomain.ArrayToDomainMapper(&photos),
+		Total:      total,
+		Limit:      int64(limit),
+		Current:    int64(page),
+		NextCursor: nextCursor,
+		PrevCursor: prevCursor,
+		NumPages:   numPages,
+	}, nil
+}
+
+// PhotoIDs ... Fetch the ids of the photos an album shows by their position, the deleted photos are left out
+func (r *Repository) PhotoIDs(albumId string) ([]string, error) {
+	var ids []string
+	err := r.DB.Model(&photoDomain.Photo{}).
+		Joins("JOIN album_photos ON album_photos.photo_id = CAST(photos.id AS text)").
+		Where("album_photos.album_id = ?", albumId).
+		Order("album_photos.position, album_photos.photo_id").Pluck("album_photos.photo_id", &ids).Error
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return ids, nil
+}
+
+// CountUserPhotos ... Count how many of the given ids are photos of the user
+func (r *Repository) CountUserPhotos(userId string, ids []string) (int64, error) {
+	var count int64
+	if err := r.DB.Model(&photoDomain.Photo{}).Where("user_id = ? AND CAST(id AS text) IN ?", userId, ids).Count(&count).Error; err != nil {
+		return 0, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return count, nil
+}
+
+// AddPhotos ... Link the photos to an album after the ones it has in the given order, the photos it has already keep their place
+func (r *Repository) AddPhotos(albumId string, photoIds []string) (added int64, err error) {
+	err = r.DB.Transaction(func(tx *gorm.DB) error {
+		var last int
+		err := tx.Model(&albumDomain.AlbumPhoto{}).Where("album_id = ?", albumId).Select("COALESCE(MAX(position), 0)").Scan(&last).Error
+		if err != nil {
+			return err
+		}
+
+		links := make([]albumDomain.AlbumPhoto, len(photoIds))
+		for i, photoId := range photoIds {
+			links[i] = albumDomain.AlbumPhoto{AlbumID: albumId, PhotoID: photoId, Position: last + i + 1}
+		}
+
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&links)
+		added = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return 0, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return
+}
+
+// RemovePhotos ... Unlink the photos from an album, the cover is removed along when it is one of them
+func (r *Repository) RemovePhotos(albumId string, photoIds []string) (removed int64, err error) {
+	err = r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("album_id = ? AND photo_id IN ?", albumId, photoIds).Delete(&albumDomain.AlbumPhoto{})
+		if result.Error != nil {
+			return result.Error
+		}
+		removed = result.RowsAffected
+
+		return tx.Model(&albumDomain.Album{}).Where("id = ? AND cover_photo_id IN ?", albumId, photoIds).
+			UpdateColumn("cover_photo_id", nil).Error
+	})
+	if err != nil {
+		return 0, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return
+}
+
+// Reorder ... Move the photos of an album to the position they have in the given ids, the photos linked but not
+// given, the deleted ones, are moved after them in the order they had
+func (r *Repository) Reorder(albumId string, photoIds []string) error {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&albumDomain.AlbumPhoto{}).Where("album_id = ? AND photo_id NOT IN ?", albumId, photoIds).
+			UpdateColumn("position", gorm.Expr("position + ?", len(photoIds))).Error
+		if err != nil {
+			return err
+		}
+
+		for i, photoId := range photoIds {
+			err := tx.Model(&albumDomain.AlbumPhoto{}).Where("album_id = ? AND photo_id = ?", albumId, photoId).
+				UpdateColumn("position", i+1).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+// Package apikey contains the database implementation for api keys
+package apikey
+
+import (
+	"time"
+
+	apiKeyDomain "hexagonal-fiber/domain/apikey"
+
+	mssgConst "hexagonal-fiber/utils/constant/message"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// lastUsedPrecision is how stale the last used time of a key may be, so a busy key does not write on every request
+const lastUsedPrecision = time.Minute
+
+// Repository is a struct that contains the database implementation for api key entity
+type Repository struct {
+	DB *gorm.DB
+}
+
+// Create ... Insert New data
+func (r *Repository) Create(newKey *apiKeyDomain.APIKey) (*apiKeyDomain.APIKey, error) {
+	if err := r.DB.Create(newKey).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return newKey, nil
+}
+
+// GetByID ... Fetch only one api key by ID
+func (r *Repository) GetByID(id string) (*apiKeyDomain.APIKey, error) {
+	return r.getOne("id = ?", id)
+}
+
+// GetByPrefix ... Fetch only one api key by the public prefix of the key
+func (r *Repository) GetByPrefix(prefix string) (*apiKeyDomain.APIKey, error) {
+	return r.getOne("prefix = ?", prefix)
+}
+
+// UserGetAll ... Fetch the api keys owned by the user, newest first
+func (r *Repository) UserGetAll(userID string) (*[]apiKeyDomain.APIKey, error) {
+	var keys []apiKeyDomain.APIKey
+	if err := r.DB.Where("user_id = ?", userID).Order("created_at desc").Find(&keys).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return &keys, nil
+}
+
+// TouchLastUsed ... Set the last used time of the api key unless it was set less than a minute ago
+func (r *Repository) TouchLastUsed(id string, usedAt time.Time) error {
+	err := r.DB.Model(&apiKeyDomain.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, usedAt.Add(-lastUsedPrecision)).
+		Update("last_used_at", usedAt).Error
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return nil
+}
+
+// Delete ... Delete the api key
+func (r *Repository) Delete(id string) error {
+	tx := r.DB.Where("id = ?", id).Delete(&apiKeyDomain.APIKey{})
+	if tx.Error != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	if tx.RowsAffected == 0 {
+		return fiber.NewError(fiber.StatusNotFound, "api key not found")
+	}
+
+	return nil
+}
+
+func (r *Repository) getOne(query string, value string) (*apiKeyDomain.APIKey, error) {
+	var key apiKeyDomain.APIKey
+	err := r.DB.Where(query, value).First(&key).Error
+
+	if err != nil {
+		switch err.Error() {
+		case gorm.ErrRecordNotFound.Error():
+			return nil, fiber.NewError(fiber.StatusNotFound, "api key not found")
+		default:
+			return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+		}
+	}
+
+	return &key, nil
+}
@@ -0,0 +1,93 @@
+// Package audit contains the append-only storage of the audit log
+package audit
+
+import (
+	auditDomain "hexagonal-fiber/domain/audit"
+
+	mssgConst "hexagonal-fiber/utils/constant/message"
+
+	"github.com/gofiber/fiber/v2"
+
+	"gorm.io/gorm"
+)
+
+// Repository is a struct that contains the database implementation for audit event entity.
+// It only inserts and reads, the table itself refuses updates and deletes
+type Repository struct {
+	DB *gorm.DB
+}
+
+// Record appends an event to the audit log
+func (r *Repository) Record(event auditDomain.Event) error {
+	if err := r.DB.Create(&event).Error; err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return nil
+}
+
+// GetAll Fetch the audit events matching the filter, newest first
+func (r *Repository) GetAll(filter auditDomain.Filter, page int, limit int) (*auditDomain.PaginationEvent, error) {
+	var events []auditDomain.Event
+	var total int64
+
+	query := r.filtered(filter)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	offset := (page - 1) * limit
+	err := r.filtered(filter).Order("occurred_at desc, id desc").Limit(limit).Offset(offset).Find(&events).Error
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	numPages := (total + int64(limit) - 1) / int64(limit)
+	var nextCursor, prevCursor uint
+	if page < int(numPages) {
+		nextCursor = uint(page + 1)
+	}
+	if page > 1 {
+		prevCursor = uint(page - 1)
+	}
+
+	return &auditDomain.PaginationEvent{
+		Data:       &events,
+		Total:      total,
+		Limit:      int64(limit),
+		Current:    int64(page),
+		NextCursor: nextCursor,
+		PrevCursor: prevCursor,
+		NumPages:   numPages,
+	}, nil
+}
+
+func (r *Repository) filtered(filter auditDomain.Filter) *gorm.DB {
+	query := r.DB.Model(&auditDomain.Event{})
+
+	if filter.ActorID != "" {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+
+	if filter.TargetID != "" {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+
+	if filter.From != nil {
+		query = query.Where("occurred_at >= ?", *filter.From)
+	}
+
+	if filter.To != nil {
+		query = query.Where("occurred_at < ?", *filter.To)
+	}
+
+	return query
+}
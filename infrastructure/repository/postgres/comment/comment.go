@@ -2,6 +2,8 @@ package comment
 
 import (
 	"encoding/json"
+	"time"
+
 	commentDomain "hexagonal-fiber/domain/comment"
 	errorDomain "hexagonal-fiber/domain/error"
 	"log"
@@ -64,7 +66,7 @@ func (r *Repository) UserGetAll(userId string, page int, limit int) (*commentDom
 		return nil, err
 	}
 	offset := (page - 1) * limit
-	err = r.DB.Limit(limit).Offset(offset).Find(&comments).Error
+	err = r.DB.Where("user_id = ?", userId).Order("created_at, id").Limit(limit).Offset(offset).Find(&comments).Error
 
 	if err != nil {
 		return nil, err
@@ -238,7 +240,7 @@ func (r *Repository) UserUpdate(id string, userId string, updateComment *comment
 	return &comment, nil
 }
 
-// Delete ... Delete comment
+// Delete ... Move comment to the trash
 func (r *Repository) Delete(id string) (err error) {
 	tx := r.DB.Where("id = ?", id).Delete(&commentDomain.Comment{})
 
@@ -253,3 +255,81 @@ func (r *Repository) Delete(id string) (err error) {
 
 	return
 }
+
+// UserGetTrash Fetch the deleted comments of a user, latest deleted first
+func (r *Repository) UserGetTrash(userId string, page int, limit int) (*commentDomain.PaginationComment, error) {
+	var comments []commentDomain.Comment
+	var total int64
+
+	trash := r.DB.Unscoped().Model(&commentDomain.Comment{}).Where("user_id = ? AND deleted_at IS NOT NULL", userId)
+	if err := trash.Count(&total).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	offset := (page - 1) * limit
+	err := r.DB.Unscoped().Where("user_id = ? AND deleted_at IS NOT NULL", userId).
+		Order("deleted_at DESC, id").Limit(limit).Offset(offset).Find(&comments).Error
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	numPages := (total + int64(limit) - 1) / int64(limit)
+	var nextCursor, prevCursor uint
+	if page < int(numPages) {
+		nextCursor = uint(page + 1)
+	}
+	if page > 1 {
+		prevCursor = uint(page - 1)
+	}
+
+	return &commentDomain.PaginationComment{
+		Data:       commentDomain.ArrayToDomainMapper(&comments),
+		Total:      total,
+		Limit:      int64(limit),
+		Current:    int64(page),
+		NextCursor: nextCursor,
+		PrevCursor: prevCursor,
+		NumPages:   numPages,
+	}, nil
+}
+
+// GetDeletedByID ... Fetch only one comment of the trash by Id
+func (r *Repository) GetDeletedByID(id string) (*commentDomain.Comment, error) {
+	var comment commentDomain.Comment
+	err := r.DB.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&comment).Error
+
+	if err != nil {
+		switch err.Error() {
+		case gorm.ErrRecordNotFound.Error():
+			return nil, fiber.NewError(fiber.StatusNotFound, "comment not found in trash")
+		default:
+			return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+		}
+	}
+
+	return &comment, nil
+}
+
+// Restore ... Bring a deleted comment back
+func (r *Repository) Restore(id string) (err error) {
+	tx := r.DB.Unscoped().Model(&commentDomain.Comment{}).Where("id = ? AND deleted_at IS NOT NULL", id).UpdateColumn("deleted_at", nil)
+	if tx.Error != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	if tx.RowsAffected == 0 {
+		return fiber.NewError(fiber.StatusNotFound, "comment not found in trash")
+	}
+
+	return
+}
+
+// Purge ... Permanently delete the comments in the trash since before the given time
+func (r *Repository) Purge(before time.Time) (int64, error) {
+	tx := r.DB.Unscoped().Where("deleted_at < ?", before).Delete(&commentDomain.Comment{})
+	if tx.Error != nil {
+		return 0, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return tx.RowsAffected, nil
+}
@@ -0,0 +1,83 @@
+// Package identity contains the database implementation for the external identities of the users
+package identity
+
+import (
+	userDomain "hexagonal-fiber/domain/user"
+
+	mssgConst "hexagonal-fiber/utils/constant/message"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// Repository is a struct that contains the database implementation for identity entity
+type Repository struct {
+	DB *gorm.DB
+}
+
+// GetBySubject ... Fetch the identity of the subject at the provider
+func (r *Repository) GetBySubject(provider string, subject string) (*userDomain.Identity, error) {
+	var identity userDomain.Identity
+	err := r.DB.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+
+	if err != nil {
+		switch err.Error() {
+		case gorm.ErrRecordNotFound.Error():
+			return nil, fiber.NewError(fiber.StatusNotFound, "identity not found")
+		default:
+			return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+		}
+	}
+
+	return &identity, nil
+}
+
+// UserGetAll ... Fetch the identities linked to the user
+func (r *Repository) UserGetAll(userID string) (*[]userDomain.Identity, error) {
+	var identities []userDomain.Identity
+	if err := r.DB.Where("user_id = ?", userID).Order("created_at").Find(&identities).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return &identities, nil
+}
+
+// Create ... Link the identity to its user
+func (r *Repository) Create(identity *userDomain.Identity) (*userDomain.Identity, error) {
+	if err := r.DB.Create(identity).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return identity, nil
+}
+
+// CreateWithUser ... Insert a new user and link the identity to it, both or none are stored
+func (r *Repository) CreateWithUser(user *userDomain.User, identity *userDomain.Identity) (*userDomain.User, error) {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+
+		identity.UserID = user.ID.String()
+		return tx.Create(identity).Error
+	})
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return user, nil
+}
+
+// Delete ... Unlink an identity of the user
+func (r *Repository) Delete(userID string, id string) error {
+	tx := r.DB.Where("id = ? AND user_id = ?", id, userID).Delete(&userDomain.Identity{})
+	if tx.Error != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	if tx.RowsAffected == 0 {
+		return fiber.NewError(fiber.StatusNotFound, "identity not found")
+	}
+
+	return nil
+}
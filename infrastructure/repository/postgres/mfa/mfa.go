@@ -0,0 +1,64 @@
+// Package mfa contains the database implementation for two factor recovery codes
+package mfa
+
+import (
+	"time"
+
+	userDomain "hexagonal-fiber/domain/user"
+
+	mssgConst "hexagonal-fiber/utils/constant/message"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// Repository is a struct that contains the database implementation for recovery code entity
+type Repository struct {
+	DB *gorm.DB
+}
+
+// ReplaceRecoveryCodes ... Replace every recovery code of the user with the given hashed codes
+func (r *Repository) ReplaceRecoveryCodes(userID string, hashCodes []string) error {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&userDomain.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		codes := make([]userDomain.RecoveryCode, len(hashCodes))
+		for i, hashCode := range hashCodes {
+			codes[i] = userDomain.RecoveryCode{UserID: userID, HashCode: hashCode}
+		}
+
+		return tx.Create(&codes).Error
+	})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return nil
+}
+
+// UseRecoveryCode ... Mark an unused recovery code of the user as used
+func (r *Repository) UseRecoveryCode(userID string, hashCode string) error {
+	tx := r.DB.Model(&userDomain.RecoveryCode{}).
+		Where("user_id = ? AND hash_code = ? AND used_at IS NULL", userID, hashCode).
+		Update("used_at", time.Now())
+	if tx.Error != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	if tx.RowsAffected == 0 {
+		return fiber.NewError(fiber.StatusUnauthorized, "invalid two factor code")
+	}
+
+	return nil
+}
+
+// DeleteRecoveryCodes ... Delete every recovery code of the user
+func (r *Repository) DeleteRecoveryCodes(userID string) error {
+	if err := r.DB.Where("user_id = ?", userID).Delete(&userDomain.RecoveryCode{}).Error; err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return nil
+}
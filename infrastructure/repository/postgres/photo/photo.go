@@ -2,18 +2,20 @@ package photo
 
 import (
 	"encoding/json"
+	"time"
+
+	albumDomain "hexagonal-fiber/domain/album"
 	commentDomain "hexagonal-fiber/domain/comment"
 	errorDomain "hexagonal-fiber/domain/error"
 	photoDomain "hexagonal-fiber/domain/photo"
 
 	mssgConst "hexagonal-fiber/utils/constant/message"
 
-	"log"
-
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Repository is a struct that contains the database implementation for photo entity
@@ -32,7 +34,7 @@ func (r *Repository) GetAll(page int, limit int) (*photoDomain.PaginationPhoto,
 	}
 
 	offset := (page - 1) * limit
-	if err = r.DB.Limit(limit).Offset(offset).Find(&photos).Error; err != nil {
+	if err = r.DB.Preload("Variants").Limit(limit).Offset(offset).Find(&photos).Error; err != nil {
 		return nil, err
 	}
 
@@ -67,7 +69,7 @@ func (r *Repository) UserGetAll(userId string, page int, limit int) (*photoDomai
 	}
 
 	offset := (page - 1) * limit
-	if err = r.DB.Limit(limit).Offset(offset).Find(&photos).Error; err != nil {
+	if err = r.DB.Preload("Variants").Where("user_id = ?", userId).Order("created_at, id").Limit(limit).Offset(offset).Find(&photos).Error; err != nil {
 		return nil, err
 	}
 
@@ -109,7 +111,7 @@ func (r *Repository) GetWithComments(id string, page int, limit int) (*photoDoma
 	}
 
 	photoComments.ID = uuid
-	err = r.DB.Model(&photoDomain.Photo{}).Preload("Comment").Limit(limit).Offset(offset).First(&photoComments).Error
+	err = r.DB.Model(&photoDomain.Photo{}).Preload("Comment").Preload("Variants").Limit(limit).Offset(offset).First(&photoComments).Error
 	if err != nil {
 		switch err.Error() {
 		case gorm.ErrRecordNotFound.Error():
@@ -149,7 +151,7 @@ func (r *Repository) GetWithComments(id string, page int, limit int) (*photoDoma
 // GetByID ... Fetch only one photo by Id
 func (r *Repository) GetByID(id string) (*photoDomain.Photo, error) {
 	var photo photoDomain.Photo
-	err := r.DB.Where("id = ?", id).First(&photo).Error
+	err := r.DB.Preload("Variants").Where("id = ?", id).First(&photo).Error
 
 	if err != nil {
 		switch err.Error() {
@@ -166,7 +168,7 @@ func (r *Repository) GetByID(id string) (*photoDomain.Photo, error) {
 // UserGetByID ... Fetch only one photo by Id
 func (r *Repository) UserGetByID(id string, userId string) (*photoDomain.Photo, error) {
 	var photo photoDomain.Photo
-	err := r.DB.Where("id = ?", id).Where("user_id = ?", userId).First(&photo).Error
+	err := r.DB.Preload("Variants").Where("id = ?", id).Where("user_id = ?", userId).First(&photo).Error
 
 	if err != nil {
 		switch err.Error() {
@@ -245,7 +247,7 @@ func (r *Repository) Update(id string, updatePhoto *photoDomain.Photo) (*photoDo
 		}
 	}
 
-	err = r.DB.Where("id = ?", id).First(&photo).Error
+	err = r.DB.Preload("Variants").Where("id = ?", id).First(&photo).Error
 	if err != nil {
 		return nil, fiber.NewError(fiber.StatusNotFound, "photo not found")
 	}
@@ -284,7 +286,7 @@ func (r *Repository) UserUpdate(id string, userId string, updatePhoto *photoDoma
 		}
 	}
 
-	err = r.DB.Where("id = ?", id).First(&photo).Error
+	err = r.DB.Preload("Variants").Where("id = ?", id).First(&photo).Error
 	if err != nil {
 		return nil, fiber.NewError(fiber.StatusNotFound, "photo not found")
 	}
@@ -292,18 +294,279 @@ func (r *Repository) UserUpdate(id string, userId string, updatePhoto *photoDoma
 	return &photo, nil
 }
 
-// Delete ... Delete photo
+// Delete ... Move photo to the trash along with its comments, they share the deletion time so a restore brings them back together
 func (r *Repository) Delete(id string) (err error) {
-	tx := r.DB.Where("id = ?", id).Delete(&photoDomain.Photo{})
+	var deleted int64
+	err = r.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
 
-	log.Println("check ", tx)
-	if tx.Error != nil {
+		result := tx.Model(&photoDomain.Photo{}).Where("id = ?", id).UpdateColumn("deleted_at", now)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		deleted = result.RowsAffected
+
+		return tx.Model(&commentDomain.Comment{}).Where("photo_id = ?", id).UpdateColumn("deleted_at", now).Error
+	})
+	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
 	}
 
-	if tx.RowsAffected == 0 {
+	if deleted == 0 {
 		return fiber.NewError(fiber.StatusNotFound, "photo not found")
 	}
 
 	return
 }
+
+// UserGetTrash Fetch the deleted photos of a user, latest deleted first
+func (r *Repository) UserGetTrash(userId string, page int, limit int) (*photoDomain.PaginationPhoto, error) {
+	var photos []photoDomain.Photo
+	var total int64
+
+	trash := r.DB.Unscoped().Model(&photoDomain.Photo{}).Where("user_id = ? AND deleted_at IS NOT NULL", userId)
+	if err := trash.Count(&total).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	offset := (page - 1) * limit
+	err := r.DB.Unscoped().Preload("Variants").Where("user_id = ? AND deleted_at IS NOT NULL", userId).
+		Order("deleted_at DESC, id").Limit(limit).Offset(offset).Find(&photos).Error
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	numPages := (total + int64(limit) - 1) / int64(limit)
+	var nextCursor, prevCursor uint
+	if page < int(numPages) {
+		nextCursor = uint(page + 1)
+	}
+	if page > 1 {
+		prevCursor = uint(page - 1)
+	}
+
+	return &photoDomain.PaginationPhoto{
+		Data:       photoDomain.ArrayToDomainMapper(&photos),
+		Total:      total,
+		Limit:      int64(limit),
+		Current:    int64(page),
+		NextCursor: nextCursor,
+		PrevCursor: prevCursor,
+		NumPages:   numPages,
+	}, nil
+}
+
+// GetDeletedByID ... Fetch only one photo of the trash by Id
+func (r *Repository) GetDeletedByID(id string) (*photoDomain.Photo, error) {
+	var photo photoDomain.Photo
+	err := r.DB.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&photo).Error
+
+	if err != nil {
+		switch err.Error() {
+		case gorm.ErrRecordNotFound.Error():
+			return nil, fiber.NewError(fiber.StatusNotFound, "photo not found in trash")
+		default:
+			return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+		}
+	}
+
+	return &photo, nil
+}
+
+// Restore ... Bring a deleted photo back along with the comments deleted with it
+func (r *Repository) Restore(photo *photoDomain.Photo) (err error) {
+	err = r.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Model(&commentDomain.Comment{}).
+			Where("photo_id = ? AND deleted_at = ?", photo.ID.String(), photo.DeletedAt.Time).
+			UpdateColumn("deleted_at", nil).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Model(&photoDomain.Photo{}).Where("id = ?", photo.ID).UpdateColumn("deleted_at", nil).Error
+	})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return
+}
+
+// Purge ... Permanently delete the photos in the trash since before the given time along with their comments
+func (r *Repository) Purge(before time.Time) (purged int64, err error) {
+	err = r.DB.Transaction(func(tx *gorm.DB) error {
+		expired := tx.Unscoped().Model(&photoDomain.Photo{}).Select("CAST(id AS text)").Where("deleted_at < ?", before)
+		if err := tx.Unscoped().Where("photo_id IN (?)", expired).Delete(&commentDomain.Comment{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("photo_id IN (?)", expired).Delete(&photoDomain.Variant{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("photo_id IN (?)", expired).Delete(&photoDomain.Duplicate{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("photo_id IN (?)", expired).Delete(&albumDomain.AlbumPhoto{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&albumDomain.Album{}).Where("cover_photo_id IN (?)", expired).UpdateColumn("cover_photo_id", nil).Error; err != nil {
+			return err
+		}
+
+		result := tx.Unscoped().Where("deleted_at < ?", before).Delete(&photoDomain.Photo{})
+		purged = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return 0, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return
+}
+
+// UserObjectKeys ... Fetch the storage keys of every uploaded image of a user and of its variants, the trash included
+func (r *Repository) UserObjectKeys(userId string) ([]string, error) {
+	return r.objectKeys(r.DB.Unscoped().Model(&photoDomain.Photo{}).Where("user_id = ?", userId))
+}
+
+// DeletedObjectKeys ... Fetch the storage keys of the uploaded images in the trash since before the given time and of their variants
+func (r *Repository) DeletedObjectKeys(before time.Time) ([]string, error) {
+	return r.objectKeys(r.DB.Unscoped().Model(&photoDomain.Photo{}).Where("deleted_at < ?", before))
+}
+
+func (r *Repository) objectKeys(photos *gorm.DB) ([]string, error) {
+	var keys, variantKeys []string
+	if err := photos.Session(&gorm.Session{}).Where("object_key <> ''").Pluck("object_key", &keys).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	ids := photos.Session(&gorm.Session{}).Select("CAST(id AS text)")
+	if err := r.DB.Model(&photoDomain.Variant{}).Where("photo_id IN (?)", ids).Pluck("object_key", &variantKeys).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return append(keys, variantKeys...), nil
+}
+
+// hammingDistance is the number of bits the perceptual hash of a photo differs from the given one by
+const hammingDistance = "length(replace(CAST(CAST(perceptual_hash # ? AS bit(64)) AS text), '0', ''))"
+
+// UserNearDuplicates ... Fetch the ids of the photos of a user whose perceptual hash is within the distance of the given one
+func (r *Repository) UserNearDuplicates(userId string, hash int64, maxDistance int) ([]string, error) {
+	var ids []string
+	err := r.DB.Model(&photoDomain.Photo{}).
+		Where("user_id = ? AND perceptual_hash IS NOT NULL", userId).
+		Where(hammingDistance+" <= ?", hash, maxDistance).
+		Order("created_at, id").Pluck("id", &ids).Error
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return ids, nil
+}
+
+// GetHashed ... Fetch the perceptual hash of every photo having one, ordered by id so the groups come out the same each time
+func (r *Repository) GetHashed() ([]photoDomain.HashedPhoto, error) {
+	var hashed []photoDomain.HashedPhoto
+	err := r.DB.Model(&photoDomain.Photo{}).Select("CAST(id AS text) AS id, user_id, perceptual_hash").
+		Where("perceptual_hash IS NOT NULL").Order("id").Scan(&hashed).Error
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return hashed, nil
+}
+
+// ReplaceDuplicates ... Replace the groups of near identical photos by the given ones in one transaction
+func (r *Repository) ReplaceDuplicates(duplicates []photoDomain.Duplicate) error {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&photoDomain.Duplicate{}).Error; err != nil {
+			return err
+		}
+		if len(duplicates) == 0 {
+			return nil
+		}
+
+		return tx.CreateInBatches(&duplicates, 500).Error
+	})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return nil
+}
+
+// GetDuplicates ... Fetch the photos of the groups of near identical photos ranked between the given ranks, both included
+func (r *Repository) GetDuplicates(firstRank int, lastRank int) ([]photoDomain.Duplicate, error) {
+	var duplicates []photoDomain.Duplicate
+	err := r.DB.Where("rank BETWEEN ? AND ?", firstRank, lastRank).Order("rank, photo_id").Find(&duplicates).Error
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return duplicates, nil
+}
+
+// CountDuplicateGroups ... Count the groups of near identical photos, the ranks follow one another from 1
+func (r *Repository) CountDuplicateGroups() (int64, error) {
+	var count int64
+	if err := r.DB.Model(&photoDomain.Duplicate{}).Select("COALESCE(MAX(rank), 0)").Scan(&count).Error; err != nil {
+		return 0, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return count, nil
+}
+
+// GetByIDs ... Fetch the photos of the given ids with their variants
+func (r *Repository) GetByIDs(ids []string) ([]photoDomain.Photo, error) {
+	var photos []photoDomain.Photo
+	if err := r.DB.Preload("Variants").Where("id IN ?", ids).Find(&photos).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return photos, nil
+}
+
+// GetUnhashed ... Fetch the uploaded photos following the given id which have no perceptual hash yet, ordered by id to walk them all in batches
+func (r *Repository) GetUnhashed(afterID string, limit int) ([]photoDomain.Photo, error) {
+	var photos []photoDomain.Photo
+	err := r.DB.Where("object_key <> '' AND perceptual_hash IS NULL AND id > ?", afterID).Order("id").Limit(limit).Find(&photos).Error
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return photos, nil
+}
+
+// SetPerceptualHash ... Update the perceptual hash of a photo
+func (r *Repository) SetPerceptualHash(id string, hash int64) error {
+	err := r.DB.Unscoped().Model(&photoDomain.Photo{}).Where("id = ?", id).UpdateColumn("perceptual_hash", hash).Error
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return nil
+}
+
+// SaveVariant ... Insert a variant of a photo or replace the one of the same name
+func (r *Repository) SaveVariant(variant *photoDomain.Variant) error {
+	err := r.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "photo_id"}, {Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"object_key", "url", "width", "height", "size", "checksum", "created_at"}),
+	}).Create(variant).Error
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return nil
+}
+
+// GetUploaded ... Fetch the uploaded photos following the given id with their variants, ordered by id to walk them all in batches
+func (r *Repository) GetUploaded(afterID string, limit int) ([]photoDomain.Photo, error) {
+	var photos []photoDomain.Photo
+	err := r.DB.Preload("Variants").Where("object_key <> '' AND id > ?", afterID).Order("id").Limit(limit).Find(&photos).Error
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return photos, nil
+}
@@ -3,10 +3,14 @@ package postgres
 
 import (
 	"fmt"
+	albumDomain "hexagonal-fiber/domain/album"
+	apiKeyDomain "hexagonal-fiber/domain/apikey"
+	auditDomain "hexagonal-fiber/domain/audit"
 	commentDomain "hexagonal-fiber/domain/comment"
 	photoDomain "hexagonal-fiber/domain/photo"
 	sosmedDomain "hexagonal-fiber/domain/sosmed"
 	userDomain "hexagonal-fiber/domain/user"
+	"hexagonal-fiber/utils/constant/permission"
 	"log"
 	"os"
 	"time"
@@ -142,16 +146,130 @@ func MigratePostgre(inGormDB *gorm.DB) error {
 		// user
 		&userDomain.User{},
 		&userDomain.Role{},
+		&userDomain.Permission{},
+		&userDomain.RecoveryCode{},
+		&userDomain.Identity{},
+		&apiKeyDomain.APIKey{},
 
 		// other
 		&commentDomain.Comment{},
 		&photoDomain.Photo{},
+		&photoDomain.Variant{},
+		&photoDomain.Duplicate{},
+		&albumDomain.Album{},
+		&albumDomain.AlbumPhoto{},
 		&sosmedDomain.SocialMedia{},
+
+		// audit
+		&auditDomain.Event{},
 	}
 
+	// the accounts created before the email verification existed are taken as verified, once, when the column is added
+	backfillVerified := inGormDB.Migrator().HasTable(&userDomain.User{}) && !inGormDB.Migrator().HasColumn(&userDomain.User{}, "VerifiedAt")
+
 	err := inGormDB.AutoMigrate(tablesMigrate...)
 	if err != nil {
 		return err
 	}
-	return nil
+
+	if backfillVerified {
+		if err = verifyExistingUsers(inGormDB); err != nil {
+			return err
+		}
+	}
+
+	if err = protectAuditEvents(inGormDB); err != nil {
+		return err
+	}
+
+	if err = clearZeroDeletedAt(inGormDB); err != nil {
+		return err
+	}
+
+	return seedPermissions(inGormDB)
+}
+
+// verifyExistingUsers marks every account as verified at its creation, it runs in the migration adding the verification
+// so the accounts signing up afterwards still have to verify their email before logging in
+func verifyExistingUsers(inGormDB *gorm.DB) error {
+	return inGormDB.Exec(`UPDATE users SET verified_at = created_at WHERE verified_at IS NULL`).Error
+}
+
+// clearZeroDeletedAt resets the zero deletion time social media rows were stored with before the soft delete,
+// a zero time is not null so the rows would otherwise be taken as deleted
+func clearZeroDeletedAt(inGormDB *gorm.DB) error {
+	return inGormDB.Exec(`UPDATE social_media SET deleted_at = NULL WHERE deleted_at < '1970-01-01'`).Error
+}
+
+// protectAuditEvents makes the audit log append-only, updates, deletes and truncates are refused by the database itself
+func protectAuditEvents(inGormDB *gorm.DB) error {
+	statements := []string{
+		`CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+		BEGIN
+			RAISE EXCEPTION 'audit_events is append-only';
+		END;
+		$$ LANGUAGE plpgsql`,
+		`DROP TRIGGER IF EXISTS audit_events_no_change ON audit_events`,
+		`CREATE TRIGGER audit_events_no_change BEFORE UPDATE OR DELETE ON audit_events
+			FOR EACH ROW EXECUTE FUNCTION audit_events_append_only()`,
+		`DROP TRIGGER IF EXISTS audit_events_no_truncate ON audit_events`,
+		`CREATE TRIGGER audit_events_no_truncate BEFORE TRUNCATE ON audit_events
+			FOR EACH STATEMENT EXECUTE FUNCTION audit_events_append_only()`,
+	}
+
+	return inGormDB.Transaction(func(tx *gorm.DB) error {
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// seedPermissions creates every known permission and the default roles. The admin role receives all permissions when
+// it is created and afterwards only the permissions new to the database, so the ones an administrator took away from it
+// are not given back by the next migration
+func seedPermissions(inGormDB *gorm.DB) error {
+	return inGormDB.Transaction(func(tx *gorm.DB) error {
+		var permissions, created []userDomain.Permission
+		for _, name := range permission.All {
+			perm := userDomain.Permission{Name: name}
+			result := tx.Where(userDomain.Permission{Name: name}).FirstOrCreate(&perm)
+			if result.Error != nil {
+				return result.Error
+			}
+
+			permissions = append(permissions, perm)
+			if result.RowsAffected > 0 {
+				created = append(created, perm)
+			}
+		}
+
+		for _, name := range []string{"admin", "user"} {
+			role := userDomain.Role{Name: name}
+			result := tx.Where(userDomain.Role{Name: name}).FirstOrCreate(&role)
+			if result.Error != nil {
+				return result.Error
+			}
+
+			if name != "admin" {
+				continue
+			}
+
+			grant := created
+			if result.RowsAffected > 0 {
+				grant = permissions
+			}
+
+			if len(grant) > 0 {
+				if err := tx.Model(&role).Association("Permissions").Append(grant); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
 }
@@ -1,12 +1,15 @@
 package role
 
 import (
+	"fmt"
+
 	domainRole "hexagonal-fiber/domain/user"
 
 	mssgConst "hexagonal-fiber/utils/constant/message"
 
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Repository is a struct that contains the database implementation for user entity
@@ -14,10 +17,51 @@ type Repository struct {
 	DB *gorm.DB
 }
 
+// GetAll ... Fetch all roles with their permissions
+func (r *Repository) GetAll() (*[]domainRole.Role, error) {
+	var roles []domainRole.Role
+
+	err := r.DB.Preload("Permissions").Order("name").Find(&roles).Error
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return &roles, nil
+}
+
+// GetPermissions ... Fetch every permission a role can own
+func (r *Repository) GetPermissions() (*[]domainRole.Permission, error) {
+	var permissions []domainRole.Permission
+
+	err := r.DB.Order("name").Find(&permissions).Error
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return &permissions, nil
+}
+
 // GetByID ... Fetch only one role by ID
 func (r *Repository) GetByID(id string) (*domainRole.Role, error) {
 	var role domainRole.Role
-	err := r.DB.Where("id = ?", id).First(&role).Error
+	err := r.DB.Preload("Permissions").Where("id = ?", id).First(&role).Error
+
+	if err != nil {
+		switch err.Error() {
+		case gorm.ErrRecordNotFound.Error():
+			return nil, fiber.NewError(fiber.StatusNotFound, "role not found")
+		default:
+			return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+		}
+	}
+
+	return &role, err
+}
+
+// GetByName ... Fetch only one role by name
+func (r *Repository) GetByName(name string) (*domainRole.Role, error) {
+	var role domainRole.Role
+	err := r.DB.Preload("Permissions").Where("name = ?", name).First(&role).Error
 
 	if err != nil {
 		switch err.Error() {
@@ -30,3 +74,159 @@ func (r *Repository) GetByID(id string) (*domainRole.Role, error) {
 
 	return &role, err
 }
+
+// Create ... Insert a new role owning the named permissions
+func (r *Repository) Create(newRole *domainRole.Role, permissionNames []string) (*domainRole.Role, error) {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkNameFree(tx, newRole.Name, ""); err != nil {
+			return err
+		}
+
+		permissions, err := findPermissions(tx, permissionNames)
+		if err != nil {
+			return err
+		}
+
+		newRole.Permissions = permissions
+		if err = tx.Create(newRole).Error; err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newRole, nil
+}
+
+// Update ... Update role columns by Map values, replacing its permissions when names are given
+func (r *Repository) Update(id string, roleMap map[string]interface{}, permissionNames *[]string) (*domainRole.Role, error) {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var role domainRole.Role
+		if err := tx.Where("id = ?", id).First(&role).Error; err != nil {
+			return fiber.NewError(fiber.StatusNotFound, "role not found")
+		}
+
+		if name, ok := roleMap["name"].(string); ok {
+			if err := checkNameFree(tx, name, id); err != nil {
+				return err
+			}
+		}
+
+		if len(roleMap) > 0 {
+			if err := tx.Model(&role).Updates(roleMap).Error; err != nil {
+				return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+			}
+		}
+
+		if permissionNames == nil {
+			return nil
+		}
+
+		permissions, err := findPermissions(tx, *permissionNames)
+		if err != nil {
+			return err
+		}
+
+		if err = tx.Model(&role).Association("Permissions").Replace(permissions); err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(id)
+}
+
+// UpdateByMap ... Update role columns by Map values, zero values included
+func (r *Repository) UpdateByMap(id string, roleMap map[string]interface{}) (*domainRole.Role, error) {
+	tx := r.DB.Model(&domainRole.Role{}).Where("id = ?", id).Updates(roleMap)
+	if tx.Error != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	if tx.RowsAffected == 0 {
+		return nil, fiber.NewError(fiber.StatusNotFound, "role not found")
+	}
+
+	return r.GetByID(id)
+}
+
+// Delete ... Delete a role no user is assigned to, the deleted users included
+func (r *Repository) Delete(id string) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		var role domainRole.Role
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&role).Error; err != nil {
+			return fiber.NewError(fiber.StatusNotFound, "role not found")
+		}
+
+		// the users in the trash still hold their role, restoring one must not leave it pointing to a missing role
+		var users int64
+		if err := tx.Unscoped().Model(&domainRole.User{}).Where("role_id = ?", id).Count(&users).Error; err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+		}
+
+		if users > 0 {
+			return fiber.NewError(fiber.StatusConflict, fmt.Sprintf("role is still assigned to %d users", users))
+		}
+
+		if err := tx.Model(&role).Association("Permissions").Clear(); err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+		}
+
+		if err := tx.Delete(&role).Error; err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+		}
+
+		return nil
+	})
+}
+
+// checkNameFree returns a conflict when another role already has the name
+func checkNameFree(tx *gorm.DB, name string, exceptID string) error {
+	query := tx.Model(&domainRole.Role{}).Where("name = ?", name)
+	if exceptID != "" {
+		query = query.Where("id <> ?", exceptID)
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	if count > 0 {
+		return fiber.NewError(fiber.StatusConflict, mssgConst.ResourceAlreadyExists)
+	}
+
+	return nil
+}
+
+// findPermissions fetches the permissions of the names, an unknown name is a bad request
+func findPermissions(tx *gorm.DB, names []string) ([]domainRole.Permission, error) {
+	permissions := []domainRole.Permission{}
+	if len(names) == 0 {
+		return permissions, nil
+	}
+
+	if err := tx.Where("name IN ?", names).Find(&permissions).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	found := map[string]bool{}
+	for _, permission := range permissions {
+		found[permission.Name] = true
+	}
+
+	for _, name := range names {
+		if !found[name] {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "unknown permission "+name)
+		}
+	}
+
+	return permissions, nil
+}
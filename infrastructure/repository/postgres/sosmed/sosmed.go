@@ -2,6 +2,8 @@ package sosmed
 
 import (
 	"encoding/json"
+	"time"
+
 	errorDomain "hexagonal-fiber/domain/error"
 	sosmedDomain "hexagonal-fiber/domain/sosmed"
 
@@ -66,7 +68,7 @@ func (r *Repository) UserGetAll(userId string, page int, limit int) (*sosmedDoma
 	}
 
 	offset := (page - 1) * limit
-	if err = r.DB.Limit(limit).Offset(offset).Find(&sosmeds).Error; err != nil {
+	if err = r.DB.Where("user_id = ?", userId).Order("created_at, id").Limit(limit).Offset(offset).Find(&sosmeds).Error; err != nil {
 		return nil, err
 	}
 
@@ -235,7 +237,7 @@ func (r *Repository) UserUpdate(id string, userId string, updateSocialMedia *sos
 	return &sosmed, nil
 }
 
-// Delete ... Delete sosmed
+// Delete ... Move social media to the trash
 func (r *Repository) Delete(id string) (err error) {
 	tx := r.DB.Where("id = ?", id).Delete(&sosmedDomain.SocialMedia{})
 
@@ -250,3 +252,81 @@ func (r *Repository) Delete(id string) (err error) {
 
 	return
 }
+
+// UserGetTrash Fetch the deleted social media of a user, latest deleted first
+func (r *Repository) UserGetTrash(userId string, page int, limit int) (*sosmedDomain.PaginationSocialMedia, error) {
+	var sosmeds []sosmedDomain.SocialMedia
+	var total int64
+
+	trash := r.DB.Unscoped().Model(&sosmedDomain.SocialMedia{}).Where("user_id = ? AND deleted_at IS NOT NULL", userId)
+	if err := trash.Count(&total).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	offset := (page - 1) * limit
+	err := r.DB.Unscoped().Where("user_id = ? AND deleted_at IS NOT NULL", userId).
+		Order("deleted_at DESC, id").Limit(limit).Offset(offset).Find(&sosmeds).Error
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	numPages := (total + int64(limit) - 1) / int64(limit)
+	var nextCursor, prevCursor uint
+	if page < int(numPages) {
+		nextCursor = uint(page + 1)
+	}
+	if page > 1 {
+		prevCursor = uint(page - 1)
+	}
+
+	return &sosmedDomain.PaginationSocialMedia{
+		Data:       sosmedDomain.ArrayToDomainMapper(&sosmeds),
+		Total:      total,
+		Limit:      int64(limit),
+		Current:    int64(page),
+		NextCursor: nextCursor,
+		PrevCursor: prevCursor,
+		NumPages:   numPages,
+	}, nil
+}
+
+// GetDeletedByID ... Fetch only one social media of the trash by Id
+func (r *Repository) GetDeletedByID(id string) (*sosmedDomain.SocialMedia, error) {
+	var sosmed sosmedDomain.SocialMedia
+	err := r.DB.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&sosmed).Error
+
+	if err != nil {
+		switch err.Error() {
+		case gorm.ErrRecordNotFound.Error():
+			return nil, fiber.NewError(fiber.StatusNotFound, "social media not found in trash")
+		default:
+			return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+		}
+	}
+
+	return &sosmed, nil
+}
+
+// Restore ... Bring a deleted social media back
+func (r *Repository) Restore(id string) (err error) {
+	tx := r.DB.Unscoped().Model(&sosmedDomain.SocialMedia{}).Where("id = ? AND deleted_at IS NOT NULL", id).UpdateColumn("deleted_at", nil)
+	if tx.Error != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	if tx.RowsAffected == 0 {
+		return fiber.NewError(fiber.StatusNotFound, "social media not found in trash")
+	}
+
+	return
+}
+
+// Purge ... Permanently delete the social media in the trash since before the given time
+func (r *Repository) Purge(before time.Time) (int64, error) {
+	tx := r.DB.Unscoped().Where("deleted_at < ?", before).Delete(&sosmedDomain.SocialMedia{})
+	if tx.Error != nil {
+		return 0, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return tx.RowsAffected, nil
+}
@@ -3,8 +3,14 @@ package user
 
 import (
 	"encoding/json"
+	"time"
 
+	albumDomain "hexagonal-fiber/domain/album"
+	apiKeyDomain "hexagonal-fiber/domain/apikey"
+	commentDomain "hexagonal-fiber/domain/comment"
 	errorDomain "hexagonal-fiber/domain/error"
+	photoDomain "hexagonal-fiber/domain/photo"
+	sosmedDomain "hexagonal-fiber/domain/sosmed"
 	userDomain "hexagonal-fiber/domain/user"
 
 	mssgConst "hexagonal-fiber/utils/constant/message"
@@ -13,6 +19,7 @@ import (
 	"github.com/google/uuid"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Repository is a struct that contains the database implementation for user entity
@@ -68,7 +75,7 @@ func (r *Repository) GetOneByMap(userMap map[string]interface{}) (*userDomain.Us
 func (r *Repository) GetWithRoleByMap(userMap map[string]interface{}) (*userDomain.UserRole, error) {
 	var userRole userDomain.UserRole
 
-	err := r.DB.Preload("Role").Where(userMap).First(&userRole).Error
+	err := r.DB.Preload("Role.Permissions").Where(userMap).First(&userRole).Error
 	if err != nil {
 		switch err.Error() {
 		case gorm.ErrRecordNotFound.Error():
@@ -81,10 +88,43 @@ func (r *Repository) GetWithRoleByMap(userMap map[string]interface{}) (*userDoma
 	return &userRole, nil
 }
 
+// byEmail matches the users of an email whatever its casing and surrounding spaces, the account of the exact email first
+func byEmail(db *gorm.DB, email string) *gorm.DB {
+	return db.Where("LOWER(email) = LOWER(TRIM(?))", email).
+		Clauses(clause.OrderBy{Expression: clause.Expr{SQL: "email = TRIM(?) DESC, created_at", Vars: []interface{}{email}}}).
+		Limit(1)
+}
+
+// GetByEmail ... Fetch only one user by email whatever its casing, an unknown email gives a user without id
+func (r *Repository) GetByEmail(email string) (*userDomain.User, error) {
+	var user userDomain.User
+
+	if err := byEmail(r.DB, email).Find(&user).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return &user, nil
+}
+
+// GetWithRoleByEmail ... Fetch only one user with Role by email whatever its casing
+func (r *Repository) GetWithRoleByEmail(email string) (*userDomain.UserRole, error) {
+	var userRole userDomain.UserRole
+
+	if err := byEmail(r.DB.Preload("Role.Permissions"), email).Find(&userRole).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	if userRole.ID == uuid.Nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "user not found")
+	}
+
+	return &userRole, nil
+}
+
 // GetWithRole ... Fetch only one user with Role by ID
 func (r *Repository) GetWithRole(id string) (*userDomain.UserRole, error) {
 	var userRole userDomain.UserRole
-	err := r.DB.Preload("Role").Where("id = ?", id).First(&userRole).Error
+	err := r.DB.Preload("Role.Permissions").Where("id = ?", id).First(&userRole).Error
 
 	if err != nil {
 		switch err.Error() {
@@ -152,9 +192,33 @@ func (r *Repository) Update(id string, updateUser *userDomain.User) (*userDomain
 	return &user, err
 }
 
-// Delete ... Delete user
-func (r *Repository) Delete(id string) (err error) {
-	tx := r.DB.Where("id = ?", id).Delete(&userDomain.User{})
+// SetKeepLocationPublic ... Update whether the location of the photos of a user is kept, turning it off removes it from the photos already uploaded
+func (r *Repository) SetKeepLocationPublic(id string, keep bool) error {
+	var updated int64
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&userDomain.User{}).Where("id = ?", id).UpdateColumn("keep_location_public", keep)
+		updated = result.RowsAffected
+		if result.Error != nil || keep {
+			return result.Error
+		}
+
+		return tx.Unscoped().Model(&photoDomain.Photo{}).Where("user_id = ? AND latitude IS NOT NULL", id).
+			UpdateColumns(map[string]interface{}{"latitude": nil, "longitude": nil}).Error
+	})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	if updated == 0 {
+		return fiber.NewError(fiber.StatusNotFound, "user not found")
+	}
+
+	return nil
+}
+
+// UpdateByMap ... Update user columns by Map values, zero values included
+func (r *Repository) UpdateByMap(id string, userMap map[string]interface{}) error {
+	tx := r.DB.Model(&userDomain.User{}).Where("id = ?", id).Updates(userMap)
 	if tx.Error != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
 	}
@@ -163,5 +227,259 @@ func (r *Repository) Delete(id string) (err error) {
 		return fiber.NewError(fiber.StatusNotFound, "user not found")
 	}
 
+	return nil
+}
+
+// AcceptTOTPStep ... Record the time step of a two factor code, false when a code of that step or a later one was accepted already
+func (r *Repository) AcceptTOTPStep(id string, step int64) (bool, error) {
+	tx := r.DB.Model(&userDomain.User{}).Where("id = ? AND totp_last_step < ?", id, step).UpdateColumn("totp_last_step", step)
+	if tx.Error != nil {
+		return false, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return tx.RowsAffected == 1, nil
+}
+
+// BumpTokenVersion ... Increment the token version of a user, returning the new one
+func (r *Repository) BumpTokenVersion(id string) (int, error) {
+	var versions []int
+	err := r.DB.Raw("UPDATE users SET token_version = token_version + 1 WHERE id = ? RETURNING token_version", id).
+		Scan(&versions).Error
+	if err != nil {
+		return 0, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	if len(versions) == 0 {
+		return 0, fiber.NewError(fiber.StatusNotFound, "user not found")
+	}
+
+	return versions[0], nil
+}
+
+// BumpRoleTokenVersion ... Increment the token version of every user of a role, returning the new ones by user id
+func (r *Repository) BumpRoleTokenVersion(roleID string) (map[string]int, error) {
+	var rows []struct {
+		ID           string
+		TokenVersion int
+	}
+
+	err := r.DB.Raw("UPDATE users SET token_version = token_version + 1 WHERE role_id = ? RETURNING id, token_version", roleID).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	versions := make(map[string]int, len(rows))
+	for _, row := range rows {
+		versions[row.ID] = row.TokenVersion
+	}
+
+	return versions, nil
+}
+
+// GetTokenVersion ... Fetch the token version of a user
+func (r *Repository) GetTokenVersion(id string) (int, error) {
+	var user userDomain.User
+	err := r.DB.Select("token_version").Where("id = ?", id).First(&user).Error
+
+	if err != nil {
+		switch err.Error() {
+		case gorm.ErrRecordNotFound.Error():
+			return 0, fiber.NewError(fiber.StatusNotFound, "user not found")
+		default:
+			return 0, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+		}
+	}
+
+	return user.TokenVersion, nil
+}
+
+// Delete ... Move user to the trash along with its photos, its social media and the comments by it or on its photos,
+// they share the deletion time so a restore brings back what was deleted with the user and nothing else
+func (r *Repository) Delete(id string) (err error) {
+	var deleted int64
+	err = r.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		result := tx.Model(&userDomain.User{}).Where("id = ?", id).UpdateColumn("deleted_at", now)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		deleted = result.RowsAffected
+
+		for _, records := range owned(tx, id) {
+			if err := tx.Model(records.model).Where(records.query, records.args...).UpdateColumn("deleted_at", now).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	if deleted == 0 {
+		return fiber.NewError(fiber.StatusNotFound, "user not found")
+	}
+
+	return
+}
+
+// Erase ... Permanently delete user along with everything it owns in one transaction, the trash and
+// the comments of others on its photos included, so no record is left pointing to a missing user
+func (r *Repository) Erase(id string) (err error) {
+	var erased int64
+	err = r.DB.Transaction(func(tx *gorm.DB) error {
+		credentials := []interface{}{&userDomain.RecoveryCode{}, &userDomain.Identity{}, &apiKeyDomain.APIKey{}}
+		for _, model := range credentials {
+			if err := tx.Where("user_id = ?", id).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		ownPhotos := tx.Unscoped().Model(&photoDomain.Photo{}).Select("CAST(id AS text)").Where("user_id = ?", id)
+		for _, model := range []interface{}{&photoDomain.Variant{}, &photoDomain.Duplicate{}} {
+			if err := tx.Where("photo_id IN (?)", ownPhotos).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		ownAlbums := tx.Unscoped().Model(&albumDomain.Album{}).Select("CAST(id AS text)").Where("user_id = ?", id)
+		if err := tx.Where("album_id IN (?) OR photo_id IN (?)", ownAlbums, ownPhotos).Delete(&albumDomain.AlbumPhoto{}).Error; err != nil {
+			return err
+		}
+
+		for _, records := range owned(tx, id) {
+			if err := tx.Unscoped().Where(records.query, records.args...).Delete(records.model).Error; err != nil {
+				return err
+			}
+		}
+
+		result := tx.Unscoped().Where("id = ?", id).Delete(&userDomain.User{})
+		erased = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	if erased == 0 {
+		return fiber.NewError(fiber.StatusNotFound, "user not found")
+	}
+
 	return
 }
+
+// GetTrash Fetch the deleted users, latest deleted first
+func (r *Repository) GetTrash(page int, limit int) (*userDomain.PaginationUser, error) {
+	var users []userDomain.User
+	var total int64
+
+	if err := r.DB.Unscoped().Model(&userDomain.User{}).Where("deleted_at IS NOT NULL").Count(&total).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	offset := (page - 1) * limit
+	err := r.DB.Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at DESC, id").Limit(limit).Offset(offset).Find(&users).Error
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	numPages := (int(total) + limit - 1) / limit
+	var nextCursor, prevCursor uint
+	if page < numPages {
+		nextCursor = uint(page + 1)
+	}
+	if page > 1 {
+		prevCursor = uint(page - 1)
+	}
+
+	return &userDomain.PaginationUser{
+		Data:       users,
+		Total:      int(total),
+		Limit:      limit,
+		Current:    page,
+		NextCursor: nextCursor,
+		PrevCursor: prevCursor,
+		NumPages:   numPages,
+	}, nil
+}
+
+// GetDeletedByID ... Fetch only one user of the trash by ID
+func (r *Repository) GetDeletedByID(id string) (*userDomain.User, error) {
+	var user userDomain.User
+	err := r.DB.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&user).Error
+
+	if err != nil {
+		switch err.Error() {
+		case gorm.ErrRecordNotFound.Error():
+			return nil, fiber.NewError(fiber.StatusNotFound, "user not found in trash")
+		default:
+			return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+		}
+	}
+
+	return &user, nil
+}
+
+// Restore ... Bring a deleted user back along with the records deleted with it
+func (r *Repository) Restore(user *userDomain.User) (err error) {
+	id := user.ID.String()
+	err = r.DB.Transaction(func(tx *gorm.DB) error {
+		for _, records := range owned(tx, id) {
+			query := tx.Unscoped().Model(records.model).Where(records.query, records.args...).Where("deleted_at = ?", user.DeletedAt.Time)
+			if err := query.UpdateColumn("deleted_at", nil).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Unscoped().Model(&userDomain.User{}).Where("id = ?", id).UpdateColumn("deleted_at", nil).Error
+	})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return
+}
+
+// GetDeletedBefore ... Fetch the ids of the users in the trash since before the given time
+func (r *Repository) GetDeletedBefore(before time.Time) ([]string, error) {
+	var ids []string
+	err := r.DB.Unscoped().Model(&userDomain.User{}).Where("deleted_at < ?", before).Pluck("id", &ids).Error
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return ids, nil
+}
+
+// GetDueErasures ... Fetch the ids of the users whose erasure is due
+func (r *Repository) GetDueErasures(now time.Time) ([]string, error) {
+	var ids []string
+	// an account scheduled for erasure is erased even when it was moved to the trash meanwhile
+	err := r.DB.Unscoped().Model(&userDomain.User{}).Where("erasure_scheduled_at <= ?", now).Pluck("id", &ids).Error
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return ids, nil
+}
+
+type ownedRecords struct {
+	model interface{}
+	query interface{}
+	args  []interface{}
+}
+
+// owned lists the content of a user which follows it to the trash and back, the comments of others on its photos included
+func owned(tx *gorm.DB, id string) []ownedRecords {
+	ownPhotos := tx.Unscoped().Model(&photoDomain.Photo{}).Select("CAST(id AS text)").Where("user_id = ?", id)
+
+	return []ownedRecords{
+		{&commentDomain.Comment{}, "user_id = ? OR photo_id IN (?)", []interface{}{id, ownPhotos}},
+		{&photoDomain.Photo{}, "user_id = ?", []interface{}{id}},
+		{&sosmedDomain.SocialMedia{}, "user_id = ?", []interface{}{id}},
+		{&albumDomain.Album{}, "user_id = ?", []interface{}{id}},
+	}
+}
@@ -0,0 +1,76 @@
+// Package export contains the redis implementation for the personal data exports
+package export
+
+import (
+	"encoding/json"
+	"time"
+
+	privacyDomain "hexagonal-fiber/domain/privacy"
+	redisRepo "hexagonal-fiber/infrastructure/repository/redis"
+
+	mssgConst "hexagonal-fiber/utils/constant/message"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// Repository is a struct that contains the redis implementation for export entity, a user has one export at most
+type Repository struct {
+	InfoRedis *redisRepo.InfoDatabaseRedis
+}
+
+func userKey(userID string) string {
+	return "export:user:" + userID
+}
+
+// Save ... Insert or replace the export of the user
+func (r *Repository) Save(export *privacyDomain.Export, ttl time.Duration) error {
+	exportJSON, err := json.Marshal(export)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	// the path is not part of the JSON of the api, it is kept next to it
+	redisDB := r.InfoRedis.NewRedis(0)
+	err = redisDB.HSet(r.InfoRedis.CTX, userKey(export.UserID), "export", exportJSON, "path", export.Path).Err()
+	if err == nil {
+		err = redisDB.Expire(r.InfoRedis.CTX, userKey(export.UserID), ttl).Err()
+	}
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return nil
+}
+
+// GetByUser ... Fetch the export of the user
+func (r *Repository) GetByUser(userID string) (*privacyDomain.Export, error) {
+	redisDB := r.InfoRedis.NewRedis(0)
+
+	values, err := redisDB.HGetAll(r.InfoRedis.CTX, userKey(userID)).Result()
+	if err != nil && err != redis.Nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	if values["export"] == "" {
+		return nil, fiber.NewError(fiber.StatusNotFound, "export not found")
+	}
+
+	var export privacyDomain.Export
+	if err = json.Unmarshal([]byte(values["export"]), &export); err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+	export.Path = values["path"]
+
+	return &export, nil
+}
+
+// Delete ... Delete the export of the user
+func (r *Repository) Delete(userID string) error {
+	redisDB := r.InfoRedis.NewRedis(0)
+	if err := redisDB.Del(r.InfoRedis.CTX, userKey(userID)).Err(); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+// Package limiter contains the redis implementation for windowed counters
+package limiter
+
+import (
+	"time"
+
+	redisRepo "hexagonal-fiber/infrastructure/repository/redis"
+
+	mssgConst "hexagonal-fiber/utils/constant/message"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// hitScript counts a hit and starts the window of a counter having none in one step, a counter left without
+// expiration would otherwise refuse its key for good
+var hitScript = redis.NewScript(`
+local count = redis.call('INCR', KEYS[1])
+if redis.call('PTTL', KEYS[1]) < 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return count
+`)
+
+// Repository is a struct that contains the redis implementation for counters
+type Repository struct {
+	InfoRedis *redisRepo.InfoDatabaseRedis
+}
+
+// Hit ... Increment the counter of the key, the window starts on the first hit
+func (r *Repository) Hit(key string, window time.Duration) (int64, error) {
+	redisDB := r.InfoRedis.NewRedis(0)
+
+	count, err := hitScript.Run(r.InfoRedis.CTX, redisDB, []string{key}, window.Milliseconds()).Int64()
+	if err != nil {
+		return 0, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return count, nil
+}
+
+// Claim ... Set the key unless it exists, only the first of concurrent claims gets true
+func (r *Repository) Claim(key string, ttl time.Duration) (bool, error) {
+	redisDB := r.InfoRedis.NewRedis(0)
+
+	claimed, err := redisDB.SetNX(r.InfoRedis.CTX, key, 1, ttl).Result()
+	if err != nil {
+		return false, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return claimed, nil
+}
+
+// Count ... Fetch the current value of the counter
+func (r *Repository) Count(key string) (int64, error) {
+	redisDB := r.InfoRedis.NewRedis(0)
+
+	count, err := redisDB.Get(r.InfoRedis.CTX, key).Int64()
+	if err != nil && err != redis.Nil {
+		return 0, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return count, nil
+}
+
+// Reset ... Delete the counters of the keys
+func (r *Repository) Reset(keys ...string) error {
+	redisDB := r.InfoRedis.NewRedis(0)
+
+	if err := redisDB.Del(r.InfoRedis.CTX, keys...).Err(); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return nil
+}
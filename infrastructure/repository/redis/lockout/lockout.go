@@ -0,0 +1,104 @@
+// Package lockout contains the redis implementation for login failure counters and lockouts
+package lockout
+
+import (
+	"time"
+
+	redisRepo "hexagonal-fiber/infrastructure/repository/redis"
+
+	mssgConst "hexagonal-fiber/utils/constant/message"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// subjects of the counters
+const (
+	Account = "account"
+	IP      = "ip"
+)
+
+// failScript counts a failure and starts the window of a counter having none in one step, a counter left without
+// expiration would otherwise lock its subject out for good
+var failScript = redis.NewScript(`
+local count = redis.call('INCR', KEYS[1])
+if redis.call('PTTL', KEYS[1]) < 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return count
+`)
+
+// Repository is a struct that contains the redis implementation for lockouts
+type Repository struct {
+	InfoRedis *redisRepo.InfoDatabaseRedis
+}
+
+func failKey(kind string, subject string) string {
+	return "login:fail:" + kind + ":" + subject
+}
+
+func lockKey(kind string, subject string) string {
+	return "login:lock:" + kind + ":" + subject
+}
+
+// Fail ... Count a failed login of the subject, the window starts on the first failure
+func (r *Repository) Fail(kind string, subject string, window time.Duration) (int64, error) {
+	redisDB := r.InfoRedis.NewRedis(0)
+
+	count, err := failScript.Run(r.InfoRedis.CTX, redisDB, []string{failKey(kind, subject)}, window.Milliseconds()).Int64()
+	if err != nil {
+		return 0, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return count, nil
+}
+
+// Lock ... Lock the subject out for the duration
+func (r *Repository) Lock(kind string, subject string, duration time.Duration) error {
+	redisDB := r.InfoRedis.NewRedis(0)
+
+	if err := redisDB.Set(r.InfoRedis.CTX, lockKey(kind, subject), 1, duration).Err(); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return nil
+}
+
+// LockedFor ... Fetch the remaining lockout of the subject, zero when it is not locked
+func (r *Repository) LockedFor(kind string, subject string) (time.Duration, error) {
+	redisDB := r.InfoRedis.NewRedis(0)
+
+	ttl, err := redisDB.PTTL(r.InfoRedis.CTX, lockKey(kind, subject)).Result()
+	if err != nil {
+		return 0, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	// missing keys answer -2 and keys without expiration -1
+	if ttl < 0 {
+		return 0, nil
+	}
+
+	return ttl, nil
+}
+
+// Reset ... Delete the failure counter of the subject, keeping its lockout
+func (r *Repository) Reset(kind string, subject string) error {
+	redisDB := r.InfoRedis.NewRedis(0)
+
+	if err := redisDB.Del(r.InfoRedis.CTX, failKey(kind, subject)).Err(); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return nil
+}
+
+// Unlock ... Delete the failure counter and the lockout of the subject
+func (r *Repository) Unlock(kind string, subject string) error {
+	redisDB := r.InfoRedis.NewRedis(0)
+
+	if err := redisDB.Del(r.InfoRedis.CTX, failKey(kind, subject), lockKey(kind, subject)).Err(); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+// Package oidc contains the redis implementation for the pending OpenID Connect logins
+package oidc
+
+import (
+	"encoding/json"
+	"time"
+
+	secureDomain "hexagonal-fiber/domain/security"
+	redisRepo "hexagonal-fiber/infrastructure/repository/redis"
+
+	mssgConst "hexagonal-fiber/utils/constant/message"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// Repository is a struct that contains the redis implementation for the login states
+type Repository struct {
+	InfoRedis *redisRepo.InfoDatabaseRedis
+}
+
+func stateKey(stateHash string) string {
+	return "oidc:state:" + stateHash
+}
+
+// Save ... Store the login state under the hash of the state sent to the provider
+func (r *Repository) Save(stateHash string, state secureDomain.OIDCState, ttl time.Duration) error {
+	stateJSON, err := json.Marshal(state)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	redisDB := r.InfoRedis.NewRedis(0)
+	if err = redisDB.Set(r.InfoRedis.CTX, stateKey(stateHash), stateJSON, ttl).Err(); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return nil
+}
+
+// Consume ... Fetch the login state and delete it so a callback can only be used once
+func (r *Repository) Consume(stateHash string) (*secureDomain.OIDCState, error) {
+	redisDB := r.InfoRedis.NewRedis(0)
+
+	stateJSON, err := redisDB.GetDel(r.InfoRedis.CTX, stateKey(stateHash)).Bytes()
+	if err == redis.Nil {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "invalid or expired login state")
+	}
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	var state secureDomain.OIDCState
+	if err = json.Unmarshal(stateJSON, &state); err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return &state, nil
+}
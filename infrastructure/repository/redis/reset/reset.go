@@ -0,0 +1,69 @@
+// Package reset contains the redis implementation for password reset tokens
+package reset
+
+import (
+	"time"
+
+	redisRepo "hexagonal-fiber/infrastructure/repository/redis"
+
+	mssgConst "hexagonal-fiber/utils/constant/message"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// Repository is a struct that contains the redis implementation for password reset tokens
+type Repository struct {
+	InfoRedis *redisRepo.InfoDatabaseRedis
+}
+
+func tokenKey(tokenHash string) string {
+	return "reset:token:" + tokenHash
+}
+
+func userKey(userID string) string {
+	return "reset:user:" + userID
+}
+
+// Save ... Store the hashed token of the user, replacing any previous one
+func (r *Repository) Save(userID string, tokenHash string, ttl time.Duration) error {
+	redisDB := r.InfoRedis.NewRedis(0)
+
+	previous, err := redisDB.Get(r.InfoRedis.CTX, userKey(userID)).Result()
+	if err != nil && err != redis.Nil {
+		return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	pipe := redisDB.TxPipeline()
+	if previous != "" {
+		pipe.Del(r.InfoRedis.CTX, tokenKey(previous))
+	}
+	pipe.Set(r.InfoRedis.CTX, tokenKey(tokenHash), userID, ttl)
+	pipe.Set(r.InfoRedis.CTX, userKey(userID), tokenHash, ttl)
+
+	if _, err = pipe.Exec(r.InfoRedis.CTX); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return nil
+}
+
+// Consume ... Fetch the user of a hashed token and delete it so it can only be used once
+func (r *Repository) Consume(tokenHash string) (string, error) {
+	redisDB := r.InfoRedis.NewRedis(0)
+
+	pipe := redisDB.TxPipeline()
+	userID := pipe.Get(r.InfoRedis.CTX, tokenKey(tokenHash))
+	pipe.Del(r.InfoRedis.CTX, tokenKey(tokenHash))
+
+	if _, err := pipe.Exec(r.InfoRedis.CTX); err != nil && err != redis.Nil {
+		return "", fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	if userID.Err() == redis.Nil {
+		return "", fiber.NewError(fiber.StatusUnauthorized, "invalid or expired reset token")
+	}
+
+	redisDB.Del(r.InfoRedis.CTX, userKey(userID.Val()))
+	return userID.Val(), nil
+}
@@ -0,0 +1,132 @@
+// Package session contains the redis implementation for user sessions
+package session
+
+import (
+	"encoding/json"
+	"time"
+
+	secureDomain "hexagonal-fiber/domain/security"
+	redisRepo "hexagonal-fiber/infrastructure/repository/redis"
+
+	mssgConst "hexagonal-fiber/utils/constant/message"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// Repository is a struct that contains the redis implementation for session entity
+type Repository struct {
+	InfoRedis *redisRepo.InfoDatabaseRedis
+}
+
+func dataKey(sessionID string) string {
+	return "session:data:" + sessionID
+}
+
+func indexKey(userID string) string {
+	return "session:index:" + userID
+}
+
+// Save ... Insert or replace a session and register it on the user index
+func (r *Repository) Save(session *secureDomain.Session) error {
+	redisDB := r.InfoRedis.NewRedis(0)
+	ttl := time.Until(session.ExpiresAt)
+
+	sessionJSON, err := json.Marshal(session)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	pipe := redisDB.TxPipeline()
+	pipe.Set(r.InfoRedis.CTX, dataKey(session.ID), sessionJSON, ttl)
+	pipe.SAdd(r.InfoRedis.CTX, indexKey(session.UserID), session.ID)
+
+	if _, err = pipe.Exec(r.InfoRedis.CTX); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return nil
+}
+
+// GetByID ... Fetch only one session by ID
+func (r *Repository) GetByID(sessionID string) (*secureDomain.Session, error) {
+	redisDB := r.InfoRedis.NewRedis(0)
+
+	sessionJSON, err := redisDB.Get(r.InfoRedis.CTX, dataKey(sessionID)).Result()
+	if err != nil {
+		switch err {
+		case redis.Nil:
+			return nil, fiber.NewError(fiber.StatusNotFound, "session not found")
+		default:
+			return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+		}
+	}
+
+	var session secureDomain.Session
+	if err = json.Unmarshal([]byte(sessionJSON), &session); err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return &session, nil
+}
+
+// UserGetAll ... Fetch all live sessions of the user, pruning the expired ones from the index
+func (r *Repository) UserGetAll(userID string) (*[]secureDomain.Session, error) {
+	redisDB := r.InfoRedis.NewRedis(0)
+	sessions := []secureDomain.Session{}
+
+	sessionIDs, err := redisDB.SMembers(r.InfoRedis.CTX, indexKey(userID)).Result()
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	if len(sessionIDs) == 0 {
+		return &sessions, nil
+	}
+
+	keys := make([]string, len(sessionIDs))
+	for i, sessionID := range sessionIDs {
+		keys[i] = dataKey(sessionID)
+	}
+
+	values, err := redisDB.MGet(r.InfoRedis.CTX, keys...).Result()
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	var expired []interface{}
+	for i, value := range values {
+		sessionJSON, ok := value.(string)
+		if !ok {
+			expired = append(expired, sessionIDs[i])
+			continue
+		}
+
+		var session secureDomain.Session
+		if err = json.Unmarshal([]byte(sessionJSON), &session); err != nil {
+			return nil, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+		}
+		sessions = append(sessions, session)
+	}
+
+	if expired != nil {
+		redisDB.SRem(r.InfoRedis.CTX, indexKey(userID), expired...)
+	}
+
+	return &sessions, nil
+}
+
+// Delete ... Delete a session
+func (r *Repository) Delete(userID string, sessionID string) error {
+	redisDB := r.InfoRedis.NewRedis(0)
+
+	pipe := redisDB.TxPipeline()
+	pipe.Del(r.InfoRedis.CTX, dataKey(sessionID))
+	pipe.SRem(r.InfoRedis.CTX, indexKey(userID), sessionID)
+
+	if _, err := pipe.Exec(r.InfoRedis.CTX); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return nil
+}
@@ -0,0 +1,184 @@
+// Package token contains the redis implementation for refresh token families
+package token
+
+import (
+	"time"
+
+	redisRepo "hexagonal-fiber/infrastructure/repository/redis"
+
+	mssgConst "hexagonal-fiber/utils/constant/message"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// rotateScript swaps the current token id of a family only when the presented one is still current,
+// a mismatch means an already rotated token was reused so the whole family is dropped
+var rotateScript = redis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if not current then
+	return 0
+end
+if current ~= ARGV[1] then
+	redis.call('DEL', KEYS[1])
+	return -1
+end
+redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+return 1
+`)
+
+// Repository is a struct that contains the redis implementation for token families
+type Repository struct {
+	InfoRedis *redisRepo.InfoDatabaseRedis
+}
+
+func familyKey(family string) string {
+	return "token:family:" + family
+}
+
+func revokedKey(tokenID string) string {
+	return "token:revoked:" + tokenID
+}
+
+func versionKey(userID string) string {
+	return "token:version:" + userID
+}
+
+// versionTTL bounds how long a cached token version lives without being read from postgres again
+const versionTTL = 24 * time.Hour
+
+// SaveFamily ... Register a new token family with its current refresh token id
+func (r *Repository) SaveFamily(family string, tokenID string, expiration time.Time) error {
+	redisDB := r.InfoRedis.NewRedis(0)
+
+	err := redisDB.Set(r.InfoRedis.CTX, familyKey(family), tokenID, time.Until(expiration)).Err()
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return nil
+}
+
+// RotateFamily ... Replace the current refresh token id of a family, revoking the family on reuse
+func (r *Repository) RotateFamily(family string, oldTokenID string, newTokenID string, expiration time.Time) error {
+	redisDB := r.InfoRedis.NewRedis(0)
+	ttl := time.Until(expiration).Milliseconds()
+
+	result, err := rotateScript.Run(r.InfoRedis.CTX, redisDB, []string{familyKey(family)}, oldTokenID, newTokenID, ttl).Int()
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	switch result {
+	case 0:
+		return fiber.NewError(fiber.StatusUnauthorized, "refresh token revoked")
+	case -1:
+		return fiber.NewError(fiber.StatusUnauthorized, "refresh token reused, session revoked")
+	}
+
+	return nil
+}
+
+// IsFamilyActive ... Check whether a token family has not been revoked
+func (r *Repository) IsFamilyActive(family string) (bool, error) {
+	redisDB := r.InfoRedis.NewRedis(0)
+
+	exists, err := redisDB.Exists(r.InfoRedis.CTX, familyKey(family)).Result()
+	if err != nil {
+		return false, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return exists == 1, nil
+}
+
+// GetFamily ... Fetch the current refresh token id of a family, empty when the family is revoked
+func (r *Repository) GetFamily(family string) (string, error) {
+	redisDB := r.InfoRedis.NewRedis(0)
+
+	tokenID, err := redisDB.Get(r.InfoRedis.CTX, familyKey(family)).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return tokenID, nil
+}
+
+// RevokeToken ... Deny a single token until it expires on its own
+func (r *Repository) RevokeToken(tokenID string, expiration time.Time) error {
+	ttl := time.Until(expiration)
+	if ttl <= 0 {
+		return nil
+	}
+
+	redisDB := r.InfoRedis.NewRedis(0)
+	if err := redisDB.Set(r.InfoRedis.CTX, revokedKey(tokenID), 1, ttl).Err(); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return nil
+}
+
+// IsTokenRevoked ... Check whether a single token has been denied
+func (r *Repository) IsTokenRevoked(tokenID string) (bool, error) {
+	redisDB := r.InfoRedis.NewRedis(0)
+
+	exists, err := redisDB.Exists(r.InfoRedis.CTX, revokedKey(tokenID)).Result()
+	if err != nil {
+		return false, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return exists == 1, nil
+}
+
+// RevokeFamily ... Revoke a token family
+func (r *Repository) RevokeFamily(family string) error {
+	redisDB := r.InfoRedis.NewRedis(0)
+
+	if err := redisDB.Del(r.InfoRedis.CTX, familyKey(family)).Err(); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return nil
+}
+
+// GetVersion ... Fetch the cached token version of a user, found is false on a cache miss
+func (r *Repository) GetVersion(userID string) (version int, found bool, err error) {
+	redisDB := r.InfoRedis.NewRedis(0)
+
+	version, err = redisDB.Get(r.InfoRedis.CTX, versionKey(userID)).Int()
+	if err == redis.Nil {
+		return 0, false, nil
+	}
+
+	if err != nil {
+		return 0, false, fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return version, true, nil
+}
+
+// SetVersion ... Cache the token version of a user
+func (r *Repository) SetVersion(userID string, version int) error {
+	redisDB := r.InfoRedis.NewRedis(0)
+
+	if err := redisDB.Set(r.InfoRedis.CTX, versionKey(userID), version, versionTTL).Err(); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return nil
+}
+
+// FillVersion ... Cache the token version of a user read from postgres, unless a bump cached a newer one meanwhile
+func (r *Repository) FillVersion(userID string, version int) error {
+	redisDB := r.InfoRedis.NewRedis(0)
+
+	if err := redisDB.SetNX(r.InfoRedis.CTX, versionKey(userID), version, versionTTL).Err(); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, mssgConst.UnknownError)
+	}
+
+	return nil
+}
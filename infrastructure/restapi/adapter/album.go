@@ -0,0 +1,17 @@
+package adapter
+
+import (
+	albumService "hexagonal-fiber/application/usecases/album"
+	databsDomain "hexagonal-fiber/domain/database"
+	albumRepository "hexagonal-fiber/infrastructure/repository/postgres/album"
+	auditRepository "hexagonal-fiber/infrastructure/repository/postgres/audit"
+	albumController "hexagonal-fiber/infrastructure/restapi/controllers/album"
+)
+
+// AlbumAdapter is a function that returns an album controller
+func AlbumAdapter(db databsDomain.Database) *albumController.Controller {
+	aRepository := albumRepository.Repository{DB: db.Postgre}
+	auRepository := &auditRepository.Repository{DB: db.Postgre}
+	service := albumService.Service{AlbumRepository: aRepository, Audit: auRepository}
+	return &albumController.Controller{AlbumService: service}
+}
@@ -0,0 +1,21 @@
+package adapter
+
+import (
+	apiKeyService "hexagonal-fiber/application/usecases/apikey"
+	databsDomain "hexagonal-fiber/domain/database"
+	apiKeyRepository "hexagonal-fiber/infrastructure/repository/postgres/apikey"
+	auditRepository "hexagonal-fiber/infrastructure/repository/postgres/audit"
+	userRepository "hexagonal-fiber/infrastructure/repository/postgres/user"
+	apiKeyController "hexagonal-fiber/infrastructure/restapi/controllers/apikey"
+)
+
+// APIKeyAdapter is a function that returns an api key controller
+func APIKeyAdapter(db databsDomain.Database) *apiKeyController.Controller {
+	service := apiKeyService.Service{
+		APIKeyRepository: apiKeyRepository.Repository{DB: db.Postgre},
+		UserRepository:   userRepository.Repository{DB: db.Postgre},
+		Audit:            &auditRepository.Repository{DB: db.Postgre},
+	}
+
+	return &apiKeyController.Controller{APIKeyService: service}
+}
@@ -0,0 +1,15 @@
+package adapter
+
+import (
+	auditService "hexagonal-fiber/application/usecases/audit"
+	databsDomain "hexagonal-fiber/domain/database"
+	auditRepository "hexagonal-fiber/infrastructure/repository/postgres/audit"
+	auditController "hexagonal-fiber/infrastructure/restapi/controllers/audit"
+)
+
+// AuditAdapter is a function that returns an audit controller
+func AuditAdapter(db databsDomain.Database) *auditController.Controller {
+	aRepository := auditRepository.Repository{DB: db.Postgre}
+	service := auditService.Service{AuditRepository: aRepository}
+	return &auditController.Controller{AuditService: service}
+}
@@ -2,12 +2,26 @@
 package adapter
 
 import (
+	"fmt"
+
+	"hexagonal-fiber/application/security/oidc"
+	"hexagonal-fiber/application/security/password"
+	"hexagonal-fiber/application/services"
 	authService "hexagonal-fiber/application/usecases/auth"
 
 	databsDomain "hexagonal-fiber/domain/database"
 
+	auditRepository "hexagonal-fiber/infrastructure/repository/postgres/audit"
+	identityRepository "hexagonal-fiber/infrastructure/repository/postgres/identity"
+	mfaRepository "hexagonal-fiber/infrastructure/repository/postgres/mfa"
 	roleRepository "hexagonal-fiber/infrastructure/repository/postgres/role"
 	userRepository "hexagonal-fiber/infrastructure/repository/postgres/user"
+	limiterRepository "hexagonal-fiber/infrastructure/repository/redis/limiter"
+	lockoutRepository "hexagonal-fiber/infrastructure/repository/redis/lockout"
+	oidcRepository "hexagonal-fiber/infrastructure/repository/redis/oidc"
+	resetRepository "hexagonal-fiber/infrastructure/repository/redis/reset"
+	sessionRepository "hexagonal-fiber/infrastructure/repository/redis/session"
+	tokenRepository "hexagonal-fiber/infrastructure/repository/redis/token"
 	authController "hexagonal-fiber/infrastructure/restapi/controllers/auth"
 )
 
@@ -15,8 +29,44 @@ import (
 func AuthAdapter(db databsDomain.Database) *authController.Controller {
 	uRepository := userRepository.Repository{DB: db.Postgre}
 	rRepository := roleRepository.Repository{DB: db.Postgre}
+	mRepository := mfaRepository.Repository{DB: db.Postgre}
+	tRepository := tokenRepository.Repository{InfoRedis: db.Redis}
+	sRepository := sessionRepository.Repository{InfoRedis: db.Redis}
+	lRepository := limiterRepository.Repository{InfoRedis: db.Redis}
+	pRepository := resetRepository.Repository{InfoRedis: db.Redis}
+	oRepository := lockoutRepository.Repository{InfoRedis: db.Redis}
+	aRepository := &auditRepository.Repository{DB: db.Postgre}
+	iRepository := identityRepository.Repository{DB: db.Postgre}
+	dRepository := oidcRepository.Repository{InfoRedis: db.Redis}
+
+	mailer, err := services.NewMailer()
+	if err != nil {
+		panic(fmt.Errorf("fatal error in mailer: %s", err))
+	}
 
-	service := authService.Service{UserRepository: uRepository, RoleRepository: rRepository}
+	hasher, err := password.NewHasher()
+	if err != nil {
+		panic(fmt.Errorf("fatal error in password hasher: %s", err))
+	}
+
+	service := authService.Service{
+		UserRepository:     uRepository,
+		RoleRepository:     rRepository,
+		MFARepository:      mRepository,
+		TokenRepository:    tRepository,
+		SessionRepository:  sRepository,
+		LimiterRepository:  lRepository,
+		ResetRepository:    pRepository,
+		LockoutRepository:  oRepository,
+		IdentityRepository: iRepository,
+		OIDCRepository:     dRepository,
+		OIDC:               oidc.NewClient(),
+		Mailer:             mailer,
+		Events:             services.NewEventPublisher(aRepository),
+		Audit:              aRepository,
+		Passwords:          hasher,
+		TokenVersions:      services.TokenVersions{UserRepository: uRepository, TokenRepository: tRepository},
+	}
 
 	return &authController.Controller{
 		InfoRedis:   db.Redis,
@@ -3,6 +3,7 @@ package adapter
 import (
 	commentService "hexagonal-fiber/application/usecases/comment"
 	databsDomain "hexagonal-fiber/domain/database"
+	auditRepository "hexagonal-fiber/infrastructure/repository/postgres/audit"
 	commentRepository "hexagonal-fiber/infrastructure/repository/postgres/comment"
 	photoRepository "hexagonal-fiber/infrastructure/repository/postgres/photo"
 	commentController "hexagonal-fiber/infrastructure/restapi/controllers/comment"
@@ -12,10 +13,12 @@ import (
 func CommentAdapter(db databsDomain.Database) *commentController.Controller {
 	cRepository := commentRepository.Repository{DB: db.Postgre}
 	pRepository := photoRepository.Repository{DB: db.Postgre}
+	aRepository := &auditRepository.Repository{DB: db.Postgre}
 
 	service := commentService.Service{
 		CommentRepository: cRepository,
 		PhotoRepository:   pRepository,
+		Audit:             aRepository,
 	}
 	return &commentController.Controller{CommentService: service}
 }
@@ -0,0 +1,27 @@
+package adapter
+
+import (
+	"hexagonal-fiber/application/services"
+	oauthService "hexagonal-fiber/application/usecases/oauth"
+	databsDomain "hexagonal-fiber/domain/database"
+	userRepository "hexagonal-fiber/infrastructure/repository/postgres/user"
+	sessionRepository "hexagonal-fiber/infrastructure/repository/redis/session"
+	tokenRepository "hexagonal-fiber/infrastructure/repository/redis/token"
+	oauthController "hexagonal-fiber/infrastructure/restapi/controllers/oauth"
+)
+
+// OAuthAdapter is a function that returns an oauth controller
+func OAuthAdapter(db databsDomain.Database) *oauthController.Controller {
+	tRepository := tokenRepository.Repository{InfoRedis: db.Redis}
+	sRepository := sessionRepository.Repository{InfoRedis: db.Redis}
+
+	uRepository := userRepository.Repository{DB: db.Postgre}
+
+	service := oauthService.Service{
+		TokenRepository:   tRepository,
+		SessionRepository: sRepository,
+		TokenVersions:     services.TokenVersions{UserRepository: uRepository, TokenRepository: tRepository},
+	}
+
+	return &oauthController.Controller{OAuthService: service}
+}
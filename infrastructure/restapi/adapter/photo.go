@@ -2,15 +2,44 @@
 package adapter
 
 import (
+	"fmt"
+
+	"hexagonal-fiber/application/services"
 	photoService "hexagonal-fiber/application/usecases/photo"
 	databsDomain "hexagonal-fiber/domain/database"
+	auditRepository "hexagonal-fiber/infrastructure/repository/postgres/audit"
 	photoRepository "hexagonal-fiber/infrastructure/repository/postgres/photo"
+	userRepository "hexagonal-fiber/infrastructure/repository/postgres/user"
 	photoController "hexagonal-fiber/infrastructure/restapi/controllers/photo"
 )
 
 // PhotoAdapter is a function that returns a photo controller
 func PhotoAdapter(db databsDomain.Database) *photoController.Controller {
 	pRepository := photoRepository.Repository{DB: db.Postgre}
-	service := photoService.Service{PhotoRepository: pRepository}
+	aRepository := &auditRepository.Repository{DB: db.Postgre}
+
+	storage, err := services.NewStorage()
+	if err != nil {
+		panic(fmt.Errorf("fatal error in photo storage: %s", err))
+	}
+
+	uRepository := userRepository.Repository{DB: db.Postgre}
+
+	service := photoService.Service{PhotoRepository: pRepository, UserRepository: uRepository, Storage: storage, Audit: aRepository}
 	return &photoController.Controller{PhotoService: service}
 }
+
+// PhotoService is a function that returns the photo service the toolbox regenerates the variants with
+func PhotoService(db databsDomain.Database) photoService.Service {
+	storage, err := services.NewStorage()
+	if err != nil {
+		panic(fmt.Errorf("fatal error in photo storage: %s", err))
+	}
+
+	return photoService.Service{
+		PhotoRepository: photoRepository.Repository{DB: db.Postgre},
+		UserRepository:  userRepository.Repository{DB: db.Postgre},
+		Storage:         storage,
+		Audit:           &auditRepository.Repository{DB: db.Postgre},
+	}
+}
@@ -0,0 +1,51 @@
+package adapter
+
+import (
+	"fmt"
+
+	"hexagonal-fiber/application/services"
+	privacyService "hexagonal-fiber/application/usecases/privacy"
+	databsDomain "hexagonal-fiber/domain/database"
+	apiKeyRepository "hexagonal-fiber/infrastructure/repository/postgres/apikey"
+	auditRepository "hexagonal-fiber/infrastructure/repository/postgres/audit"
+	commentRepository "hexagonal-fiber/infrastructure/repository/postgres/comment"
+	identityRepository "hexagonal-fiber/infrastructure/repository/postgres/identity"
+	photoRepository "hexagonal-fiber/infrastructure/repository/postgres/photo"
+	sosmedRepository "hexagonal-fiber/infrastructure/repository/postgres/sosmed"
+	userRepository "hexagonal-fiber/infrastructure/repository/postgres/user"
+	exportRepository "hexagonal-fiber/infrastructure/repository/redis/export"
+	sessionRepository "hexagonal-fiber/infrastructure/repository/redis/session"
+	tokenRepository "hexagonal-fiber/infrastructure/repository/redis/token"
+	privacyController "hexagonal-fiber/infrastructure/restapi/controllers/privacy"
+)
+
+// PrivacyAdapter is a function that returns a privacy controller
+func PrivacyAdapter(db databsDomain.Database) *privacyController.Controller {
+	return &privacyController.Controller{PrivacyService: PrivacyService(db)}
+}
+
+// PrivacyService is a function that returns the privacy service, the toolbox erases the accounts with it too
+func PrivacyService(db databsDomain.Database) privacyService.Service {
+	uRepository := userRepository.Repository{DB: db.Postgre}
+	tRepository := tokenRepository.Repository{InfoRedis: db.Redis}
+
+	storage, err := services.NewStorage()
+	if err != nil {
+		panic(fmt.Errorf("fatal error in photo storage: %s", err))
+	}
+
+	return privacyService.Service{
+		UserRepository:        uRepository,
+		PhotoRepository:       photoRepository.Repository{DB: db.Postgre},
+		CommentRepository:     commentRepository.Repository{DB: db.Postgre},
+		SocialMediaRepository: sosmedRepository.Repository{DB: db.Postgre},
+		IdentityRepository:    identityRepository.Repository{DB: db.Postgre},
+		APIKeyRepository:      apiKeyRepository.Repository{DB: db.Postgre},
+		SessionRepository:     sessionRepository.Repository{InfoRedis: db.Redis},
+		TokenRepository:       tRepository,
+		ExportRepository:      exportRepository.Repository{InfoRedis: db.Redis},
+		Storage:               storage,
+		TokenVersions:         services.TokenVersions{UserRepository: uRepository, TokenRepository: tRepository},
+		Audit:                 &auditRepository.Repository{DB: db.Postgre},
+	}
+}
@@ -0,0 +1,32 @@
+package adapter
+
+import (
+	databsDomain "hexagonal-fiber/domain/database"
+
+	"hexagonal-fiber/application/services"
+	roleService "hexagonal-fiber/application/usecases/role"
+	auditRepository "hexagonal-fiber/infrastructure/repository/postgres/audit"
+	roleRepository "hexagonal-fiber/infrastructure/repository/postgres/role"
+	userRepository "hexagonal-fiber/infrastructure/repository/postgres/user"
+	tokenRepository "hexagonal-fiber/infrastructure/repository/redis/token"
+	roleController "hexagonal-fiber/infrastructure/restapi/controllers/role"
+)
+
+// RoleAdapter is a function that returns a role controller
+func RoleAdapter(db databsDomain.Database) *roleController.Controller {
+	rRepository := roleRepository.Repository{DB: db.Postgre}
+	uRepository := userRepository.Repository{DB: db.Postgre}
+	aRepository := &auditRepository.Repository{DB: db.Postgre}
+	tRepository := tokenRepository.Repository{InfoRedis: db.Redis}
+
+	service := roleService.Service{
+		RoleRepository: rRepository,
+		UserRepository: uRepository,
+		Audit:          aRepository,
+		TokenVersions:  services.TokenVersions{UserRepository: uRepository, TokenRepository: tRepository},
+	}
+
+	return &roleController.Controller{
+		RoleService: service,
+	}
+}
@@ -3,6 +3,7 @@ package adapter
 import (
 	sosmedService "hexagonal-fiber/application/usecases/sosmed"
 	databsDomain "hexagonal-fiber/domain/database"
+	auditRepository "hexagonal-fiber/infrastructure/repository/postgres/audit"
 	sosmedRepository "hexagonal-fiber/infrastructure/repository/postgres/sosmed"
 	sosmedController "hexagonal-fiber/infrastructure/restapi/controllers/sosmed"
 )
@@ -10,6 +11,7 @@ import (
 // SocialMediaAdapter is a function that returns a sosmed controller
 func SocialMediaAdapter(db databsDomain.Database) *sosmedController.Controller {
 	sRepository := sosmedRepository.Repository{DB: db.Postgre}
-	service := sosmedService.Service{SocialMediaRepository: sRepository}
+	aRepository := &auditRepository.Repository{DB: db.Postgre}
+	service := sosmedService.Service{SocialMediaRepository: sRepository, Audit: aRepository}
 	return &sosmedController.Controller{SocialMediaService: service}
 }
@@ -0,0 +1,31 @@
+package adapter
+
+import (
+	"fmt"
+
+	"hexagonal-fiber/application/services"
+	trashService "hexagonal-fiber/application/usecases/trash"
+	databsDomain "hexagonal-fiber/domain/database"
+	auditRepository "hexagonal-fiber/infrastructure/repository/postgres/audit"
+	commentRepository "hexagonal-fiber/infrastructure/repository/postgres/comment"
+	photoRepository "hexagonal-fiber/infrastructure/repository/postgres/photo"
+	sosmedRepository "hexagonal-fiber/infrastructure/repository/postgres/sosmed"
+	userRepository "hexagonal-fiber/infrastructure/repository/postgres/user"
+)
+
+// TrashService is a function that returns the trash service the toolbox purges the deleted resources with
+func TrashService(db databsDomain.Database) trashService.Service {
+	storage, err := services.NewStorage()
+	if err != nil {
+		panic(fmt.Errorf("fatal error in photo storage: %s", err))
+	}
+
+	return trashService.Service{
+		UserRepository:        userRepository.Repository{DB: db.Postgre},
+		PhotoRepository:       photoRepository.Repository{DB: db.Postgre},
+		CommentRepository:     commentRepository.Repository{DB: db.Postgre},
+		SocialMediaRepository: sosmedRepository.Repository{DB: db.Postgre},
+		Storage:               storage,
+		Audit:                 &auditRepository.Repository{DB: db.Postgre},
+	}
+}
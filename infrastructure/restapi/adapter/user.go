@@ -1,11 +1,17 @@
 package adapter
 
 import (
+	"fmt"
+
+	"hexagonal-fiber/application/security/password"
+	"hexagonal-fiber/application/services"
 	databsDomain "hexagonal-fiber/domain/database"
 
 	userService "hexagonal-fiber/application/usecases/user"
+	auditRepository "hexagonal-fiber/infrastructure/repository/postgres/audit"
 	roleRepository "hexagonal-fiber/infrastructure/repository/postgres/role"
 	userRepository "hexagonal-fiber/infrastructure/repository/postgres/user"
+	tokenRepository "hexagonal-fiber/infrastructure/repository/redis/token"
 	userController "hexagonal-fiber/infrastructure/restapi/controllers/user"
 )
 
@@ -13,8 +19,27 @@ import (
 func UserAdapter(db databsDomain.Database) *userController.Controller {
 	uRepository := userRepository.Repository{DB: db.Postgre}
 	rRepository := roleRepository.Repository{DB: db.Postgre}
+	aRepository := &auditRepository.Repository{DB: db.Postgre}
+	tRepository := tokenRepository.Repository{InfoRedis: db.Redis}
+
+	mailer, err := services.NewMailer()
+	if err != nil {
+		panic(fmt.Errorf("fatal error in mailer: %s", err))
+	}
 
-	service := userService.Service{UserRepository: uRepository, RoleRepository: rRepository}
+	hasher, err := password.NewHasher()
+	if err != nil {
+		panic(fmt.Errorf("fatal error in password hasher: %s", err))
+	}
+
+	service := userService.Service{
+		UserRepository: uRepository,
+		RoleRepository: rRepository,
+		Audit:          aRepository,
+		Passwords:      hasher,
+		TokenVersions:  services.TokenVersions{UserRepository: uRepository, TokenRepository: tRepository},
+		Mailer:         mailer,
+	}
 
 	return &userController.Controller{
 		InfoRedis:   db.Redis,
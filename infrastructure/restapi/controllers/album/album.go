@@ -0,0 +1,289 @@
+// Package album contains the album controller
+package album
+
+import (
+	useCaseAlbum "hexagonal-fiber/application/usecases/album"
+	albumDomain "hexagonal-fiber/domain/album"
+
+	secureDomain "hexagonal-fiber/domain/security"
+	"hexagonal-fiber/infrastructure/restapi/controllers"
+
+	authConst "hexagonal-fiber/utils/constant/auth"
+	mssgConst "hexagonal-fiber/utils/constant/message"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Controller is a struct that contains the album service
+type Controller struct {
+	AlbumService useCaseAlbum.Service
+}
+
+// NewAlbum godoc
+// @Tags album
+// @Summary Create New Album
+// @Description Create new album on the system, private and in manual ordering unless told otherwise
+// @Security ApiKeyAuth
+// @Accept  json
+// @Produce  json
+// @Param data body albumDomain.NewAlbum true "body data"
+// @Success 201 {object} albumDomain.Album
+// @Failure 400 {object} controllers.MessageResponse
+// @Failure 500 {object} controllers.MessageResponse
+// @Router /albums [post]
+func (c *Controller) NewAlbum(ctx *fiber.Ctx) (err error) {
+	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
+
+	var request albumDomain.NewAlbum
+	if err := ctx.BodyParser(&request); err != nil {
+		appError := fiber.NewError(fiber.StatusBadRequest, mssgConst.StatusBadRequest)
+		return ctx.Status(fiber.StatusBadRequest).JSON(appError)
+	}
+
+	if err = createValidation(&request); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err})
+	}
+
+	request.UserID = authData.UserID
+	album, err := c.AlbumService.Create(&request)
+	if err != nil {
+		return ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(album)
+}
+
+// GetPublicAlbums godoc
+// @Tags album
+// @Summary Get public Albums
+// @Security ApiKeyAuth
+// @Description Get the public albums of every user, latest first
+// @Param page query int false "page"
+// @Param limit query int false "limit"
+// @Success 200 {object} albumDomain.PaginationAlbum
+// @Failure 400 {object} controllers.MessageResponse
+// @Failure 500 {object} controllers.MessageResponse
+// @Router /albums [get]
+func (c *Controller) GetPublicAlbums(ctx *fiber.Ctx) (err error) {
+	page, limit, err := pagination(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err})
+	}
+
+	albums, err := c.AlbumService.GetPublic(page, limit)
+	if err != nil {
+		return ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(albums)
+}
+
+// GetAllOwnAlbums godoc
+// @Tags album
+// @Summary Get own Albums
+// @Security ApiKeyAuth
+// @Description Get the albums of the caller whatever their visibility, latest first
+// @Param page query int false "page"
+// @Param limit query int false "limit"
+// @Success 200 {object} albumDomain.PaginationAlbum
+// @Failure 400 {object} controllers.MessageResponse
+// @Failure 500 {object} controllers.MessageResponse
+// @Router /albums/own [get]
+func (c *Controller) GetAllOwnAlbums(ctx *fiber.Ctx) (err error) {
+	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
+
+	page, limit, err := pagination(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err})
+	}
+
+	albums, err := c.AlbumService.UserGetAll(authData.UserID, page, limit)
+	if err != nil {
+		return ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(albums)
+}
+
+// GetAlbumByID godoc
+// @Tags album
+// @Summary Get album by ID
+// @Description Get an album by ID, a private one only by its owner
+// @Param album_id path string true "id of album"
+// @Security ApiKeyAuth
+// @Success 200 {object} albumDomain.Album
+// @Failure 403 {object} controllers.MessageResponse
+// @Failure 404 {object} controllers.MessageResponse
+// @Router /albums/{album_id} [get]
+func (c *Controller) GetAlbumByID(ctx *fiber.Ctx) (err error) {
+	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
+
+	album, err := c.AlbumService.GetByID(authData, ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(album)
+}
+
+// GetAlbumPhotos godoc
+// @Tags album
+// @Summary Get the photos of an album
+// @Description Get the photos of an album in its ordering, the deleted photos are left out
+// @Param album_id path string true "id of album"
+// @Param page query int false "page"
+// @Param limit query int false "limit"
+// @Security ApiKeyAuth
+// @Success 200 {object} photoDomain.PaginationPhoto
+// @Failure 400 {object} controllers.MessageResponse
+// @Failure 403 {object} controllers.MessageResponse
+// @Failure 404 {object} controllers.MessageResponse
+// @Router /albums/{album_id}/photos [get]
+func (c *Controller) GetAlbumPhotos(ctx *fiber.Ctx) (err error) {
+	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
+
+	page, limit, err := pagination(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err})
+	}
+
+	photos, err := c.AlbumService.Photos(authData, ctx.Params("id"), page, limit)
+	if err != nil {
+		return ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(photos)
+}
+
+// UpdateAlbum godoc
+// @Tags album
+// @Summary Update album by ID
+// @Description Update the title, description, cover, ordering or visibility of an album, an empty cover removes it
+// @Param album_id path string true "id of album"
+// @Param data body albumDomain.UpdateAlbum true "body data"
+// @Security ApiKeyAuth
+// @Success 200 {object} albumDomain.Album
+// @Failure 400 {object} controllers.MessageResponse
+// @Failure 403 {object} controllers.MessageResponse
+// @Failure 404 {object} controllers.MessageResponse
+// @Router /albums/{album_id} [put]
+func (c *Controller) UpdateAlbum(ctx *fiber.Ctx) (err error) {
+	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
+
+	var request albumDomain.UpdateAlbum
+	if err := ctx.BodyParser(&request); err != nil {
+		appError := fiber.NewError(fiber.StatusBadRequest, mssgConst.StatusBadRequest)
+		return ctx.Status(fiber.StatusBadRequest).JSON(appError)
+	}
+
+	if err = updateValidation(&request); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err})
+	}
+
+	album, err := c.AlbumService.Update(authData, ctx.Params("id"), request)
+	if err != nil {
+		return ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(album)
+}
+
+// DeleteAlbum godoc
+// @Tags album
+// @Summary Delete album by ID
+// @Description Delete an album, the photos it shows are kept
+// @Param album_id path string true "id of album"
+// @Security ApiKeyAuth
+// @Success 200 {object} controllers.MessageResponse
+// @Failure 403 {object} controllers.MessageResponse
+// @Failure 404 {object} controllers.MessageResponse
+// @Router /albums/{album_id} [delete]
+func (c *Controller) DeleteAlbum(ctx *fiber.Ctx) (err error) {
+	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
+
+	if err = c.AlbumService.Delete(authData, ctx.Params("id")); err != nil {
+		return ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(controllers.MessageResponse{Message: "album deleted successfully"})
+}
+
+// AddAlbumPhotos godoc
+// @Tags album
+// @Summary Add photos to an album
+// @Description Add up to 100 photos of the album owner after the ones it has, the first one becomes the cover of an album without one
+// @Param album_id path string true "id of album"
+// @Param data body albumDomain.AlbumPhotosRequest true "body data"
+// @Security ApiKeyAuth
+// @Success 200 {object} albumDomain.Album
+// @Failure 400 {object} controllers.MessageResponse
+// @Failure 403 {object} controllers.MessageResponse
+// @Failure 404 {object} controllers.MessageResponse
+// @Router /albums/{album_id}/photos [post]
+func (c *Controller) AddAlbumPhotos(ctx *fiber.Ctx) (err error) {
+	return c.changePhotos(ctx, maxPhotoIDs, c.AlbumService.AddPhotos)
+}
+
+// RemoveAlbumPhotos godoc
+// @Tags album
+// @Summary Remove photos from an album
+// @Description Remove up to 100 photos from an album, the photos themselves are kept
+// @Param album_id path string true "id of album"
+// @Param data body albumDomain.AlbumPhotosRequest true "body data"
+// @Security ApiKeyAuth
+// @Success 200 {object} albumDomain.Album
+// @Failure 400 {object} controllers.MessageResponse
+// @Failure 403 {object} controllers.MessageResponse
+// @Failure 404 {object} controllers.MessageResponse
+// @Router /albums/{album_id}/photos [delete]
+func (c *Controller) RemoveAlbumPhotos(ctx *fiber.Ctx) (err error) {
+	return c.changePhotos(ctx, maxPhotoIDs, c.AlbumService.RemovePhotos)
+}
+
+// ReorderAlbumPhotos godoc
+// @Tags album
+// @Summary Reorder the photos of an album
+// @Description Give the manual ordering of an album, every photo the album shows must be listed once, its deleted photos are kept after them
+// @Param album_id path string true "id of album"
+// @Param data body albumDomain.AlbumPhotosRequest true "body data"
+// @Security ApiKeyAuth
+// @Success 200 {object} albumDomain.Album
+// @Failure 400 {object} controllers.MessageResponse
+// @Failure 403 {object} controllers.MessageResponse
+// @Failure 404 {object} controllers.MessageResponse
+// @Router /albums/{album_id}/photos/order [put]
+func (c *Controller) ReorderAlbumPhotos(ctx *fiber.Ctx) (err error) {
+	return c.changePhotos(ctx, maxOrderedIDs, c.AlbumService.Reorder)
+}
+
+// changePhotos parses and validates the photo ids of the body before changing the photos of the album with them
+func (c *Controller) changePhotos(ctx *fiber.Ctx, max int,
+	change func(*secureDomain.Claims, string, []string) (*albumDomain.Album, error)) (err error) {
+	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
+
+	var request albumDomain.AlbumPhotosRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		appError := fiber.NewError(fiber.StatusBadRequest, mssgConst.StatusBadRequest)
+		return ctx.Status(fiber.StatusBadRequest).JSON(appError)
+	}
+
+	if err = photosValidation(&request, max); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err})
+	}
+
+	album, err := change(authData, ctx.Params("id"), request.PhotoIDs)
+	if err != nil {
+		return ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(album)
+}
+
+func pagination(ctx *fiber.Ctx) (page int, limit int, err error) {
+	page = ctx.QueryInt("page", 1)
+	limit = ctx.QueryInt("limit", 20)
+	if page < 1 || limit < 1 || limit > 100 {
+		err = fiber.NewError(fiber.StatusBadRequest, "page must be positive and limit between 1 and 100")
+	}
+	return
+}
@@ -0,0 +1,101 @@
+package album
+
+import (
+	"fmt"
+	albumDomain "hexagonal-fiber/domain/album"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const (
+	// maxPhotoIDs is how many photos a single request may add or remove
+	maxPhotoIDs = 100
+	// maxOrderedIDs is how many photos an album may hold to be reordered, the whole order is given at once
+	maxOrderedIDs = 1000
+)
+
+func createValidation(request *albumDomain.NewAlbum) (err error) {
+	var errorsValidation []string
+
+	// Title cannot be empty
+	if len(request.Title) < 1 {
+		errorsValidation = append(errorsValidation, "Title cannot be empty")
+	}
+
+	if request.Ordering != "" && !validOrdering(request.Ordering) {
+		errorsValidation = append(errorsValidation, "Ordering must be manual, newest, oldest or taken")
+	}
+
+	if request.Visibility != "" && !validVisibility(request.Visibility) {
+		errorsValidation = append(errorsValidation, "Visibility must be private or public")
+	}
+
+	if errorsValidation != nil {
+		err = fiber.NewError(fiber.StatusBadRequest, strings.Join(errorsValidation, ", "))
+	}
+	return
+}
+
+func updateValidation(request *albumDomain.UpdateAlbum) (err error) {
+	var errorsValidation []string
+
+	// Title cannot be empty
+	if request.Title != nil {
+		if len(*request.Title) < 1 {
+			errorsValidation = append(errorsValidation, "Title cannot be empty")
+		}
+	}
+
+	// an empty cover removes it
+	if request.CoverPhotoID != nil && *request.CoverPhotoID != "" {
+		if !validPhotoID(*request.CoverPhotoID) {
+			errorsValidation = append(errorsValidation, "CoverPhotoID must be a photo id")
+		}
+	}
+
+	if request.Ordering != nil && !validOrdering(*request.Ordering) {
+		errorsValidation = append(errorsValidation, "Ordering must be manual, newest, oldest or taken")
+	}
+
+	if request.Visibility != nil && !validVisibility(*request.Visibility) {
+		errorsValidation = append(errorsValidation, "Visibility must be private or public")
+	}
+
+	if errorsValidation != nil {
+		err = fiber.NewError(fiber.StatusBadRequest, strings.Join(errorsValidation, ", "))
+	}
+	return
+}
+
+func photosValidation(request *albumDomain.AlbumPhotosRequest, max int) (err error) {
+	if len(request.PhotoIDs) < 1 || len(request.PhotoIDs) > max {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("PhotoIDs must hold between 1 and %d photo ids", max))
+	}
+
+	for _, photoId := range request.PhotoIDs {
+		if !validPhotoID(photoId) {
+			return fiber.NewError(fiber.StatusBadRequest, "PhotoIDs must hold photo ids")
+		}
+	}
+	return
+}
+
+// validPhotoID accepts the ids the way they are stored only, as the links compare them as text
+func validPhotoID(id string) bool {
+	parsed, err := uuid.Parse(id)
+	return err == nil && parsed.String() == id
+}
+
+func validOrdering(ordering string) bool {
+	switch ordering {
+	case albumDomain.Manual, albumDomain.Newest, albumDomain.Oldest, albumDomain.Taken:
+		return true
+	}
+	return false
+}
+
+func validVisibility(visibility string) bool {
+	return visibility == albumDomain.Private || visibility == albumDomain.Public
+}
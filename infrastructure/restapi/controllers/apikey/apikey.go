@@ -0,0 +1,99 @@
+// Package apikey contains the api key controller
+package apikey
+
+import (
+	useCaseAPIKey "hexagonal-fiber/application/usecases/apikey"
+	apiKeyDomain "hexagonal-fiber/domain/apikey"
+	secureDomain "hexagonal-fiber/domain/security"
+
+	authConst "hexagonal-fiber/utils/constant/auth"
+	mssgConst "hexagonal-fiber/utils/constant/message"
+
+	"hexagonal-fiber/infrastructure/restapi/controllers"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Controller is a struct that contains the api key service
+type Controller struct {
+	APIKeyService useCaseAPIKey.Service
+}
+
+// GetAllAPIKeys godoc
+// @Tags api key
+// @Summary Get api keys
+// @Description Get the api keys of the user, the caller's own when no user is given
+// @Security ApiKeyAuth
+// @Param user_id query string false "id of the owner of the keys"
+// @Success 200 {object} []apiKeyDomain.APIKey
+// @Failure 403 {object} controllers.MessageResponse
+// @Failure 500 {object} controllers.MessageResponse
+// @Router /api-keys [get]
+func (c *Controller) GetAllAPIKeys(ctx *fiber.Ctx) (err error) {
+	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
+
+	keys, err := c.APIKeyService.GetAll(authData, ctx.Query("user_id"))
+	if err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(keys)
+}
+
+// NewAPIKey godoc
+// @Tags api key
+// @Summary Create New api key
+// @Description Create a new api key scoped to some of the caller's permissions, the key is only shown in this response
+// @Security ApiKeyAuth
+// @Param data body apiKeyDomain.NewAPIKey true "body data"
+// @Success 201 {object} apiKeyDomain.CreatedAPIKey
+// @Failure 400 {object} controllers.MessageResponse
+// @Failure 403 {object} controllers.MessageResponse
+// @Failure 404 {object} controllers.MessageResponse
+// @Router /api-keys [post]
+func (c *Controller) NewAPIKey(ctx *fiber.Ctx) (err error) {
+	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
+
+	var request apiKeyDomain.NewAPIKey
+
+	if err = ctx.BodyParser(&request); err != nil {
+		appError := fiber.NewError(fiber.StatusBadRequest, mssgConst.StatusBadRequest)
+		return ctx.Status(fiber.StatusBadRequest).JSON(appError)
+	}
+
+	if err = controllers.Validation(request); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	key, err := c.APIKeyService.Create(authData, request)
+	if err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	ctx.Set(fiber.HeaderCacheControl, "no-store")
+	return ctx.Status(fiber.StatusCreated).JSON(key)
+}
+
+// DeleteAPIKey godoc
+// @Tags api key
+// @Summary Revoke api key by ID
+// @Description Revoke an api key, it stops working right away
+// @Param api_key_id path string true "id of api key"
+// @Security ApiKeyAuth
+// @Success 200 {object} controllers.MessageResponse
+// @Failure 403 {object} controllers.MessageResponse
+// @Failure 404 {object} controllers.MessageResponse
+// @Router /api-keys/{api_key_id} [delete]
+func (c *Controller) DeleteAPIKey(ctx *fiber.Ctx) (err error) {
+	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
+
+	if err = c.APIKeyService.Delete(authData, ctx.Params("id")); err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(controllers.MessageResponse{Message: "api key revoked successfully"})
+}
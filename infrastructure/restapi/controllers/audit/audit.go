@@ -0,0 +1,82 @@
+// Package audit contains the audit log controller
+package audit
+
+import (
+	"time"
+
+	useCaseAudit "hexagonal-fiber/application/usecases/audit"
+	auditDomain "hexagonal-fiber/domain/audit"
+
+	"hexagonal-fiber/infrastructure/restapi/controllers"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Controller is a struct that contains the audit service
+type Controller struct {
+	AuditService useCaseAudit.Service
+}
+
+// GetAllAuditEvents godoc
+// @Tags audit
+// @Summary Get audit events
+// @Description Get the audit log, newest first, filtered by actor, action, target and time range
+// @Security ApiKeyAuth
+// @Param actor_id query string false "id of the user who acted"
+// @Param action query string false "action, e.g. photo.delete"
+// @Param target_type query string false "type of the target, e.g. photo"
+// @Param target_id query string false "id of the target"
+// @Param from query string false "RFC 3339 time, inclusive"
+// @Param to query string false "RFC 3339 time, exclusive"
+// @Param page query int false "page"
+// @Param limit query int false "limit"
+// @Success 200 {object} auditDomain.PaginationEvent
+// @Failure 400 {object} controllers.MessageResponse
+// @Failure 403 {object} controllers.MessageResponse
+// @Router /audit-events [get]
+func (c *Controller) GetAllAuditEvents(ctx *fiber.Ctx) (err error) {
+	page := ctx.QueryInt("page", 1)
+	limit := ctx.QueryInt("limit", 20)
+	if page < 1 || limit < 1 || limit > 100 {
+		appError := fiber.NewError(fiber.StatusBadRequest, "page must be positive and limit between 1 and 100")
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": appError})
+	}
+
+	filter := auditDomain.Filter{
+		ActorID:    ctx.Query("actor_id"),
+		Action:     ctx.Query("action"),
+		TargetType: ctx.Query("target_type"),
+		TargetID:   ctx.Query("target_id"),
+	}
+
+	if filter.From, err = queryTime(ctx, "from"); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err})
+	}
+
+	if filter.To, err = queryTime(ctx, "to"); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err})
+	}
+
+	events, err := c.AuditService.GetAll(filter, page, limit)
+	if err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(events)
+}
+
+// queryTime reads an optional RFC 3339 time from the query string
+func queryTime(ctx *fiber.Ctx, key string) (*time.Time, error) {
+	value := ctx.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, key+" must be an RFC 3339 time")
+	}
+
+	return &parsed, nil
+}
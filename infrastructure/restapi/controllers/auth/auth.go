@@ -2,11 +2,12 @@
 package auth
 
 import (
-	"encoding/json"
 	useCaseAuth "hexagonal-fiber/application/usecases/auth"
 	userDomain "hexagonal-fiber/domain/user"
-	"time"
 
+	secureDomain "hexagonal-fiber/domain/security"
+
+	authConst "hexagonal-fiber/utils/constant/auth"
 	mssgConst "hexagonal-fiber/utils/constant/message"
 
 	redisRepo "hexagonal-fiber/infrastructure/repository/redis"
@@ -48,7 +49,7 @@ func (c *Controller) NewUser(ctx *fiber.Ctx) (err error) {
 
 	user, err := c.AuthService.Create(request)
 	if err != nil {
-		ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err})
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
 		return
 	}
 
@@ -61,6 +62,7 @@ func (c *Controller) NewUser(ctx *fiber.Ctx) (err error) {
 // @Description Auth user by email and password
 // @Param data body LoginRequest true "body data"
 // @Success 200 {object} userDomain.SecurityAuthenticatedUser
+// @Success 202 {object} userDomain.MFAChallenge
 // @Failure 400 {object} controllers.MessageResponse
 // @Failure 500 {object} controllers.MessageResponse
 // @Router /auth/login [post]
@@ -79,23 +81,15 @@ func (c *Controller) Login(ctx *fiber.Ctx) (err error) {
 		return
 	}
 
-	authDataUser, err := c.AuthService.LoginJWT(request)
+	authDataUser, challenge, err := c.AuthService.LoginJWT(request, controllers.ClientInfo(ctx))
 	if err != nil {
-		ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err})
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
 		return
 	}
 
-	authJSON, err := json.Marshal(authDataUser)
-	if err != nil {
-		ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err})
-		return
-	}
-
-	redisDB := c.InfoRedis.NewRedis(0)
-	status := redisDB.Set(c.InfoRedis.CTX, ctx.IP(), authJSON, 30*60*time.Second)
-	if status.Err() != nil {
-		ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "failed set redis"})
-		return
+	// the session is opened on /auth/login/mfa once the second factor is checked
+	if challenge != nil {
+		return ctx.Status(fiber.StatusAccepted).JSON(challenge)
 	}
 
 	return ctx.Status(fiber.StatusOK).JSON(authDataUser)
@@ -113,18 +107,252 @@ func (c *Controller) Login(ctx *fiber.Ctx) (err error) {
 func (c *Controller) GetAccessTokenByRefreshToken(ctx *fiber.Ctx) (err error) {
 	var request userDomain.AccessTokenRequest
 
-	if err = ctx.BodyParser(request); err != nil {
+	if err = ctx.BodyParser(&request); err != nil {
 		ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": mssgConst.ValidationError,
 		})
 		return
 	}
 
+	if err = controllers.Validation(request); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err})
+		return
+	}
+
 	authDataUser, err := c.AuthService.AccessTokenByRefreshToken(request.RefreshToken)
 	if err != nil {
-		ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err})
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
 		return
 	}
 
 	return ctx.Status(fiber.StatusOK).JSON(authDataUser)
 }
+
+// Logout godoc
+// @Tags auth
+// @Summary Logout current session
+// @Description Revoke the refresh token family of the current access token
+// @Security ApiKeyAuth
+// @Success 200 {object} controllers.MessageResponse
+// @Failure 401 {object} controllers.MessageResponse
+// @Failure 500 {object} controllers.MessageResponse
+// @Router /auth/logout [post]
+func (c *Controller) Logout(ctx *fiber.Ctx) (err error) {
+	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
+
+	if err = c.AuthService.Logout(authData); err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(controllers.MessageResponse{Message: "logged out successfully"})
+}
+
+// LogoutAll godoc
+// @Tags auth
+// @Summary Logout all sessions
+// @Description Revoke every refresh token family of the current user
+// @Security ApiKeyAuth
+// @Success 200 {object} controllers.MessageResponse
+// @Failure 401 {object} controllers.MessageResponse
+// @Failure 500 {object} controllers.MessageResponse
+// @Router /auth/logout-all [post]
+func (c *Controller) LogoutAll(ctx *fiber.Ctx) (err error) {
+	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
+
+	if err = c.AuthService.LogoutAll(authData.UserID); err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(controllers.MessageResponse{Message: "logged out from all sessions successfully"})
+}
+
+// GetSessions godoc
+// @Tags auth
+// @Summary Get all sessions
+// @Description Get the logged in devices of the current user
+// @Security ApiKeyAuth
+// @Success 200 {object} []secureDomain.Session
+// @Failure 401 {object} controllers.MessageResponse
+// @Failure 500 {object} controllers.MessageResponse
+// @Router /auth/sessions [get]
+func (c *Controller) GetSessions(ctx *fiber.Ctx) (err error) {
+	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
+
+	sessions, err := c.AuthService.GetSessions(authData)
+	if err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(sessions)
+}
+
+// DeleteSession godoc
+// @Tags auth
+// @Summary Revoke session by ID
+// @Description Revoke one logged in device of the current user
+// @Param session_id path string true "id of session"
+// @Security ApiKeyAuth
+// @Success 200 {object} controllers.MessageResponse
+// @Failure 404 {object} controllers.MessageResponse
+// @Failure 500 {object} controllers.MessageResponse
+// @Router /auth/sessions/{session_id} [delete]
+func (c *Controller) DeleteSession(ctx *fiber.Ctx) (err error) {
+	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
+
+	if err = c.AuthService.RevokeSession(authData.UserID, ctx.Params("id")); err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(controllers.MessageResponse{Message: "session revoked successfully"})
+}
+
+// VerifyEmail godoc
+// @Tags auth
+// @Summary Verify email
+// @Description Verify the email of a user by the token sent on registration
+// @Param token query string true "verification token"
+// @Success 200 {object} userDomain.ResponseUser
+// @Failure 401 {object} controllers.MessageResponse
+// @Failure 500 {object} controllers.MessageResponse
+// @Router /auth/verify [get]
+func (c *Controller) VerifyEmail(ctx *fiber.Ctx) (err error) {
+	token := ctx.Query("token")
+	if token == "" {
+		appError := fiber.NewError(fiber.StatusBadRequest, mssgConst.ValidationError)
+		return ctx.Status(fiber.StatusBadRequest).JSON(appError)
+	}
+
+	user, err := c.AuthService.VerifyEmail(token)
+	if err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(user.DomainToResponseMapper())
+}
+
+// ResendVerification godoc
+// @Tags auth
+// @Summary Resend verification email
+// @Description Send a new verification email, limited per email address
+// @Param data body userDomain.ResendVerificationRequest true "body data"
+// @Success 200 {object} controllers.MessageResponse
+// @Failure 400 {object} controllers.MessageResponse
+// @Failure 429 {object} controllers.MessageResponse
+// @Router /auth/verify/resend [post]
+func (c *Controller) ResendVerification(ctx *fiber.Ctx) (err error) {
+	var request userDomain.ResendVerificationRequest
+
+	if err = ctx.BodyParser(&request); err != nil {
+		appError := fiber.NewError(fiber.StatusBadRequest, mssgConst.StatusBadRequest)
+		return ctx.Status(fiber.StatusBadRequest).JSON(appError)
+	}
+
+	if err = controllers.Validation(request); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	if err = c.AuthService.ResendVerification(request.Email); err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(controllers.MessageResponse{Message: "verification email sent if the account exists"})
+}
+
+// ForgotPassword godoc
+// @Tags auth
+// @Summary Forgot password
+// @Description Send a single use password reset token to the email of the user
+// @Param data body userDomain.ForgotPasswordRequest true "body data"
+// @Success 200 {object} controllers.MessageResponse
+// @Failure 400 {object} controllers.MessageResponse
+// @Failure 429 {object} controllers.MessageResponse
+// @Router /auth/password/forgot [post]
+func (c *Controller) ForgotPassword(ctx *fiber.Ctx) (err error) {
+	var request userDomain.ForgotPasswordRequest
+
+	if err = ctx.BodyParser(&request); err != nil {
+		appError := fiber.NewError(fiber.StatusBadRequest, mssgConst.StatusBadRequest)
+		return ctx.Status(fiber.StatusBadRequest).JSON(appError)
+	}
+
+	if err = controllers.Validation(request); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	if err = c.AuthService.ForgotPassword(request.Email); err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(controllers.MessageResponse{Message: "reset email sent if the account exists"})
+}
+
+// ResetPassword godoc
+// @Tags auth
+// @Summary Reset password
+// @Description Set a new password with a reset token, every session of the user is revoked
+// @Param data body userDomain.ResetPasswordRequest true "body data"
+// @Success 200 {object} controllers.MessageResponse
+// @Failure 400 {object} controllers.MessageResponse
+// @Failure 401 {object} controllers.MessageResponse
+// @Router /auth/password/reset [post]
+func (c *Controller) ResetPassword(ctx *fiber.Ctx) (err error) {
+	var request userDomain.ResetPasswordRequest
+
+	if err = ctx.BodyParser(&request); err != nil {
+		appError := fiber.NewError(fiber.StatusBadRequest, mssgConst.StatusBadRequest)
+		return ctx.Status(fiber.StatusBadRequest).JSON(appError)
+	}
+
+	if err = controllers.Validation(request); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	if err = c.AuthService.ResetPassword(request); err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(controllers.MessageResponse{Message: "password reset successfully"})
+}
+
+// Unlock godoc
+// @Tags auth
+// @Summary Unlock account
+// @Description Lift the login lockout of an account, and of an IP when given
+// @Security ApiKeyAuth
+// @Param data body userDomain.UnlockRequest true "body data"
+// @Success 200 {object} controllers.MessageResponse
+// @Failure 400 {object} controllers.MessageResponse
+// @Failure 500 {object} controllers.MessageResponse
+// @Router /auth/unlock [post]
+func (c *Controller) Unlock(ctx *fiber.Ctx) (err error) {
+	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
+	var request userDomain.UnlockRequest
+
+	if err = ctx.BodyParser(&request); err != nil {
+		appError := fiber.NewError(fiber.StatusBadRequest, mssgConst.StatusBadRequest)
+		return ctx.Status(fiber.StatusBadRequest).JSON(appError)
+	}
+
+	if err = controllers.Validation(request); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	if err = c.AuthService.Unlock(request, authData.UserID); err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(controllers.MessageResponse{Message: "account unlocked successfully"})
+}
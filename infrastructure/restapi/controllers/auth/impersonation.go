@@ -0,0 +1,55 @@
+package auth
+
+import (
+	secureDomain "hexagonal-fiber/domain/security"
+
+	authConst "hexagonal-fiber/utils/constant/auth"
+
+	"hexagonal-fiber/infrastructure/restapi/controllers"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Impersonate godoc
+// @Tags auth
+// @Summary Impersonate user
+// @Description Get a short-lived access token acting as the user, every write made with it is audited
+// @Param user_id path string true "id of user"
+// @Security ApiKeyAuth
+// @Success 201 {object} userDomain.Impersonation
+// @Failure 400 {object} controllers.MessageResponse
+// @Failure 403 {object} controllers.MessageResponse
+// @Failure 404 {object} controllers.MessageResponse
+// @Router /auth/impersonate/{user_id} [post]
+func (c *Controller) Impersonate(ctx *fiber.Ctx) (err error) {
+	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
+
+	impersonation, err := c.AuthService.Impersonate(authData, ctx.Params("id"))
+	if err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	ctx.Set(fiber.HeaderCacheControl, "no-store")
+	return ctx.Status(fiber.StatusCreated).JSON(impersonation)
+}
+
+// StopImpersonation godoc
+// @Tags auth
+// @Summary Stop impersonation
+// @Description Revoke the impersonation token the request is made with
+// @Security ApiKeyAuth
+// @Success 200 {object} controllers.MessageResponse
+// @Failure 400 {object} controllers.MessageResponse
+// @Failure 401 {object} controllers.MessageResponse
+// @Router /auth/impersonate [delete]
+func (c *Controller) StopImpersonation(ctx *fiber.Ctx) (err error) {
+	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
+
+	if err = c.AuthService.StopImpersonation(authData); err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(controllers.MessageResponse{Message: "impersonation stopped successfully"})
+}
@@ -0,0 +1,194 @@
+package auth
+
+import (
+	secureDomain "hexagonal-fiber/domain/security"
+	userDomain "hexagonal-fiber/domain/user"
+
+	authConst "hexagonal-fiber/utils/constant/auth"
+	mssgConst "hexagonal-fiber/utils/constant/message"
+
+	"hexagonal-fiber/infrastructure/restapi/controllers"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// LoginMFA godoc
+// @Tags auth
+// @Summary Login second step
+// @Description Exchange the mfa token of a login and a totp or recovery code for a session
+// @Param data body userDomain.LoginMFARequest true "body data"
+// @Success 200 {object} userDomain.SecurityAuthenticatedUser
+// @Failure 400 {object} controllers.MessageResponse
+// @Failure 401 {object} controllers.MessageResponse
+// @Failure 429 {object} controllers.MessageResponse
+// @Router /auth/login/mfa [post]
+func (c *Controller) LoginMFA(ctx *fiber.Ctx) (err error) {
+	var request userDomain.LoginMFARequest
+
+	if err = ctx.BodyParser(&request); err != nil {
+		appError := fiber.NewError(fiber.StatusBadRequest, mssgConst.StatusBadRequest)
+		return ctx.Status(fiber.StatusBadRequest).JSON(appError)
+	}
+
+	if err = controllers.Validation(request); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	authDataUser, err := c.AuthService.LoginMFA(request, controllers.ClientInfo(ctx))
+	if err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(authDataUser)
+}
+
+// LoginMFAEnroll godoc
+// @Tags auth
+// @Summary Enroll two factor on login
+// @Description Start the two factor enrollment required by the role of the user, using the mfa token of the login
+// @Param data body userDomain.MFAEnrollRequest true "body data"
+// @Success 200 {object} userDomain.MFAEnrollment
+// @Failure 400 {object} controllers.MessageResponse
+// @Failure 401 {object} controllers.MessageResponse
+// @Failure 409 {object} controllers.MessageResponse
+// @Router /auth/login/mfa/enroll [post]
+func (c *Controller) LoginMFAEnroll(ctx *fiber.Ctx) (err error) {
+	var request userDomain.MFAEnrollRequest
+
+	if err = ctx.BodyParser(&request); err != nil {
+		appError := fiber.NewError(fiber.StatusBadRequest, mssgConst.StatusBadRequest)
+		return ctx.Status(fiber.StatusBadRequest).JSON(appError)
+	}
+
+	if err = controllers.Validation(request); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	enrollment, err := c.AuthService.LoginMFAEnroll(request.MFAToken)
+	if err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(enrollment)
+}
+
+// EnrollMFA godoc
+// @Tags auth
+// @Summary Enroll two factor
+// @Description Start the two factor enrollment of the current user
+// @Security ApiKeyAuth
+// @Success 200 {object} userDomain.MFAEnrollment
+// @Failure 401 {object} controllers.MessageResponse
+// @Failure 409 {object} controllers.MessageResponse
+// @Router /auth/mfa/enroll [post]
+func (c *Controller) EnrollMFA(ctx *fiber.Ctx) (err error) {
+	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
+
+	enrollment, err := c.AuthService.EnrollMFA(authData.UserID)
+	if err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(enrollment)
+}
+
+// ConfirmMFA godoc
+// @Tags auth
+// @Summary Confirm two factor
+// @Description Enable two factor for the current user with a first totp code and get the recovery codes
+// @Security ApiKeyAuth
+// @Param data body userDomain.MFACodeRequest true "body data"
+// @Success 200 {object} userDomain.RecoveryCodesResponse
+// @Failure 400 {object} controllers.MessageResponse
+// @Failure 401 {object} controllers.MessageResponse
+// @Router /auth/mfa/confirm [post]
+func (c *Controller) ConfirmMFA(ctx *fiber.Ctx) (err error) {
+	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
+	var request userDomain.MFACodeRequest
+
+	if err = ctx.BodyParser(&request); err != nil {
+		appError := fiber.NewError(fiber.StatusBadRequest, mssgConst.StatusBadRequest)
+		return ctx.Status(fiber.StatusBadRequest).JSON(appError)
+	}
+
+	if err = controllers.Validation(request); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	recoveryCodes, err := c.AuthService.ConfirmMFA(authData.UserID, request.Code)
+	if err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(userDomain.RecoveryCodesResponse{RecoveryCodes: recoveryCodes})
+}
+
+// DisableMFA godoc
+// @Tags auth
+// @Summary Disable two factor
+// @Description Disable two factor for the current user with a totp or recovery code
+// @Security ApiKeyAuth
+// @Param data body userDomain.MFACodeRequest true "body data"
+// @Success 200 {object} controllers.MessageResponse
+// @Failure 400 {object} controllers.MessageResponse
+// @Failure 401 {object} controllers.MessageResponse
+// @Failure 403 {object} controllers.MessageResponse
+// @Router /auth/mfa [delete]
+func (c *Controller) DisableMFA(ctx *fiber.Ctx) (err error) {
+	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
+	var request userDomain.MFACodeRequest
+
+	if err = ctx.BodyParser(&request); err != nil {
+		appError := fiber.NewError(fiber.StatusBadRequest, mssgConst.StatusBadRequest)
+		return ctx.Status(fiber.StatusBadRequest).JSON(appError)
+	}
+
+	if err = controllers.Validation(request); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	if err = c.AuthService.DisableMFA(authData.UserID, request.Code); err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(controllers.MessageResponse{Message: "two factor disabled successfully"})
+}
+
+// RequireRoleMFA godoc
+// @Tags auth
+// @Summary Require two factor for role
+// @Description Set whether the users of a role must log in with two factor
+// @Security ApiKeyAuth
+// @Param role_id path string true "id of role"
+// @Param data body userDomain.RoleMFARequest true "body data"
+// @Success 200 {object} userDomain.Role
+// @Failure 400 {object} controllers.MessageResponse
+// @Failure 404 {object} controllers.MessageResponse
+// @Router /auth/mfa/roles/{role_id} [put]
+func (c *Controller) RequireRoleMFA(ctx *fiber.Ctx) (err error) {
+	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
+
+	var request userDomain.RoleMFARequest
+
+	if err = ctx.BodyParser(&request); err != nil {
+		appError := fiber.NewError(fiber.StatusBadRequest, mssgConst.StatusBadRequest)
+		return ctx.Status(fiber.StatusBadRequest).JSON(appError)
+	}
+
+	role, err := c.AuthService.RequireRoleMFA(authData, ctx.Params("id"), request.Require)
+	if err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(role)
+}
@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"hexagonal-fiber/infrastructure/restapi/controllers"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetOIDCProviders godoc
+// @Tags auth
+// @Summary Get identity providers
+// @Description Get the names of the OpenID Connect providers users can log in with
+// @Success 200 {object} []string
+// @Failure 500 {object} controllers.MessageResponse
+// @Router /auth/oidc [get]
+func (c *Controller) GetOIDCProviders(ctx *fiber.Ctx) (err error) {
+	providers, err := c.AuthService.OIDCProviders()
+	if err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(providers)
+}
+
+// OIDCLogin godoc
+// @Tags auth
+// @Summary Login with identity provider
+// @Description Redirect to the login page of the OpenID Connect provider
+// @Param provider path string true "name of the provider"
+// @Success 302
+// @Failure 404 {object} controllers.MessageResponse
+// @Failure 502 {object} controllers.MessageResponse
+// @Router /auth/oidc/{provider} [get]
+func (c *Controller) OIDCLogin(ctx *fiber.Ctx) (err error) {
+	authURL, err := c.AuthService.OIDCLogin(ctx.Params("provider"))
+	if err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	ctx.Set(fiber.HeaderCacheControl, "no-store")
+	return ctx.Redirect(authURL, fiber.StatusFound)
+}
+
+// OIDCCallback godoc
+// @Tags auth
+// @Summary Identity provider callback
+// @Description Finish the login at the OpenID Connect provider, the account is created on the first login
+// @Param provider path string true "name of the provider"
+// @Param code query string true "authorization code"
+// @Param state query string true "state of the login"
+// @Success 200 {object} userDomain.SecurityAuthenticatedUser
+// @Success 202 {object} userDomain.MFAChallenge
+// @Failure 400 {object} controllers.MessageResponse
+// @Failure 401 {object} controllers.MessageResponse
+// @Failure 409 {object} controllers.MessageResponse
+// @Failure 502 {object} controllers.MessageResponse
+// @Router /auth/oidc/{provider}/callback [get]
+func (c *Controller) OIDCCallback(ctx *fiber.Ctx) (err error) {
+	if providerError := ctx.Query("error"); providerError != "" {
+		appError := fiber.NewError(fiber.StatusBadRequest, "identity provider answered "+providerError)
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": appError})
+	}
+
+	if ctx.Query("code") == "" || ctx.Query("state") == "" {
+		appError := fiber.NewError(fiber.StatusBadRequest, "code and state are required")
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": appError})
+	}
+
+	authDataUser, challenge, err := c.AuthService.OIDCCallback(ctx.Params("provider"), ctx.Query("state"), ctx.Query("code"), controllers.ClientInfo(ctx))
+	if err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	// the session is opened on /auth/login/mfa once the second factor is checked
+	if challenge != nil {
+		return ctx.Status(fiber.StatusAccepted).JSON(challenge)
+	}
+
+	ctx.Set(fiber.HeaderCacheControl, "no-store")
+	return ctx.Status(fiber.StatusOK).JSON(authDataUser)
+}
@@ -4,6 +4,7 @@ import (
 	useCaseComment "hexagonal-fiber/application/usecases/comment"
 	commentDomain "hexagonal-fiber/domain/comment"
 	secureDomain "hexagonal-fiber/domain/security"
+	"hexagonal-fiber/infrastructure/restapi/controllers"
 
 	authConst "hexagonal-fiber/utils/constant/auth"
 	mssgConst "hexagonal-fiber/utils/constant/message"
@@ -115,10 +116,12 @@ func (c *Controller) GetAllOwnComments(ctx *fiber.Ctx) (err error) {
 // @Failure 500 {object} controllers.MessageResponse
 // @Router /comment/{comment_id} [get]
 func (c *Controller) GetCommentByID(ctx *fiber.Ctx) (err error) {
+	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
 	commentID := ctx.Params("id")
-	comment, err := c.CommentService.GetByID(commentID)
+
+	comment, err := c.CommentService.GetByID(authData, commentID)
 	if err != nil {
-		ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err})
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
 		return
 	}
 
@@ -150,20 +153,10 @@ func (c *Controller) UpdateComment(ctx *fiber.Ctx) (err error) {
 		return
 	}
 
-	var comment *commentDomain.Comment
-
-	if authData.Role == "admin" {
-		comment, err = c.CommentService.Update(commentID, request)
-		if err != nil {
-			ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err})
-			return
-		}
-	} else {
-		comment, err = c.CommentService.UserUpdate(commentID, authData.UserID, request)
-		if err != nil {
-			ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err})
-			return
-		}
+	comment, err := c.CommentService.Update(authData, commentID, request)
+	if err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
 	}
 
 	return ctx.Status(fiber.StatusOK).JSON(comment)
@@ -180,11 +173,63 @@ func (c *Controller) UpdateComment(ctx *fiber.Ctx) (err error) {
 // @Failure 500 {object} controllers.MessageResponse
 // @Router /comment/{comment_id} [get]
 func (c *Controller) DeleteComment(ctx *fiber.Ctx) (err error) {
+	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
 	commentID := ctx.Params("id")
-	if err = c.CommentService.Delete(commentID); err != nil {
-		ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err})
+
+	if err = c.CommentService.Delete(authData, commentID); err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
 		return
 	}
 
 	return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "resource deleted successfully"})
 }
+
+// GetCommentTrash godoc
+// @Tags comment
+// @Summary Get deleted comments
+// @Description Get the deleted comments of the user kept until purged, the caller's own when no user is given
+// @Security ApiKeyAuth
+// @Param user_id query string false "id of the owner of the comments"
+// @Param page query int false "page"
+// @Param limit query int false "limit"
+// @Success 200 {object} commentDomain.PaginationComment
+// @Failure 403 {object} controllers.MessageResponse
+// @Failure 500 {object} controllers.MessageResponse
+// @Router /comments/trash [get]
+func (c *Controller) GetCommentTrash(ctx *fiber.Ctx) (err error) {
+	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
+
+	page := ctx.QueryInt("page", 1)
+	limit := ctx.QueryInt("limit", 20)
+
+	trash, err := c.CommentService.Trash(authData, ctx.Query("user_id", authData.UserID), page, limit)
+	if err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(trash)
+}
+
+// RestoreComment godoc
+// @Tags comment
+// @Summary Restore a deleted comment
+// @Description Bring a deleted comment back from the trash, a comment deleted with its photo comes back with the photo
+// @Param comment_id path string true "id of comment"
+// @Security ApiKeyAuth
+// @Success 200 {object} commentDomain.Comment
+// @Failure 403 {object} controllers.MessageResponse
+// @Failure 404 {object} controllers.MessageResponse
+// @Failure 409 {object} controllers.MessageResponse
+// @Router /comments/{comment_id}/restore [post]
+func (c *Controller) RestoreComment(ctx *fiber.Ctx) (err error) {
+	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
+
+	comment, err := c.CommentService.Restore(authData, ctx.Params("id"))
+	if err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(comment)
+}
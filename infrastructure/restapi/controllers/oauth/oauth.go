@@ -0,0 +1,155 @@
+// Package oauth contains the token introspection and revocation controller, its responses follow
+// the shape of RFC 7662 and RFC 7009 rather than the one of the other controllers
+package oauth
+
+import (
+	"encoding/base64"
+	"net/url"
+	"strings"
+
+	useCaseOAuth "hexagonal-fiber/application/usecases/oauth"
+	secureDomain "hexagonal-fiber/domain/security"
+
+	"hexagonal-fiber/infrastructure/restapi/controllers"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Controller is a struct that contains the oauth service
+type Controller struct {
+	OAuthService useCaseOAuth.Service
+}
+
+// oauth error codes, RFC 6749 section 5.2 and RFC 7009 section 2.2.1
+const (
+	errorInvalidRequest       = "invalid_request"
+	errorInvalidClient        = "invalid_client"
+	errorUnsupportedTokenType = "unsupported_token_type"
+	errorServer               = "server_error"
+)
+
+// Introspect godoc
+// @Tags oauth
+// @Summary Introspect a token
+// @Description Report whether an access or refresh token is active, for the services authenticated by client credentials
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param token formData string true "token to introspect"
+// @Param token_type_hint formData string false "access_token or refresh_token"
+// @Success 200 {object} secureDomain.Introspection
+// @Failure 400 {object} controllers.MessageResponse
+// @Failure 401 {object} controllers.MessageResponse
+// @Router /oauth/introspect [post]
+func (c *Controller) Introspect(ctx *fiber.Ctx) (err error) {
+	request, err := c.tokenRequest(ctx)
+	if err != nil {
+		return oauthError(ctx, err)
+	}
+
+	introspection, err := c.OAuthService.Introspect(*request)
+	if err != nil {
+		return oauthError(ctx, fiber.NewError(fiber.StatusInternalServerError, errorServer))
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(introspection)
+}
+
+// Revoke godoc
+// @Tags oauth
+// @Summary Revoke a token
+// @Description Revoke an access token, or a refresh token together with its session, for the services authenticated by client credentials
+// @Accept x-www-form-urlencoded
+// @Param token formData string true "token to revoke"
+// @Param token_type_hint formData string false "access_token or refresh_token"
+// @Success 200
+// @Failure 400 {object} controllers.MessageResponse
+// @Failure 401 {object} controllers.MessageResponse
+// @Router /oauth/revoke [post]
+func (c *Controller) Revoke(ctx *fiber.Ctx) (err error) {
+	request, err := c.tokenRequest(ctx)
+	if err != nil {
+		return oauthError(ctx, err)
+	}
+
+	hint := request.TokenTypeHint
+	if hint != "" && hint != secureDomain.TokenTypeHintAccess && hint != secureDomain.TokenTypeHintRefresh {
+		return oauthError(ctx, fiber.NewError(fiber.StatusBadRequest, errorUnsupportedTokenType))
+	}
+
+	if err = c.OAuthService.Revoke(*request); err != nil {
+		return oauthError(ctx, fiber.NewError(fiber.StatusInternalServerError, errorServer))
+	}
+
+	return ctx.SendStatus(fiber.StatusOK)
+}
+
+// tokenRequest authenticates the calling service and parses the request body
+func (c *Controller) tokenRequest(ctx *fiber.Ctx) (*secureDomain.TokenRequest, error) {
+	ctx.Set(fiber.HeaderCacheControl, "no-store")
+	ctx.Set(fiber.HeaderPragma, "no-cache")
+
+	clientID, clientSecret, ok := clientCredentials(ctx)
+	if !ok || c.OAuthService.AuthenticateClient(clientID, clientSecret) != nil {
+		ctx.Set(fiber.HeaderWWWAuthenticate, `Basic realm="oauth"`)
+		return nil, fiber.NewError(fiber.StatusUnauthorized, errorInvalidClient)
+	}
+
+	var request secureDomain.TokenRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, errorInvalidRequest)
+	}
+
+	if err := controllers.Validation(request); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, errorInvalidRequest)
+	}
+
+	return &request, nil
+}
+
+// clientCredentials reads the client credentials from the basic authorization header,
+// or from the body for the clients not able to send the header, RFC 6749 section 2.3.1
+func clientCredentials(ctx *fiber.Ctx) (string, string, bool) {
+	authorization := ctx.Get(fiber.HeaderAuthorization)
+	if authorization == "" {
+		clientID, clientSecret := ctx.FormValue("client_id"), ctx.FormValue("client_secret")
+		return clientID, clientSecret, clientID != "" && clientSecret != ""
+	}
+
+	encoded, found := strings.CutPrefix(authorization, "Basic ")
+	if !found {
+		return "", "", false
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", "", false
+	}
+
+	clientID, clientSecret, found := strings.Cut(string(decoded), ":")
+	if !found {
+		return "", "", false
+	}
+
+	// the credentials are form encoded before being base64 encoded
+	clientID, err = url.QueryUnescape(clientID)
+	if err != nil {
+		return "", "", false
+	}
+
+	clientSecret, err = url.QueryUnescape(clientSecret)
+	if err != nil {
+		return "", "", false
+	}
+
+	return clientID, clientSecret, true
+}
+
+// oauthError writes the error as an oauth error code
+func oauthError(ctx *fiber.Ctx, err error) error {
+	code := errorServer
+	if fiberErr, ok := err.(*fiber.Error); ok {
+		code = fiberErr.Message
+	}
+
+	return ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": code})
+}
@@ -2,10 +2,13 @@
 package photo
 
 import (
+	"strings"
+
 	useCasePhoto "hexagonal-fiber/application/usecases/photo"
 	photoDomain "hexagonal-fiber/domain/photo"
 
 	secureDomain "hexagonal-fiber/domain/security"
+	"hexagonal-fiber/infrastructure/restapi/controllers"
 
 	authConst "hexagonal-fiber/utils/constant/auth"
 	mssgConst "hexagonal-fiber/utils/constant/message"
@@ -21,13 +24,19 @@ type Controller struct {
 // NewPhoto godoc
 // @Tags photo
 // @Summary Create New PhotoName
-// @Description Create new photo on the system
+// @Description Create new photo on the system, either linking an outside url with a json body or uploading a jpeg, png or gif image as multipart form
 // @Security ApiKeyAuth
-// @Accept  json
+// @Accept  json,mpfd
 // @Produce  json
-// @Param data body NewPhoto true "body data"
+// @Param data body NewPhoto false "body data"
+// @Param title formData string false "title of the uploaded photo"
+// @Param caption formData string false "caption of the uploaded photo"
+// @Param photo formData file false "image of the uploaded photo"
 // @Success 200 {object} photoDomain.Photo
 // @Failure 400 {object} controllers.MessageResponse
+// @Failure 409 {object} controllers.MessageResponse "near duplicate of a photo of the user, when duplicates are rejected"
+// @Failure 413 {object} controllers.MessageResponse
+// @Failure 415 {object} controllers.MessageResponse
 // @Failure 500 {object} controllers.MessageResponse
 // @Router /photo [post]
 func (c *Controller) NewPhoto(ctx *fiber.Ctx) (err error) {
@@ -40,6 +49,10 @@ func (c *Controller) NewPhoto(ctx *fiber.Ctx) (err error) {
 	}
 
 	request.UserID = authData.UserID
+	if strings.HasPrefix(string(ctx.Request().Header.ContentType()), fiber.MIMEMultipartForm) {
+		return c.uploadPhoto(ctx, &request)
+	}
+
 	if err = createValidation(request); err != nil {
 		appError := fiber.NewError(fiber.StatusBadRequest, mssgConst.ValidationError)
 		return ctx.Status(fiber.StatusBadRequest).JSON(appError)
@@ -54,6 +67,33 @@ func (c *Controller) NewPhoto(ctx *fiber.Ctx) (err error) {
 	return ctx.Status(fiber.StatusCreated).JSON(photo)
 }
 
+// uploadPhoto creates the photo from the image of the multipart form
+func (c *Controller) uploadPhoto(ctx *fiber.Ctx, request *photoDomain.NewPhoto) (err error) {
+	if err = uploadValidation(request); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err})
+	}
+
+	fileHeader, err := ctx.FormFile("photo")
+	if err != nil {
+		appError := fiber.NewError(fiber.StatusBadRequest, "photo file is required")
+		return ctx.Status(fiber.StatusBadRequest).JSON(appError)
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		appError := fiber.NewError(fiber.StatusBadRequest, mssgConst.StatusBadRequest)
+		return ctx.Status(fiber.StatusBadRequest).JSON(appError)
+	}
+	defer file.Close()
+
+	photo, err := c.PhotoService.Upload(request, file, fileHeader.Size)
+	if err != nil {
+		return ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(photo)
+}
+
 // GetAllPhotos godoc
 // @Tags photo
 // @Summary Get all Photos
@@ -135,16 +175,54 @@ func (c *Controller) GetPhotoWithComments(ctx *fiber.Ctx) (err error) {
 // @Failure 500 {object} controllers.MessageResponse
 // @Router /photo/{photo_id} [get]
 func (c *Controller) GetPhotoByID(ctx *fiber.Ctx) (err error) {
+	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
 	photoID := ctx.Params("id")
-	photo, err := c.PhotoService.GetByID(photoID)
+
+	photo, err := c.PhotoService.GetByID(authData, photoID)
 	if err != nil {
-		ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err})
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
 		return
 	}
 
 	return ctx.Status(fiber.StatusCreated).JSON(photo)
 }
 
+// DownloadPhoto godoc
+// @Tags photo
+// @Summary Download the image of a photo
+// @Description Serve the uploaded image of a photo, or redirect to the storage or to the outside url it is served from
+// @Param photo_id path string true "id of photo"
+// @Param variant query string false "name of the variant like thumb, the original image serves a variant not generated yet"
+// @Security ApiKeyAuth
+// @Produce image/jpeg,image/png,image/gif
+// @Success 200 {file} binary
+// @Success 302 {string} string "location of the image"
+// @Failure 404 {object} controllers.MessageResponse
+// @Router /photos/{photo_id}/download [get]
+func (c *Controller) DownloadPhoto(ctx *fiber.Ctx) (err error) {
+	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
+
+	download, err := c.PhotoService.Download(authData, ctx.Params("id"), ctx.Query("variant"))
+	if err != nil {
+		return ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+	}
+
+	if download.URL != "" {
+		return ctx.Redirect(download.URL, fiber.StatusFound)
+	}
+
+	ctx.Set(fiber.HeaderContentType, download.Object.ContentType)
+	ctx.Set(fiber.HeaderETag, `"`+download.Checksum+`"`)
+	ctx.Set(fiber.HeaderCacheControl, "private, max-age=86400")
+
+	if ctx.Get(fiber.HeaderIfNoneMatch) == `"`+download.Checksum+`"` {
+		download.Object.Body.Close()
+		return ctx.SendStatus(fiber.StatusNotModified)
+	}
+
+	return ctx.SendStream(download.Object.Body, int(download.Object.Size))
+}
+
 // UpdatePhoto godoc
 // @Tags photo
 // @Summary Get photos by ID
@@ -170,20 +248,10 @@ func (c *Controller) UpdatePhoto(ctx *fiber.Ctx) (err error) {
 		return
 	}
 
-	var photo *photoDomain.Photo
-
-	if authData.Role == "admin" {
-		photo, err = c.PhotoService.Update(photoID, request)
-		if err != nil {
-			ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err})
-			return
-		}
-	} else {
-		photo, err = c.PhotoService.UserUpdate(photoID, authData.UserID, request)
-		if err != nil {
-			ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err})
-			return
-		}
+	photo, err := c.PhotoService.Update(authData, photoID, request)
+	if err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
 	}
 
 	return ctx.Status(fiber.StatusOK).JSON(photo)
@@ -200,12 +268,91 @@ func (c *Controller) UpdatePhoto(ctx *fiber.Ctx) (err error) {
 // @Failure 500 {object} controllers.MessageResponse
 // @Router /photo/{photo_id} [get]
 func (c *Controller) DeletePhoto(ctx *fiber.Ctx) (err error) {
+	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
 	photoID := ctx.Params("id")
-	if err = c.PhotoService.Delete(photoID); err != nil {
-		ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err})
+
+	if err = c.PhotoService.Delete(authData, photoID); err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
 		return
 	}
 
 	return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "resource deleted successfully"})
 
 }
+
+// GetPhotoTrash godoc
+// @Tags photo
+// @Summary Get deleted photos
+// @Description Get the deleted photos of the user kept until purged, the caller's own when no user is given
+// @Security ApiKeyAuth
+// @Param user_id query string false "id of the owner of the photos"
+// @Param page query int false "page"
+// @Param limit query int false "limit"
+// @Success 200 {object} photoDomain.PaginationPhoto
+// @Failure 403 {object} controllers.MessageResponse
+// @Failure 500 {object} controllers.MessageResponse
+// @Router /photos/trash [get]
+func (c *Controller) GetPhotoTrash(ctx *fiber.Ctx) (err error) {
+	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
+
+	page := ctx.QueryInt("page", 1)
+	limit := ctx.QueryInt("limit", 20)
+
+	trash, err := c.PhotoService.Trash(authData, ctx.Query("user_id", authData.UserID), page, limit)
+	if err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(trash)
+}
+
+// GetDuplicatePhotos godoc
+// @Tags photo
+// @Summary Get near identical photos
+// @Description Get the groups of near identical photos across every user found by the last run of the photos hash command, the groups posted by the most users first
+// @Security ApiKeyAuth
+// @Param page query int false "page"
+// @Param limit query int false "limit"
+// @Success 200 {object} photoDomain.PaginationDuplicate
+// @Failure 400 {object} controllers.MessageResponse
+// @Failure 403 {object} controllers.MessageResponse
+// @Router /admin/photos/duplicates [get]
+func (c *Controller) GetDuplicatePhotos(ctx *fiber.Ctx) (err error) {
+	page := ctx.QueryInt("page", 1)
+	limit := ctx.QueryInt("limit", 20)
+	if page < 1 || limit < 1 || limit > 100 {
+		appError := fiber.NewError(fiber.StatusBadRequest, "page must be positive and limit between 1 and 100")
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": appError})
+	}
+
+	duplicates, err := c.PhotoService.Duplicates(page, limit)
+	if err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(duplicates)
+}
+
+// RestorePhoto godoc
+// @Tags photo
+// @Summary Restore a deleted photo
+// @Description Bring a deleted photo back from the trash along with the comments deleted with it
+// @Param photo_id path string true "id of photo"
+// @Security ApiKeyAuth
+// @Success 200 {object} photoDomain.Photo
+// @Failure 403 {object} controllers.MessageResponse
+// @Failure 404 {object} controllers.MessageResponse
+// @Router /photos/{photo_id}/restore [post]
+func (c *Controller) RestorePhoto(ctx *fiber.Ctx) (err error) {
+	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
+
+	photo, err := c.PhotoService.Restore(authData, ctx.Params("id"))
+	if err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(photo)
+}
@@ -17,10 +17,10 @@ func updateValidation(request *photoDomain.UpdatePhoto) (err error) {
 		}
 	}
 
-	// PhotoUrl cannot be empty
+	// PhotoUrl must be an absolute http or https url
 	if request.PhotoUrl != nil {
-		if len(*request.PhotoUrl) < 1 {
-			errorsValidation = append(errorsValidation, "PhotoUrl cannot be empty")
+		if !photoDomain.IsLinkURL(*request.PhotoUrl) {
+			errorsValidation = append(errorsValidation, "PhotoUrl must be an absolute http or https url")
 		}
 	}
 
@@ -38,9 +38,9 @@ func createValidation(request photoDomain.NewPhoto) (err error) {
 		errorsValidation = append(errorsValidation, "Title cannot be empty")
 	}
 
-	// PhotoUrl cannot be empty
-	if len(request.PhotoUrl) < 1 {
-		errorsValidation = append(errorsValidation, "PhotoUrl cannot be empty")
+	// PhotoUrl must be an absolute http or https url
+	if !photoDomain.IsLinkURL(request.PhotoUrl) {
+		errorsValidation = append(errorsValidation, "PhotoUrl must be an absolute http or https url")
 	}
 
 	if errorsValidation != nil {
@@ -48,3 +48,11 @@ func createValidation(request photoDomain.NewPhoto) (err error) {
 	}
 	return
 }
+
+func uploadValidation(request *photoDomain.NewPhoto) (err error) {
+	// Title cannot be empty
+	if len(request.Title) < 1 {
+		err = fiber.NewError(fiber.StatusBadRequest, "Title cannot be empty")
+	}
+	return
+}
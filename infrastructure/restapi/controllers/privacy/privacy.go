@@ -0,0 +1,122 @@
+// Package privacy contains the personal data controller
+package privacy
+
+import (
+	useCasePrivacy "hexagonal-fiber/application/usecases/privacy"
+	secureDomain "hexagonal-fiber/domain/security"
+
+	authConst "hexagonal-fiber/utils/constant/auth"
+
+	"hexagonal-fiber/infrastructure/restapi/controllers"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Controller is a struct that contains the privacy service
+type Controller struct {
+	PrivacyService useCasePrivacy.Service
+}
+
+// RequestExport godoc
+// @Tags privacy
+// @Summary Export personal data
+// @Description Start assembling an archive of the personal data of the current user, poll its status until it is ready
+// @Security ApiKeyAuth
+// @Success 202 {object} privacyDomain.Export
+// @Failure 403 {object} controllers.MessageResponse
+// @Failure 409 {object} controllers.MessageResponse
+// @Router /user/me/export [post]
+func (c *Controller) RequestExport(ctx *fiber.Ctx) (err error) {
+	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
+
+	export, err := c.PrivacyService.RequestExport(authData)
+	if err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	return ctx.Status(fiber.StatusAccepted).JSON(export)
+}
+
+// GetExport godoc
+// @Tags privacy
+// @Summary Get personal data export
+// @Description Get the status of the last personal data export of the current user
+// @Security ApiKeyAuth
+// @Success 200 {object} privacyDomain.Export
+// @Failure 404 {object} controllers.MessageResponse
+// @Router /user/me/export [get]
+func (c *Controller) GetExport(ctx *fiber.Ctx) (err error) {
+	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
+
+	export, err := c.PrivacyService.GetExport(authData)
+	if err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(export)
+}
+
+// DownloadExport godoc
+// @Tags privacy
+// @Summary Download personal data export
+// @Description Download the zip archive of the last personal data export of the current user
+// @Security ApiKeyAuth
+// @Produce application/zip
+// @Success 200 {file} file
+// @Failure 403 {object} controllers.MessageResponse
+// @Failure 404 {object} controllers.MessageResponse
+// @Router /user/me/export/download [get]
+func (c *Controller) DownloadExport(ctx *fiber.Ctx) (err error) {
+	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
+
+	export, err := c.PrivacyService.ExportArchive(authData)
+	if err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	ctx.Set(fiber.HeaderCacheControl, "no-store")
+	return ctx.Download(export.Path, "personal-data-"+export.ID+".zip")
+}
+
+// RequestErasure godoc
+// @Tags privacy
+// @Summary Erase account
+// @Description Schedule the erasure of the current user and everything it owns after a grace period
+// @Security ApiKeyAuth
+// @Success 202 {object} privacyDomain.Erasure
+// @Failure 403 {object} controllers.MessageResponse
+// @Router /user/me/erasure [post]
+func (c *Controller) RequestErasure(ctx *fiber.Ctx) (err error) {
+	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
+
+	erasure, err := c.PrivacyService.RequestErasure(authData)
+	if err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	return ctx.Status(fiber.StatusAccepted).JSON(erasure)
+}
+
+// CancelErasure godoc
+// @Tags privacy
+// @Summary Cancel account erasure
+// @Description Cancel the scheduled erasure of the current user during the grace period
+// @Security ApiKeyAuth
+// @Success 200 {object} controllers.MessageResponse
+// @Failure 403 {object} controllers.MessageResponse
+// @Failure 404 {object} controllers.MessageResponse
+// @Router /user/me/erasure [delete]
+func (c *Controller) CancelErasure(ctx *fiber.Ctx) (err error) {
+	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
+
+	if err = c.PrivacyService.CancelErasure(authData); err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(controllers.MessageResponse{Message: "erasure cancelled successfully"})
+}
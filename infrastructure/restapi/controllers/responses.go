@@ -1,5 +1,11 @@
 package controllers
 
+import (
+	secureDomain "hexagonal-fiber/domain/security"
+
+	"github.com/gofiber/fiber/v2"
+)
+
 // JSONSwagger is a struct that contains the swagger documentation
 type JSONSwagger struct {
 }
@@ -8,3 +14,20 @@ type JSONSwagger struct {
 type MessageResponse struct {
 	Message string `json:"message"`
 }
+
+// ErrorStatus returns the status code carried by a fiber error, or bad request for any other error
+func ErrorStatus(err error) int {
+	if fiberErr, ok := err.(*fiber.Error); ok {
+		return fiberErr.Code
+	}
+
+	return fiber.StatusBadRequest
+}
+
+// ClientInfo returns the client information of the request
+func ClientInfo(ctx *fiber.Ctx) secureDomain.ClientInfo {
+	return secureDomain.ClientInfo{
+		IP:        ctx.IP(),
+		UserAgent: ctx.Get(fiber.HeaderUserAgent),
+	}
+}
@@ -0,0 +1,192 @@
+// Package role contains the role controller
+package role
+
+import (
+	useCaseRole "hexagonal-fiber/application/usecases/role"
+	secureDomain "hexagonal-fiber/domain/security"
+	userDomain "hexagonal-fiber/domain/user"
+
+	authConst "hexagonal-fiber/utils/constant/auth"
+	mssgConst "hexagonal-fiber/utils/constant/message"
+
+	"hexagonal-fiber/infrastructure/restapi/controllers"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Controller is a struct that contains the role service
+type Controller struct {
+	RoleService useCaseRole.Service
+}
+
+// GetAllRoles godoc
+// @Tags role
+// @Summary Get all Roles
+// @Security ApiKeyAuth
+// @Description Get all Roles with their permissions
+// @Success 200 {object} []userDomain.Role
+// @Failure 403 {object} controllers.MessageResponse
+// @Failure 500 {object} controllers.MessageResponse
+// @Router /roles [get]
+func (c *Controller) GetAllRoles(ctx *fiber.Ctx) (err error) {
+	roles, err := c.RoleService.GetAll()
+	if err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(roles)
+}
+
+// GetPermissions godoc
+// @Tags role
+// @Summary Get all Permissions
+// @Security ApiKeyAuth
+// @Description Get every permission a role can own
+// @Success 200 {object} []userDomain.Permission
+// @Failure 403 {object} controllers.MessageResponse
+// @Failure 500 {object} controllers.MessageResponse
+// @Router /roles/permissions [get]
+func (c *Controller) GetPermissions(ctx *fiber.Ctx) (err error) {
+	permissions, err := c.RoleService.GetPermissions()
+	if err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(permissions)
+}
+
+// GetRoleByID godoc
+// @Tags role
+// @Summary Get role by ID
+// @Description Get Role by ID with its permissions
+// @Param role_id path string true "id of role"
+// @Security ApiKeyAuth
+// @Success 200 {object} userDomain.Role
+// @Failure 404 {object} controllers.MessageResponse
+// @Failure 500 {object} controllers.MessageResponse
+// @Router /roles/{role_id} [get]
+func (c *Controller) GetRoleByID(ctx *fiber.Ctx) (err error) {
+	role, err := c.RoleService.GetByID(ctx.Params("id"))
+	if err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(role)
+}
+
+// NewRole godoc
+// @Tags role
+// @Summary Create New Role
+// @Description Create new role owning the given permissions
+// @Security ApiKeyAuth
+// @Param data body userDomain.NewRole true "body data"
+// @Success 201 {object} userDomain.Role
+// @Failure 400 {object} controllers.MessageResponse
+// @Failure 409 {object} controllers.MessageResponse
+// @Router /roles [post]
+func (c *Controller) NewRole(ctx *fiber.Ctx) (err error) {
+	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
+
+	var request userDomain.NewRole
+
+	if err = ctx.BodyParser(&request); err != nil {
+		appError := fiber.NewError(fiber.StatusBadRequest, mssgConst.StatusBadRequest)
+		return ctx.Status(fiber.StatusBadRequest).JSON(appError)
+	}
+
+	if err = controllers.Validation(request); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	role, err := c.RoleService.Create(authData, request)
+	if err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(role)
+}
+
+// UpdateRole godoc
+// @Tags role
+// @Summary Update role by ID
+// @Description Update the name, two factor requirement or permissions of a role
+// @Param role_id path string true "id of role"
+// @Security ApiKeyAuth
+// @Param data body userDomain.UpdateRole true "body data"
+// @Success 200 {object} userDomain.Role
+// @Failure 400 {object} controllers.MessageResponse
+// @Failure 404 {object} controllers.MessageResponse
+// @Failure 409 {object} controllers.MessageResponse
+// @Router /roles/{role_id} [put]
+func (c *Controller) UpdateRole(ctx *fiber.Ctx) (err error) {
+	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
+
+	var request userDomain.UpdateRole
+
+	if err = ctx.BodyParser(&request); err != nil {
+		appError := fiber.NewError(fiber.StatusBadRequest, mssgConst.StatusBadRequest)
+		return ctx.Status(fiber.StatusBadRequest).JSON(appError)
+	}
+
+	if err = controllers.Validation(request); err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	role, err := c.RoleService.Update(authData, ctx.Params("id"), request)
+	if err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(role)
+}
+
+// DeleteRole godoc
+// @Tags role
+// @Summary Delete role by ID
+// @Description Delete a role no user is assigned to
+// @Param role_id path string true "id of role"
+// @Security ApiKeyAuth
+// @Success 200 {object} controllers.MessageResponse
+// @Failure 404 {object} controllers.MessageResponse
+// @Failure 409 {object} controllers.MessageResponse
+// @Router /roles/{role_id} [delete]
+func (c *Controller) DeleteRole(ctx *fiber.Ctx) (err error) {
+	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
+
+	if err = c.RoleService.Delete(authData, ctx.Params("id")); err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(controllers.MessageResponse{Message: "role deleted successfully"})
+}
+
+// AssignRole godoc
+// @Tags role
+// @Summary Assign role to user
+// @Description Give the role to the user, replacing its previous role
+// @Param role_id path string true "id of role"
+// @Param user_id path string true "id of user"
+// @Security ApiKeyAuth
+// @Success 200 {object} userDomain.ResponseUserRole
+// @Failure 404 {object} controllers.MessageResponse
+// @Failure 500 {object} controllers.MessageResponse
+// @Router /roles/{role_id}/users/{user_id} [put]
+func (c *Controller) AssignRole(ctx *fiber.Ctx) (err error) {
+	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
+
+	userRole, err := c.RoleService.AssignToUser(authData, ctx.Params("id"), ctx.Params("user_id"))
+	if err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(userRole)
+}
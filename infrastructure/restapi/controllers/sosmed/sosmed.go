@@ -6,6 +6,7 @@ import (
 	sosmedDomain "hexagonal-fiber/domain/sosmed"
 
 	secureDomain "hexagonal-fiber/domain/security"
+	"hexagonal-fiber/infrastructure/restapi/controllers"
 
 	authConst "hexagonal-fiber/utils/constant/auth"
 	mssgConst "hexagonal-fiber/utils/constant/message"
@@ -111,11 +112,13 @@ func (c *Controller) GetAllOwnSocialMedia(ctx *fiber.Ctx) (err error) {
 // @Failure 500 {object} controllers.MessageResponse
 // @Router /sosmed/{sosmed_id} [get]
 func (c *Controller) GetSocialMediaByID(ctx *fiber.Ctx) (err error) {
+	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
 	sosmedID := ctx.Params("id")
-	sosmed, err := c.SocialMediaService.GetByID(sosmedID)
+
+	sosmed, err := c.SocialMediaService.GetByID(authData, sosmedID)
 	if err != nil {
-		appError := fiber.NewError(fiber.StatusBadRequest, mssgConst.ValidationError)
-		return ctx.Status(fiber.StatusBadRequest).JSON(appError)
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
 	}
 
 	return ctx.Status(fiber.StatusOK).JSON(sosmed)
@@ -146,20 +149,10 @@ func (c *Controller) UpdateSocialMedia(ctx *fiber.Ctx) (err error) {
 		return
 	}
 
-	var sosmed *sosmedDomain.SocialMedia
-
-	if authData.Role == "admin" {
-		sosmed, err = c.SocialMediaService.Update(sosmedID, request)
-		if err != nil {
-			ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err})
-			return
-		}
-	} else {
-		sosmed, err = c.SocialMediaService.UserUpdate(sosmedID, authData.UserID, request)
-		if err != nil {
-			ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err})
-			return
-		}
+	sosmed, err := c.SocialMediaService.Update(authData, sosmedID, request)
+	if err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
 	}
 
 	return ctx.Status(fiber.StatusOK).JSON(sosmed)
@@ -176,12 +169,63 @@ func (c *Controller) UpdateSocialMedia(ctx *fiber.Ctx) (err error) {
 // @Failure 500 {object} controllers.MessageResponse
 // @Router /sosmed/{sosmed_id} [get]
 func (c *Controller) DeleteSocialMedia(ctx *fiber.Ctx) (err error) {
+	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
 	sosmedID := ctx.Params("id")
-	if err = c.SocialMediaService.Delete(sosmedID); err != nil {
-		ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err})
+
+	if err = c.SocialMediaService.Delete(authData, sosmedID); err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
 		return
 	}
 
 	return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "resource deleted successfully"})
 
 }
+
+// GetSocialMediaTrash godoc
+// @Tags sosmed
+// @Summary Get deleted social media
+// @Description Get the deleted social media of the user kept until purged, the caller's own when no user is given
+// @Security ApiKeyAuth
+// @Param user_id query string false "id of the owner of the social media"
+// @Param page query int false "page"
+// @Param limit query int false "limit"
+// @Success 200 {object} sosmedDomain.PaginationSocialMedia
+// @Failure 403 {object} controllers.MessageResponse
+// @Failure 500 {object} controllers.MessageResponse
+// @Router /social-media/trash [get]
+func (c *Controller) GetSocialMediaTrash(ctx *fiber.Ctx) (err error) {
+	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
+
+	page := ctx.QueryInt("page", 1)
+	limit := ctx.QueryInt("limit", 20)
+
+	trash, err := c.SocialMediaService.Trash(authData, ctx.Query("user_id", authData.UserID), page, limit)
+	if err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(trash)
+}
+
+// RestoreSocialMedia godoc
+// @Tags sosmed
+// @Summary Restore a deleted social media
+// @Description Bring a deleted social media back from the trash
+// @Param social_media_id path string true "id of social media"
+// @Security ApiKeyAuth
+// @Success 200 {object} sosmedDomain.SocialMedia
+// @Failure 403 {object} controllers.MessageResponse
+// @Failure 404 {object} controllers.MessageResponse
+// @Router /social-media/{social_media_id}/restore [post]
+func (c *Controller) RestoreSocialMedia(ctx *fiber.Ctx) (err error) {
+	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
+
+	sosmed, err := c.SocialMediaService.Restore(authData, ctx.Params("id"))
+	if err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(sosmed)
+}
@@ -2,18 +2,17 @@
 package user
 
 import (
-	"encoding/json"
 	useCaseUser "hexagonal-fiber/application/usecases/user"
 	userDomain "hexagonal-fiber/domain/user"
 
 	secureDomain "hexagonal-fiber/domain/security"
 	redisRepo "hexagonal-fiber/infrastructure/repository/redis"
+	"hexagonal-fiber/infrastructure/restapi/controllers"
 
 	authConst "hexagonal-fiber/utils/constant/auth"
 	mssgConst "hexagonal-fiber/utils/constant/message"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/redis/go-redis/v9"
 )
 
 // Controller is a struct that contains the user service
@@ -53,48 +52,15 @@ func (c *Controller) GetAllUsers(ctx *fiber.Ctx) (err error) {
 // @Router /user/{user_id} [get]
 func (c *Controller) GetUsersByID(ctx *fiber.Ctx) (err error) {
 	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
-
-	if authData.Role == "admin" {
-		var userRole *userDomain.ResponseUserRole
-		userID := ctx.Params("id")
-
-		userRole, err = c.UserService.GetWithRole(userID)
-		if err != nil {
-			ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err})
-			return
-		}
-
-		return ctx.Status(fiber.StatusOK).JSON(userRole)
-
-	} else {
-		var userRole *userDomain.ResponseUserRole
-
-		redisDB := c.InfoRedis.NewRedis(0)
-		redisData, redisErr := redisDB.Get(c.InfoRedis.CTX, ctx.IP()).Result()
-
-		if redisErr == redis.Nil {
-			userRole, err = c.UserService.GetWithRole(authData.UserID)
-			if err != nil {
-				ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": redisErr})
-				return
-			}
-
-		} else {
-			authDataUser := userDomain.SecurityAuthenticatedUser{}
-
-			err = json.Unmarshal([]byte(redisData), &authDataUser)
-			if err != nil {
-				ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err})
-				return
-			}
-
-			userRole = authDataUser.ToUserRoleResponse()
-		}
-
-		return ctx.Status(fiber.StatusOK).JSON(userRole)
-
+	// the profile is always read from the database, a copy kept for the
+	// session would miss the updates, role changes and revokes made since
+	userRole, err := c.UserService.GetWithRole(authData, ctx.Params("id"))
+	if err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
 	}
 
+	return ctx.Status(fiber.StatusOK).JSON(userRole)
 }
 
 // UpdateUser godoc
@@ -109,13 +75,7 @@ func (c *Controller) GetUsersByID(ctx *fiber.Ctx) (err error) {
 // @Router /user/{user_id} [get]
 func (c *Controller) UpdateUser(ctx *fiber.Ctx) (err error) {
 	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
-
-	var userID string
-	if authData.Role == "admin" {
-		userID = ctx.Params("id")
-	} else {
-		userID = authData.UserID
-	}
+	userID := ctx.Params("id")
 
 	var request userDomain.UpdateUser
 	if err = ctx.BodyParser(&request); err != nil {
@@ -128,9 +88,9 @@ func (c *Controller) UpdateUser(ctx *fiber.Ctx) (err error) {
 		return
 	}
 
-	user, err := c.UserService.Update(userID, request)
+	user, err := c.UserService.Update(authData, userID, request)
 	if err != nil {
-		ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err})
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
 		return
 	}
 
@@ -149,21 +109,60 @@ func (c *Controller) UpdateUser(ctx *fiber.Ctx) (err error) {
 // @Router /user/{user_id} [get]
 func (c *Controller) DeleteUser(ctx *fiber.Ctx) (err error) {
 	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
+	userID := ctx.Params("id")
 
-	var userID string
-	if authData.Role == "admin" {
-		userID = ctx.Params("id")
-	} else {
-		userID = authData.UserID
+	if err = c.UserService.Delete(authData, userID); err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
 	}
 
-	if err = c.UserService.Delete(userID); err != nil {
-		ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err})
+	return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "resource deleted successfully"})
+}
+
+// GetUserTrash godoc
+// @Tags user
+// @Summary Get deleted users
+// @Description Get the deleted users kept until purged
+// @Security ApiKeyAuth
+// @Param page query int false "page"
+// @Param limit query int false "limit"
+// @Success 200 {object} userDomain.PaginationResponseUser
+// @Failure 403 {object} controllers.MessageResponse
+// @Failure 500 {object} controllers.MessageResponse
+// @Router /user/trash [get]
+func (c *Controller) GetUserTrash(ctx *fiber.Ctx) (err error) {
+	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
+
+	page := ctx.QueryInt("page", 1)
+	limit := ctx.QueryInt("limit", 20)
+
+	trash, err := c.UserService.Trash(authData, page, limit)
+	if err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
 		return
 	}
 
-	redisDB := c.InfoRedis.NewRedis(0)
-	redisDB.Del(c.InfoRedis.CTX, ctx.IP())
+	return ctx.Status(fiber.StatusOK).JSON(trash)
+}
 
-	return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "resource deleted successfully"})
+// RestoreUser godoc
+// @Tags user
+// @Summary Restore a deleted user
+// @Description Bring a deleted user back from the trash along with the photos, comments and social media deleted with it
+// @Param user_id path string true "id of user"
+// @Security ApiKeyAuth
+// @Success 200 {object} ResponseUser
+// @Failure 403 {object} controllers.MessageResponse
+// @Failure 404 {object} controllers.MessageResponse
+// @Router /user/{user_id}/restore [post]
+func (c *Controller) RestoreUser(ctx *fiber.Ctx) (err error) {
+	authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
+
+	user, err := c.UserService.Restore(authData, ctx.Params("id"))
+	if err != nil {
+		ctx.Status(controllers.ErrorStatus(err)).JSON(fiber.Map{"error": err})
+		return
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(user)
 }
@@ -2,49 +2,169 @@
 package middlewares
 
 import (
-	"hexagonal-fiber/utils/lists"
+	"strings"
 
+	"hexagonal-fiber/application/security/jwt"
+	"hexagonal-fiber/application/services"
+	apiKeyService "hexagonal-fiber/application/usecases/apikey"
+
+	apiKeyDomain "hexagonal-fiber/domain/apikey"
+	auditDomain "hexagonal-fiber/domain/audit"
+	databsDomain "hexagonal-fiber/domain/database"
 	secureDomain "hexagonal-fiber/domain/security"
+	apiKeyRepository "hexagonal-fiber/infrastructure/repository/postgres/apikey"
+	auditRepository "hexagonal-fiber/infrastructure/repository/postgres/audit"
+	userRepository "hexagonal-fiber/infrastructure/repository/postgres/user"
+	tokenRepository "hexagonal-fiber/infrastructure/repository/redis/token"
 
 	authConst "hexagonal-fiber/utils/constant/auth"
 
-	jwt "github.com/dgrijalva/jwt-go"
 	"github.com/gofiber/fiber/v2"
 )
 
+var middlewareDB databsDomain.Database
+
+// SetMiddlewareDB sets the databases used by the middlewares
+func SetMiddlewareDB(db databsDomain.Database) {
+	middlewareDB = db
+}
+
+// AuthJWTMiddleware is a function that validates the bearer access token of the request,
+// machine to machine clients may present an api key in the X-Api-Key header instead
 func AuthJWTMiddleware() fiber.Handler {
 	return func(ctx *fiber.Ctx) error {
-		tokenString := ctx.Get("Authorization")
+		if HasAPIKey(ctx) {
+			return authAPIKey(ctx)
+		}
+
+		tokenString := strings.TrimPrefix(ctx.Get("Authorization"), "Bearer ")
 		if tokenString == "" {
 			return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Token not provided"})
 		}
 
-		claims := &secureDomain.Claims{}
-		_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			return secureDomain.PublicKey, nil
-		})
-
+		claims, err := jwt.GetClaimsAndVerifyToken(tokenString, jwt.Access)
 		if err != nil {
 			return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid token"})
 		}
 
+		// revoking a session drops its token family, so its access tokens stop working too
+		tRepository := tokenRepository.Repository{InfoRedis: middlewareDB.Redis}
+		active, err := tRepository.IsFamilyActive(claims.SessionID)
+		if err != nil || !active {
+			return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Token revoked"})
+		}
+
+		// a single access token can be revoked through the oauth revocation endpoint
+		revoked, err := tRepository.IsTokenRevoked(claims.Id)
+		if err != nil || revoked {
+			return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Token revoked"})
+		}
+
+		// a password or role change, a deletion or a logout from everywhere bumps the version of the user
+		versions := services.TokenVersions{
+			UserRepository:  userRepository.Repository{DB: middlewareDB.Postgre},
+			TokenRepository: tRepository,
+		}
+		version, err := versions.Current(claims.UserID)
+		if err != nil || version != claims.Version {
+			return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Token revoked"})
+		}
+
+		claims.Client = secureDomain.ClientInfo{IP: ctx.IP(), UserAgent: ctx.Get(fiber.HeaderUserAgent)}
 		ctx.Locals(authConst.Authorized, claims)
 
+		if claims.IsImpersonated() {
+			return auditImpersonatedWrite(ctx, claims)
+		}
+
+		return ctx.Next()
+	}
+}
+
+// DenyImpersonationMiddleware is a function that keeps the account security of the user out of reach of an impersonating admin
+func DenyImpersonationMiddleware() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
+		if authData.IsImpersonated() {
+			return ctx.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "You are not allowed to do this while impersonating"})
+		}
+
 		return ctx.Next()
 	}
 }
 
-// AuthRoleMiddleware is a function that validates the role of user
-func AuthRoleMiddleware(validRoles []string) fiber.Handler {
+// DenyAPIKeyMiddleware is a function that keeps the account security of the user out of reach of its api keys,
+// a leaked key must not be enough to take the account over
+func DenyAPIKeyMiddleware() fiber.Handler {
 	return func(ctx *fiber.Ctx) error {
 		authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
+		if authData.Type == apiKeyDomain.ClaimsType {
+			return ctx.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "You are not allowed to do this with an api key"})
+		}
+
+		return ctx.Next()
+	}
+}
+
+// auditImpersonatedWrite runs the request and records it in the audit log when it may have changed something,
+// reads of an impersonating admin are not recorded past the start of the impersonation
+func auditImpersonatedWrite(ctx *fiber.Ctx, claims *secureDomain.Claims) error {
+	err := ctx.Next()
+
+	switch ctx.Method() {
+	case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions:
+		return err
+	}
+
+	services.Audit(&auditRepository.Repository{DB: middlewareDB.Postgre}, claims, auditDomain.Event{
+		Action:     auditDomain.ActionImpersonationWrite,
+		TargetType: auditDomain.TargetUser,
+		TargetID:   claims.UserID,
+		Changes: auditDomain.Changes{
+			"method": {After: ctx.Method()},
+			"path":   {After: ctx.Path()},
+			"status": {After: ctx.Response().StatusCode()},
+		},
+	})
+
+	return err
+}
 
-		if authData.Role == "" {
-			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "You are not authorized"})
+// HasAPIKey reports whether the request authenticates with an api key, such requests carry no cookie to protect from CSRF
+func HasAPIKey(ctx *fiber.Ctx) bool {
+	return ctx.Get(authConst.APIKey) != ""
+}
+
+// authAPIKey authenticates the request with its api key, the claims of the key are read like the ones of a token
+func authAPIKey(ctx *fiber.Ctx) error {
+	service := apiKeyService.Service{
+		APIKeyRepository: apiKeyRepository.Repository{DB: middlewareDB.Postgre},
+		UserRepository:   userRepository.Repository{DB: middlewareDB.Postgre},
+	}
+
+	claims, err := service.Authenticate(ctx.Get(authConst.APIKey))
+	if err != nil {
+		if fiberErr, ok := err.(*fiber.Error); ok && fiberErr.Code == fiber.StatusUnauthorized {
+			return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": fiberErr.Message})
 		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Api key could not be verified"})
+	}
+
+	claims.Client = secureDomain.ClientInfo{IP: ctx.IP(), UserAgent: ctx.Get(fiber.HeaderUserAgent)}
+	ctx.Locals(authConst.Authorized, claims)
+
+	return ctx.Next()
+}
+
+// AuthPermissionMiddleware is a function that validates the user owns every given permission
+func AuthPermissionMiddleware(permissions ...string) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		authData := ctx.Locals(authConst.Authorized).(*secureDomain.Claims)
 
-		if !lists.Contains(validRoles, authData.Role) {
-			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "You are not authorized for this path"})
+		for _, permission := range permissions {
+			if !authData.Can(permission) {
+				return ctx.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "You are not authorized for this path"})
+			}
 		}
 
 		return ctx.Next()
@@ -0,0 +1,28 @@
+package routes
+
+import (
+	albumController "hexagonal-fiber/infrastructure/restapi/controllers/album"
+	"hexagonal-fiber/infrastructure/restapi/middlewares"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AlbumRoutes is a function that contains all routes of the album
+func AlbumRoutes(router fiber.Router, controller *albumController.Controller) {
+	routerAlbum := router.Group("/albums")
+
+	// authentication
+	routerAlbum.Use(middlewares.AuthJWTMiddleware())
+	{
+		routerAlbum.Get("", controller.GetPublicAlbums)
+		routerAlbum.Get("/own", controller.GetAllOwnAlbums)
+		routerAlbum.Get("/:id/photos", controller.GetAlbumPhotos)
+		routerAlbum.Get("/:id", controller.GetAlbumByID)
+		routerAlbum.Post("", controller.NewAlbum)
+		routerAlbum.Put("/:id", controller.UpdateAlbum)
+		routerAlbum.Delete("/:id", controller.DeleteAlbum)
+		routerAlbum.Post("/:id/photos", controller.AddAlbumPhotos)
+		routerAlbum.Delete("/:id/photos", controller.RemoveAlbumPhotos)
+		routerAlbum.Put("/:id/photos/order", controller.ReorderAlbumPhotos)
+	}
+}
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	apiKeyController "hexagonal-fiber/infrastructure/restapi/controllers/apikey"
+	"hexagonal-fiber/infrastructure/restapi/middlewares"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// APIKeyRoutes is a function that contains all routes of the api keys
+func APIKeyRoutes(router fiber.Router, controller *apiKeyController.Controller) {
+	routerAPIKey := router.Group("/api-keys")
+
+	// authorization
+	routerAPIKey.Use(middlewares.AuthJWTMiddleware())
+	{
+		routerAPIKey.Get("", controller.GetAllAPIKeys)
+		routerAPIKey.Post("", middlewares.DenyImpersonationMiddleware(), controller.NewAPIKey)
+		routerAPIKey.Delete("/:id", middlewares.DenyImpersonationMiddleware(), middlewares.DenyAPIKeyMiddleware(), controller.DeleteAPIKey)
+	}
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	auditController "hexagonal-fiber/infrastructure/restapi/controllers/audit"
+	"hexagonal-fiber/infrastructure/restapi/middlewares"
+	"hexagonal-fiber/utils/constant/permission"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AuditRoutes is a function that contains all routes of the audit log
+func AuditRoutes(router fiber.Router, controller *auditController.Controller) {
+	routerAudit := router.Group("/audit-events")
+
+	// authorization
+	routerAudit.Use(middlewares.AuthJWTMiddleware(), middlewares.AuthPermissionMiddleware(permission.AuditRead))
+	{
+		routerAudit.Get("", controller.GetAllAuditEvents)
+	}
+}
@@ -3,6 +3,8 @@ package routes
 
 import (
 	authController "hexagonal-fiber/infrastructure/restapi/controllers/auth"
+	"hexagonal-fiber/infrastructure/restapi/middlewares"
+	"hexagonal-fiber/utils/constant/permission"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -13,8 +15,36 @@ func AuthRoutes(router fiber.Router, controller *authController.Controller) {
 	routerAuth := router.Group("/auth")
 	{
 		routerAuth.Post("/login", controller.Login)
+		routerAuth.Post("/login/mfa", controller.LoginMFA)
+		routerAuth.Post("/login/mfa/enroll", controller.LoginMFAEnroll)
 		routerAuth.Post("/register", controller.NewUser)
 		routerAuth.Post("/access-token", controller.GetAccessTokenByRefreshToken)
+		routerAuth.Get("/verify", controller.VerifyEmail)
+		routerAuth.Post("/verify/resend", controller.ResendVerification)
+		routerAuth.Post("/password/forgot", controller.ForgotPassword)
+		routerAuth.Post("/password/reset", controller.ResetPassword)
+		routerAuth.Get("/oidc", controller.GetOIDCProviders)
+		routerAuth.Get("/oidc/:provider", controller.OIDCLogin)
+		routerAuth.Get("/oidc/:provider/callback", controller.OIDCCallback)
+	}
+
+	// authentication, the account security is kept from impersonating admins and from api keys
+	{
+		routerAuth.Post("/logout", middlewares.AuthJWTMiddleware(), controller.Logout)
+		routerAuth.Post("/logout-all", middlewares.AuthJWTMiddleware(), middlewares.DenyImpersonationMiddleware(), middlewares.DenyAPIKeyMiddleware(), controller.LogoutAll)
+		routerAuth.Get("/sessions", middlewares.AuthJWTMiddleware(), middlewares.DenyAPIKeyMiddleware(), controller.GetSessions)
+		routerAuth.Delete("/sessions/:id", middlewares.AuthJWTMiddleware(), middlewares.DenyImpersonationMiddleware(), middlewares.DenyAPIKeyMiddleware(), controller.DeleteSession)
+		routerAuth.Post("/mfa/enroll", middlewares.AuthJWTMiddleware(), middlewares.DenyImpersonationMiddleware(), middlewares.DenyAPIKeyMiddleware(), controller.EnrollMFA)
+		routerAuth.Post("/mfa/confirm", middlewares.AuthJWTMiddleware(), middlewares.DenyImpersonationMiddleware(), middlewares.DenyAPIKeyMiddleware(), controller.ConfirmMFA)
+		routerAuth.Delete("/mfa", middlewares.AuthJWTMiddleware(), middlewares.DenyImpersonationMiddleware(), middlewares.DenyAPIKeyMiddleware(), controller.DisableMFA)
+		routerAuth.Delete("/impersonate", middlewares.AuthJWTMiddleware(), controller.StopImpersonation)
+	}
+
+	// admin
+	{
+		routerAuth.Put("/mfa/roles/:id", middlewares.AuthJWTMiddleware(), middlewares.AuthPermissionMiddleware(permission.RoleManage), controller.RequireRoleMFA)
+		routerAuth.Post("/unlock", middlewares.AuthJWTMiddleware(), middlewares.AuthPermissionMiddleware(permission.AccountUnlock), controller.Unlock)
+		routerAuth.Post("/impersonate/:id", middlewares.AuthJWTMiddleware(), middlewares.AuthPermissionMiddleware(permission.UserImpersonate), controller.Impersonate)
 	}
 
 }
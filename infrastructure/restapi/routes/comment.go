@@ -16,9 +16,11 @@ func CommentRoutes(router fiber.Router, controller *commentController.Controller
 	{
 		routerComment.Get("", controller.GetAllComments)
 		routerComment.Get("/own", controller.GetAllOwnComments)
+		routerComment.Get("/trash", controller.GetCommentTrash)
 		routerComment.Get("/:id", controller.GetCommentByID)
 		routerComment.Post("", controller.NewComment)
 		routerComment.Put("/:id", controller.UpdateComment)
 		routerComment.Delete("/:id", controller.DeleteComment)
+		routerComment.Post("/:id/restore", controller.RestoreComment)
 	}
 }
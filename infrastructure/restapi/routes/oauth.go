@@ -0,0 +1,17 @@
+package routes
+
+import (
+	oauthController "hexagonal-fiber/infrastructure/restapi/controllers/oauth"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// OAuthRoutes is a function that contains all routes of the token introspection and revocation,
+// the calling services authenticate with their client credentials instead of a user token
+func OAuthRoutes(router fiber.Router, controller *oauthController.Controller) {
+	routerOAuth := router.Group("/oauth")
+	{
+		routerOAuth.Post("/introspect", controller.Introspect)
+		routerOAuth.Post("/revoke", controller.Revoke)
+	}
+}
@@ -3,6 +3,7 @@ package routes
 import (
 	photoController "hexagonal-fiber/infrastructure/restapi/controllers/photo"
 	"hexagonal-fiber/infrastructure/restapi/middlewares"
+	"hexagonal-fiber/utils/constant/permission"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -16,10 +17,21 @@ func PhotoRoutes(router fiber.Router, controller *photoController.Controller) {
 	{
 		routerPhoto.Get("", controller.GetAllPhotos)
 		routerPhoto.Get("/own", controller.GetAllOwnPhotos)
+		routerPhoto.Get("/trash", controller.GetPhotoTrash)
 		routerPhoto.Get("/:id/comments", controller.GetPhotoWithComments)
+		routerPhoto.Get("/:id/download", controller.DownloadPhoto)
 		routerPhoto.Get("/:id", controller.GetPhotoByID)
 		routerPhoto.Post("", controller.NewPhoto)
 		routerPhoto.Put("/:id", controller.UpdatePhoto)
 		routerPhoto.Delete("/:id", controller.DeletePhoto)
+		routerPhoto.Post("/:id/restore", controller.RestorePhoto)
+	}
+
+	routerAdmin := router.Group("/admin/photos")
+
+	// authorization
+	routerAdmin.Use(middlewares.AuthJWTMiddleware(), middlewares.AuthPermissionMiddleware(permission.PhotoDuplicateRead))
+	{
+		routerAdmin.Get("/duplicates", controller.GetDuplicatePhotos)
 	}
 }
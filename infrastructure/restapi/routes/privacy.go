@@ -0,0 +1,21 @@
+package routes
+
+import (
+	privacyController "hexagonal-fiber/infrastructure/restapi/controllers/privacy"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PrivacyRoutes is a function that contains all routes of the personal data of the current user, to be registered after UserRoutes
+func PrivacyRoutes(router fiber.Router, controller *privacyController.Controller) {
+	routerPrivacy := router.Group("/user/me")
+
+	// authentication, the user routes registered before authenticate everything under /user so it runs once
+	{
+		routerPrivacy.Post("/export", controller.RequestExport)
+		routerPrivacy.Get("/export", controller.GetExport)
+		routerPrivacy.Get("/export/download", controller.DownloadExport)
+		routerPrivacy.Post("/erasure", controller.RequestErasure)
+		routerPrivacy.Delete("/erasure", controller.CancelErasure)
+	}
+}
@@ -0,0 +1,26 @@
+package routes
+
+import (
+	roleController "hexagonal-fiber/infrastructure/restapi/controllers/role"
+	"hexagonal-fiber/infrastructure/restapi/middlewares"
+	"hexagonal-fiber/utils/constant/permission"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RoleRoutes is a function that contains all routes of the role
+func RoleRoutes(router fiber.Router, controller *roleController.Controller) {
+	routerRole := router.Group("/roles")
+
+	// authorization
+	routerRole.Use(middlewares.AuthJWTMiddleware(), middlewares.AuthPermissionMiddleware(permission.RoleManage))
+	{
+		routerRole.Get("", controller.GetAllRoles)
+		routerRole.Get("/permissions", controller.GetPermissions)
+		routerRole.Get("/:id", controller.GetRoleByID)
+		routerRole.Post("", controller.NewRole)
+		routerRole.Put("/:id", controller.UpdateRole)
+		routerRole.Delete("/:id", controller.DeleteRole)
+		routerRole.Put("/:id/users/:user_id", controller.AssignRole)
+	}
+}
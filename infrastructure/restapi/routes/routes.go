@@ -2,9 +2,11 @@
 package routes
 
 import (
+	"hexagonal-fiber/application/security/keyring"
 	_ "hexagonal-fiber/docs"
 	databsDomain "hexagonal-fiber/domain/database"
 	"hexagonal-fiber/infrastructure/restapi/adapter"
+	"hexagonal-fiber/infrastructure/restapi/middlewares"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/csrf"
@@ -18,6 +20,14 @@ func ApplicationRootRouter(router fiber.Router, db databsDomain.Database) {
 		router.Get("/metrics", monitor.New())
 	}
 
+	// Signing keys for the services verifying our tokens
+	{
+		router.Get("/.well-known/jwks.json", func(c *fiber.Ctx) error {
+			c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+			return c.JSON(keyring.Keys.JWKS())
+		})
+	}
+
 	// Documentation Swagger
 	{
 		router.Get("/swagger/*any", fiberSwagger.WrapHandler)
@@ -30,26 +40,49 @@ func ApplicationRootRouter(router fiber.Router, db databsDomain.Database) {
 func ApplicationV1Router(router fiber.Router, db databsDomain.Database) {
 	routerV1 := router.Group("/v1")
 
+	// middleware databases
+	middlewares.SetMiddlewareDB(db)
+
 	{
 		// Auth Routes
 		AuthRoutes(routerV1, adapter.AuthAdapter(db))
 
-		// CSRF Middleware
+		// OAuth Routes, called by other services so they stay out of the CSRF protection
+		OAuthRoutes(routerV1, adapter.OAuthAdapter(db))
+
+		// CSRF Middleware, api keys travel in a header a browser never sends on its own
 		{
-			router.Use(csrf.New(csrf.ConfigDefault))
+			csrfConfig := csrf.ConfigDefault
+			csrfConfig.Next = middlewares.HasAPIKey
+			router.Use(csrf.New(csrfConfig))
 		}
 
 		// User Routes
 		UserRoutes(routerV1, adapter.UserAdapter(db))
 
+		// Personal Data Routes
+		PrivacyRoutes(routerV1, adapter.PrivacyAdapter(db))
+
+		// Role Routes
+		RoleRoutes(routerV1, adapter.RoleAdapter(db))
+
 		// Photo Routes
 		PhotoRoutes(routerV1, adapter.PhotoAdapter(db))
 
+		// Album Routes
+		AlbumRoutes(routerV1, adapter.AlbumAdapter(db))
+
 		// SocialMedia Routes
 		SocialMediaRoutes(routerV1, adapter.SocialMediaAdapter(db))
 
 		// Comment Routes
 		CommentRoutes(routerV1, adapter.CommentAdapter(db))
 
+		// Audit Routes
+		AuditRoutes(routerV1, adapter.AuditAdapter(db))
+
+		// API Key Routes
+		APIKeyRoutes(routerV1, adapter.APIKeyAdapter(db))
+
 	}
 }
@@ -16,9 +16,11 @@ func SocialMediaRoutes(router fiber.Router, controller *sosmedController.Control
 	{
 		routerSocialMedia.Get("", controller.GetAllSocialMedia)
 		routerSocialMedia.Get("/own", controller.GetAllOwnSocialMedia)
+		routerSocialMedia.Get("/trash", controller.GetSocialMediaTrash)
 		routerSocialMedia.Get("/:id", controller.GetSocialMediaByID)
 		routerSocialMedia.Post("", controller.NewSocialMedia)
 		routerSocialMedia.Put("/:id", controller.UpdateSocialMedia)
 		routerSocialMedia.Delete("/:id", controller.DeleteSocialMedia)
+		routerSocialMedia.Post("/:id/restore", controller.RestoreSocialMedia)
 	}
 }
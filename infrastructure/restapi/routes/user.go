@@ -3,6 +3,7 @@ package routes
 import (
 	userController "hexagonal-fiber/infrastructure/restapi/controllers/user"
 	"hexagonal-fiber/infrastructure/restapi/middlewares"
+	"hexagonal-fiber/utils/constant/permission"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -14,14 +15,15 @@ func UserRoutes(router fiber.Router, controller *userController.Controller) {
 	// authentication
 	routerAuth.Use(middlewares.AuthJWTMiddleware())
 	{
+		routerAuth.Get("/trash", controller.GetUserTrash)
 		routerAuth.Get("/:id", controller.GetUsersByID)
 		routerAuth.Put("/:id", controller.UpdateUser)
 		routerAuth.Delete("/:id", controller.DeleteUser)
+		routerAuth.Post("/:id/restore", controller.RestoreUser)
 	}
 
 	// authorization
-	routerAuth.Use(middlewares.AuthRoleMiddleware([]string{"admin"}))
 	{
-		routerAuth.Get("", controller.GetAllUsers)
+		routerAuth.Get("", middlewares.AuthPermissionMiddleware(permission.UserList), controller.GetAllUsers)
 	}
 }
@@ -4,9 +4,10 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"hexagonal-fiber/application/security/jwt"
+	"hexagonal-fiber/application/security/keyring"
 	"hexagonal-fiber/cmd"
 	databsDomain "hexagonal-fiber/domain/database"
-	secureDomain "hexagonal-fiber/domain/security"
 
 	"hexagonal-fiber/infrastructure/repository/postgres"
 	"hexagonal-fiber/infrastructure/repository/redis"
@@ -44,10 +45,15 @@ func main() {
 	// commands handler
 	cmd.Execute(databases)
 
-	// getting key ssh
-	err := secureDomain.GettingKeySSH()
+	// loading signing key ring
+	err := keyring.Load(keyRingPath())
 	if err != nil {
-		panic(fmt.Errorf("fatal error in getting key ssh: %s", err))
+		panic(fmt.Errorf("fatal error in loading key ring: %s", err))
+	}
+
+	// checking signing algorithms
+	if err = jwt.ValidateAlgorithms(); err != nil {
+		panic(fmt.Errorf("fatal error in signing algorithms: %s", err))
 	}
 
 	// root routes
@@ -80,6 +86,16 @@ func initDatabase() databsDomain.Database {
 	}
 }
 
+// key ring manifest path
+func keyRingPath() string {
+	viper.SetConfigFile("config.json")
+	if err := viper.ReadInConfig(); err != nil {
+		log.Fatalf("fatal error in config file: %s", err.Error())
+	}
+
+	return viper.GetString("Secure.KeyRingPath")
+}
+
 // start server config
 func startServer(app *fiber.App) {
 	viper.SetConfigFile("config.json")
@@ -3,4 +3,5 @@ package auth
 const (
 	Authorized = "Authorized"
 	CSRF       = "X-Csrf-Token"
+	APIKey     = "X-Api-Key"
 )
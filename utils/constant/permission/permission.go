@@ -0,0 +1,63 @@
+// Package permission contains the permissions a role can own
+package permission
+
+const (
+	UserList        = "user:list"
+	UserReadAny     = "user:read:any"
+	UserUpdateAny   = "user:update:any"
+	UserDeleteAny   = "user:delete:any"
+	UserRestoreAny  = "user:restore:any"
+	UserImpersonate = "user:impersonate"
+
+	PhotoUpdateAny        = "photo:update:any"
+	PhotoDeleteAny        = "photo:delete:any"
+	PhotoRestoreAny       = "photo:restore:any"
+	PhotoDuplicateRead    = "photo:duplicate:read"
+	CommentUpdateAny      = "comment:update:any"
+	CommentDeleteAny      = "comment:delete:any"
+	CommentRestoreAny     = "comment:restore:any"
+	SocialMediaUpdateAny  = "sosmed:update:any"
+	SocialMediaDeleteAny  = "sosmed:delete:any"
+	SocialMediaRestoreAny = "sosmed:restore:any"
+
+	AlbumReadAny   = "album:read:any"
+	AlbumUpdateAny = "album:update:any"
+	AlbumDeleteAny = "album:delete:any"
+
+	APIKeyReadAny   = "apikey:read:any"
+	APIKeyCreateAny = "apikey:create:any"
+	APIKeyDeleteAny = "apikey:delete:any"
+
+	RoleManage    = "role:manage"
+	AccountUnlock = "account:unlock"
+	AuditRead     = "audit:read"
+)
+
+// All lists every permission, they are seeded by the postgres migration and granted to the admin role
+var All = []string{
+	UserList,
+	UserReadAny,
+	UserUpdateAny,
+	UserDeleteAny,
+	UserRestoreAny,
+	UserImpersonate,
+	PhotoUpdateAny,
+	PhotoDeleteAny,
+	PhotoRestoreAny,
+	PhotoDuplicateRead,
+	CommentUpdateAny,
+	CommentDeleteAny,
+	CommentRestoreAny,
+	SocialMediaUpdateAny,
+	SocialMediaDeleteAny,
+	SocialMediaRestoreAny,
+	AlbumReadAny,
+	AlbumUpdateAny,
+	AlbumDeleteAny,
+	APIKeyReadAny,
+	APIKeyCreateAny,
+	APIKeyDeleteAny,
+	RoleManage,
+	AccountUnlock,
+	AuditRead,
+}